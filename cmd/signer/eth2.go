@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/xueqianLu/ethsigner/internal/config"
+	"github.com/xueqianLu/ethsigner/internal/eth2"
+)
+
+// newEth2Signer loads the validator keys and opens the slashing protection database.
+// Callers must Close the returned Signer.
+func newEth2Signer(cfg config.Eth2Config) (*eth2.Signer, error) {
+	protection, err := eth2.OpenSlashingProtection(cfg.SlashingProtectionDB)
+	if err != nil {
+		return nil, err
+	}
+	var genesisForkVersion *eth2.Version
+	if cfg.GenesisForkVersion != "" {
+		genesisForkVersion = new(eth2.Version)
+		if err := genesisForkVersion.UnmarshalText([]byte(cfg.GenesisForkVersion)); err != nil {
+			protection.Close()
+			return nil, fmt.Errorf("invalid eth2.genesis_fork_version: %w", err)
+		}
+	}
+	keys, err := eth2.NewKeyManager(cfg.KeystoreDir, cfg.PasswordDir, cfg.Password)
+	if err != nil {
+		protection.Close()
+		return nil, fmt.Errorf("failed to load validator keys: %w", err)
+	}
+	return eth2.NewSigner(keys, protection, genesisForkVersion), nil
+}
+
+// loadEth2Config loads and validates the configuration and checks that eth2 signing is enabled.
+func loadEth2Config() (config.Eth2Config, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return config.Eth2Config{}, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return config.Eth2Config{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if !cfg.Eth2.Enabled {
+		return config.Eth2Config{}, errors.New("eth2 signing is not enabled (eth2.enabled)")
+	}
+	return cfg.Eth2, nil
+}
+
+func newEth2Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "eth2",
+		Short: "Manage consensus-layer validator keys and slashing protection",
+	}
+	slashing := &cobra.Command{
+		Use:   "slashing-protection",
+		Short: "Import and export EIP-3076 slashing protection interchange files",
+	}
+	slashing.AddCommand(newSlashingExportCmd(), newSlashingImportCmd())
+	cmd.AddCommand(newEth2ListCmd(), slashing)
+	return cmd
+}
+
+func newEth2ListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the public keys of the loaded validator keys",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadEth2Config()
+			if err != nil {
+				return err
+			}
+			keys, err := eth2.NewKeyManager(cfg.KeystoreDir, cfg.PasswordDir, cfg.Password)
+			if err != nil {
+				return err
+			}
+			defer keys.Close()
+			for _, pub := range keys.PublicKeys() {
+				fmt.Fprintln(cmd.OutOrStdout(), pub.Hex())
+			}
+			return nil
+		},
+	}
+}
+
+func newSlashingExportCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the signing history of all validators as an interchange file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadEth2Config()
+			if err != nil {
+				return err
+			}
+			protection, err := eth2.OpenSlashingProtection(cfg.SlashingProtectionDB)
+			if err != nil {
+				return err
+			}
+			defer protection.Close()
+
+			interchange, err := protection.Export()
+			if err != nil {
+				return err
+			}
+			data, err := json.MarshalIndent(interchange, "", "  ")
+			if err != nil {
+				return err
+			}
+			if file == "" || file == "-" {
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			return os.WriteFile(file, append(data, '\n'), 0600)
+		},
+	}
+	cmd.Flags().StringVar(&file, "file", "", `file to write, or "-" for stdout (default stdout)`)
+	return cmd
+}
+
+func newSlashingImportCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Merge an interchange file into the slashing protection database",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			var interchange eth2.Interchange
+			if err := json.Unmarshal(data, &interchange); err != nil {
+				return fmt.Errorf("invalid interchange file: %w", err)
+			}
+
+			cfg, err := loadEth2Config()
+			if err != nil {
+				return err
+			}
+			protection, err := eth2.OpenSlashingProtection(cfg.SlashingProtectionDB)
+			if err != nil {
+				return err
+			}
+			defer protection.Close()
+
+			validators, err := protection.Import(&interchange)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Imported slashing protection data for %d validators\n", validators)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&file, "file", "", "interchange file to import")
+	cmd.MarkFlagRequired("file")
+	return cmd
+}
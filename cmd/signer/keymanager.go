@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/xueqianLu/ethsigner/internal/config"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/store"
+)
+
+// storeAccess selects how loadSigner opens the account store.
+type storeAccess int
+
+const (
+	// storeReadWrite locks the store for commands that change account records. It fails
+	// while a running signer holds the store.
+	storeReadWrite storeAccess = iota
+	// storeReadOnly is for commands that only read account records. If a running signer
+	// holds the store, or there is none yet, the command proceeds without records.
+	storeReadOnly
+	// storeReadStates is for commands that must honour account states, such as signing
+	// and exporting. It reads the store like storeReadOnly but fails while a running signer
+	// holds it, since disabled or archived accounts could not be told apart otherwise.
+	storeReadStates
+)
+
+// loadSigner loads and validates the configuration and builds a Signer on top of the
+// configured KeyManager and account store. Callers must Close the returned Signer.
+func loadSigner(access storeAccess) (*signer.Signer, config.Config, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, cfg, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, cfg, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	accounts, err := openStore(cfg.Store.Path, access)
+	if err != nil {
+		return nil, cfg, err
+	}
+	keyManager, err := newKeyManager(cfg)
+	if err != nil {
+		accounts.Close()
+		return nil, cfg, err
+	}
+	return signer.NewSigner(keyManager, accounts, timeoutsFromConfig(cfg)), cfg, nil
+}
+
+// openStore opens the account store at path with the given access.
+func openStore(path string, access storeAccess) (*store.Store, error) {
+	if access == storeReadWrite {
+		return store.Open(path)
+	}
+	accounts, err := store.OpenReadOnly(path)
+	switch {
+	case errors.Is(err, store.ErrLocked) && access == storeReadStates:
+		return nil, fmt.Errorf("cannot check account states: %w", err)
+	case errors.Is(err, store.ErrLocked):
+		log.Printf("Warning: account store %s is in use by the running signer; account states and metadata are not applied", path)
+		return nil, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// newKeyManager creates the KeyManager selected by key_manager.type.
+func newKeyManager(cfg config.Config) (signer.KeyManager, error) {
+	if cfg.KeyManager.Type != "composite" {
+		return newBackend(cfg.KeyManager.Type, cfg.KeyManager.Local, cfg.KeyManager.Vault)
+	}
+
+	var backends []signer.NamedKeyManager
+	closeAll := func() {
+		for _, b := range backends {
+			b.KeyManager.Close()
+		}
+	}
+	for _, b := range cfg.KeyManager.Backends {
+		keyManager, err := newBackend(b.Type, b.Local, b.Vault)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("backend %s: %w", b.Name, err)
+		}
+		backends = append(backends, signer.NamedKeyManager{Name: b.Name, KeyManager: keyManager})
+	}
+	keyManager, err := signer.NewCompositeKeyManager(backends, cfg.KeyManager.DefaultBackend)
+	if err != nil {
+		closeAll()
+		return nil, fmt.Errorf("failed to initialize composite key manager: %w", err)
+	}
+	log.Printf("Using composite key manager with %d backends", len(backends))
+	return keyManager, nil
+}
+
+// newBackend creates a local or Vault KeyManager.
+func newBackend(kind string, local config.LocalConfig, vault config.VaultConfig) (signer.KeyManager, error) {
+	switch kind {
+	case "local":
+		var unlock *signer.UnlockPolicy
+		if local.PerAccountPasswords {
+			unlock = &signer.UnlockPolicy{DefaultTTL: local.UnlockTTL, MaxTTL: local.MaxUnlockTTL}
+		}
+		keyManager, err := signer.NewLocalKeyManager(local.KeyDir, local.ArchiveDir, local.Password, unlock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize local key manager: %w", err)
+		}
+		if unlock != nil {
+			log.Printf("Using local key manager in %s with per-account passwords", local.KeyDir)
+		} else {
+			log.Printf("Using local key manager in %s", local.KeyDir)
+		}
+		return keyManager, nil
+	case "vault":
+		// Vault client configuration
+		vaultConfig := &api.Config{
+			Address: vault.Address,
+		}
+		vaultClient, err := api.NewClient(vaultConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Vault client: %w", err)
+		}
+		auth, err := vaultAuthenticator(vault, vaultClient)
+		if err != nil {
+			return nil, err
+		}
+		if auth == nil {
+			vaultClient.SetToken(vault.Token)
+		}
+
+		keyManager, err := signer.NewVaultKeyManager(vaultClient, vault.TransitPath, signer.VaultOptions{
+			Auth:            auth,
+			RefreshInterval: vault.RefreshInterval,
+			KeyPrefix:       vault.KeyPrefix,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Vault key manager: %w", err)
+		}
+		log.Printf("Using Vault key manager at %s", vault.Address)
+		return keyManager, nil
+	default:
+		return nil, fmt.Errorf("invalid key manager type specified: %s", kind)
+	}
+}
+
+// vaultAuthenticator returns the authenticator for the configured Vault login method,
+// or nil when the static token is used.
+func vaultAuthenticator(cfg config.VaultConfig, client *api.Client) (*signer.VaultAuthenticator, error) {
+	auth := cfg.Auth
+	switch auth.Method {
+	case "", "token":
+		return nil, nil
+	case "approle":
+		return signer.NewVaultAuthenticator(client, signer.AppRoleLogin{
+			MountPath: auth.MountPath,
+			RoleID:    auth.RoleID,
+			SecretID:  auth.SecretID,
+		}), nil
+	case "kubernetes":
+		return signer.NewVaultAuthenticator(client, signer.KubernetesLogin{
+			MountPath: auth.MountPath,
+			Role:      auth.Role,
+			JWTPath:   auth.JWTPath,
+		}), nil
+	default:
+		return nil, fmt.Errorf("invalid Vault auth method: %s", auth.Method)
+	}
+}
+
+// timeoutsFromConfig converts the configured key manager timeouts for the signer.
+func timeoutsFromConfig(cfg config.Config) signer.Timeouts {
+	return signer.Timeouts{
+		List:   cfg.KeyManager.Timeouts.List,
+		Create: cfg.KeyManager.Timeouts.Create,
+		Sign:   cfg.KeyManager.Timeouts.Sign,
+	}
+}
+
+// perAccountPasswords reports whether new keys in the given backend need their own password.
+// An empty backend selects the default backend of a composite key manager.
+func perAccountPasswords(cfg config.Config, backend string) bool {
+	km := cfg.KeyManager
+	if km.Type != "composite" {
+		return km.Type == "local" && km.Local.PerAccountPasswords
+	}
+	if backend == "" {
+		backend = km.DefaultBackend
+	}
+	for i, b := range km.Backends {
+		if b.Name == backend || (backend == "" && i == 0) {
+			return b.Type == "local" && b.Local.PerAccountPasswords
+		}
+	}
+	return false
+}
+
+// sharedLocalPassword returns the keystore password of the local key manager. With a
+// composite key manager it is that of the first local backend; password rotation is
+// refused anyway unless there is exactly one.
+func sharedLocalPassword(cfg config.Config) string {
+	if cfg.KeyManager.Type != "composite" {
+		return cfg.KeyManager.Local.Password
+	}
+	for _, b := range cfg.KeyManager.Backends {
+		if b.Type == "local" {
+			return b.Local.Password
+		}
+	}
+	return ""
+}
@@ -0,0 +1,482 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"github.com/xueqianLu/ethsigner/internal/config"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/store"
+)
+
+func newKeysCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage the keys held by the configured key manager",
+	}
+	cmd.AddCommand(
+		newKeysListCmd(),
+		newKeysCreateCmd(),
+		newKeysLabelCmd(),
+		newKeysImportCmd(),
+		newKeysExportCmd(),
+		newKeysDeleteCmd(),
+		newKeysStateCmd("disable", "Stop an account from signing, keeping its key"),
+		newKeysStateCmd("enable", "Allow a disabled account to sign again"),
+		newKeysArchiveCmd(),
+		newKeysRotatePasswordCmd(),
+	)
+	return cmd
+}
+
+func newKeysListCmd() *cobra.Command {
+	var (
+		filter signer.AccountFilter
+		all    bool
+		long   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List managed account addresses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, _, err := loadSigner(storeReadOnly)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			if all {
+				filter.States = []string{store.StateActive, store.StateDisabled, store.StateArchived}
+			}
+			accounts, err := s.ListAccounts(cmd.Context(), filter)
+			if err != nil {
+				return err
+			}
+			if !long {
+				for _, acc := range accounts {
+					fmt.Fprintln(cmd.OutOrStdout(), acc.Address.Hex())
+				}
+				return nil
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "ADDRESS\tSTATE\tLABEL\tOWNER\tTAGS\tBACKEND\tKEY REF")
+			for _, acc := range accounts {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", acc.Address.Hex(), acc.State,
+					acc.Label, acc.Owner, strings.Join(acc.Tags, ","), acc.Backend, acc.KeyRef)
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().StringSliceVar(&filter.Tags, "tag", nil, "only list accounts carrying this tag (repeatable)")
+	cmd.Flags().StringVar(&filter.Owner, "owner", "", "only list accounts owned by this team")
+	cmd.Flags().BoolVar(&all, "all", false, "include disabled and archived accounts")
+	cmd.Flags().BoolVarP(&long, "long", "l", false, "show state and metadata")
+	return cmd
+}
+
+func newKeysCreateCmd() *cobra.Command {
+	var meta store.Metadata
+	var backend, keyName, accountPasswordFile string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Generate a new key and print its address",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, cfg, err := loadSigner(storeReadWrite)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			opts, err := createKeyOptions(cfg, backend, accountPasswordFile)
+			if err != nil {
+				return err
+			}
+			opts.Name = keyName
+			address, err := s.CreateKey(cmd.Context(), opts, meta)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), address.Hex())
+			return nil
+		},
+	}
+	addMetadataFlags(cmd, &meta)
+	cmd.Flags().StringVar(&accountPasswordFile, "account-password-file", "", "file holding the password of the new account, with per-account passwords (prompted if omitted)")
+	cmd.Flags().StringVar(&backend, "backend", "", "composite key manager backend to create the key in (default: key_manager.default_backend)")
+	cmd.Flags().StringVar(&keyName, "key-name", "", "name of the key in the backend, e.g. the Vault transit key (default: generated)")
+	return cmd
+}
+
+func newKeysLabelCmd() *cobra.Command {
+	var meta store.Metadata
+
+	cmd := &cobra.Command{
+		Use:   "label <address>",
+		Short: "Replace the label, owner, purpose and tags of an account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			address, err := parseAddress(args[0])
+			if err != nil {
+				return err
+			}
+
+			s, _, err := loadSigner(storeReadWrite)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			_, err = s.UpdateMetadata(cmd.Context(), address, meta)
+			return err
+		},
+	}
+	addMetadataFlags(cmd, &meta)
+	return cmd
+}
+
+// createKeyOptions asks for the password of a new account when the target backend uses
+// per-account passwords.
+func createKeyOptions(cfg config.Config, backend, accountPasswordFile string) (signer.CreateKeyOptions, error) {
+	if backend != "" && cfg.KeyManager.Type != "composite" {
+		return signer.CreateKeyOptions{}, errors.New("--backend requires key_manager.type composite")
+	}
+	if !perAccountPasswords(cfg, backend) {
+		if accountPasswordFile != "" {
+			return signer.CreateKeyOptions{}, errors.New("--account-password-file requires a local backend with per_account_passwords")
+		}
+		return signer.CreateKeyOptions{Backend: backend}, nil
+	}
+	password, err := readPassword(accountPasswordFile, "Account password", true)
+	if err != nil {
+		return signer.CreateKeyOptions{}, err
+	}
+	return signer.CreateKeyOptions{Password: password, Backend: backend}, nil
+}
+
+// withUnlock runs op and, if the account turns out to be locked, asks for its password,
+// unlocks it for the duration of one retry and locks it again.
+func withUnlock(cmd *cobra.Command, s *signer.Signer, address common.Address, passwordFile string, op func() error) error {
+	err := op()
+	if !errors.Is(err, signer.ErrAccountLocked) {
+		return err
+	}
+	password, err := readPassword(passwordFile, "Password for "+address.Hex(), false)
+	if err != nil {
+		return err
+	}
+	if _, err := s.UnlockAccount(cmd.Context(), address, password, time.Minute); err != nil {
+		return err
+	}
+	defer s.LockAccount(cmd.Context(), address)
+	return op()
+}
+
+// addMetadataFlags registers the account metadata flags shared by create, import and label.
+func addMetadataFlags(cmd *cobra.Command, meta *store.Metadata) {
+	cmd.Flags().StringVar(&meta.Label, "label", "", "human-readable account label")
+	cmd.Flags().StringVar(&meta.Owner, "owner", "", "team owning the account")
+	cmd.Flags().StringVar(&meta.Purpose, "purpose", "", "what the account is used for")
+	cmd.Flags().StringSliceVar(&meta.Tags, "tag", nil, "tag to attach to the account (repeatable)")
+}
+
+func newKeysImportCmd() *cobra.Command {
+	var keystoreFile, privateKeyFile, passwordFile, accountPasswordFile, backend, keyName string
+	var meta store.Metadata
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import an existing key from a V3 keystore file or a raw hex private key",
+		Example: `  signer keys import --keystore UTC--2024-01-01T00-00-00Z--abc.json
+  signer keys import --private-key-file key.hex
+  echo $KEY | signer keys import --private-key-file -`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (keystoreFile == "") == (privateKeyFile == "") {
+				return errors.New("exactly one of --keystore or --private-key-file is required")
+			}
+
+			var privateKey *ecdsa.PrivateKey
+			var err error
+			if keystoreFile != "" {
+				privateKey, err = readKeystore(keystoreFile, passwordFile)
+			} else {
+				privateKey, err = readHexKey(privateKeyFile)
+			}
+			if err != nil {
+				return err
+			}
+
+			s, cfg, err := loadSigner(storeReadWrite)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			opts, err := createKeyOptions(cfg, backend, accountPasswordFile)
+			if err != nil {
+				return err
+			}
+			opts.Name = keyName
+			address, err := s.ImportKey(cmd.Context(), privateKey, opts, meta)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), address.Hex())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&keystoreFile, "keystore", "", "V3 keystore JSON file to import")
+	cmd.Flags().StringVar(&passwordFile, "password-file", "", "file holding the keystore password (prompted if omitted)")
+	cmd.Flags().StringVar(&privateKeyFile, "private-key-file", "", `file holding a hex-encoded private key, or "-" for stdin`)
+	cmd.Flags().StringVar(&accountPasswordFile, "account-password-file", "", "file holding the password of the imported account, with per-account passwords (prompted if omitted)")
+	cmd.Flags().StringVar(&backend, "backend", "", "composite key manager backend to import the key into (default: key_manager.default_backend)")
+	cmd.Flags().StringVar(&keyName, "key-name", "", "name of the key in the backend, e.g. the Vault transit key (default: generated)")
+	addMetadataFlags(cmd, &meta)
+	return cmd
+}
+
+func newKeysExportCmd() *cobra.Command {
+	var outFile, passwordFile, accountPasswordFile string
+
+	cmd := &cobra.Command{
+		Use:   "export <address>",
+		Short: "Export a key as a V3 keystore encrypted with a new password",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			address, err := parseAddress(args[0])
+			if err != nil {
+				return err
+			}
+			password, err := readPassword(passwordFile, "Export password", true)
+			if err != nil {
+				return err
+			}
+
+			s, _, err := loadSigner(storeReadStates)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			var keyJson []byte
+			err = withUnlock(cmd, s, address, accountPasswordFile, func() error {
+				keyJson, err = s.ExportKey(cmd.Context(), address, password)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if outFile == "" {
+				fmt.Fprintln(cmd.OutOrStdout(), string(keyJson))
+				return nil
+			}
+			return os.WriteFile(outFile, keyJson, 0600)
+		},
+	}
+	cmd.Flags().StringVar(&outFile, "out", "", "write the keystore to this file instead of stdout")
+	cmd.Flags().StringVar(&passwordFile, "password-file", "", "file holding the export password (prompted if omitted)")
+	cmd.Flags().StringVar(&accountPasswordFile, "account-password-file", "", "file holding the account password, if the account is locked (prompted if omitted)")
+	return cmd
+}
+
+func newKeysDeleteCmd() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "delete <address>",
+		Short: "Permanently delete a key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			address, err := parseAddress(args[0])
+			if err != nil {
+				return err
+			}
+			if !yes {
+				fmt.Fprintf(os.Stderr, "This permanently destroys the key for %s.\nType the address to confirm: ", address.Hex())
+				line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				if !strings.EqualFold(strings.TrimSpace(line), address.Hex()) {
+					return errors.New("confirmation did not match, aborting")
+				}
+			}
+
+			s, _, err := loadSigner(storeReadWrite)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			return s.DeleteKey(cmd.Context(), address)
+		},
+	}
+	cmd.Flags().BoolVar(&yes, "yes", false, "skip the confirmation prompt")
+	return cmd
+}
+
+func newKeysStateCmd(action, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   action + " <address>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			address, err := parseAddress(args[0])
+			if err != nil {
+				return err
+			}
+
+			s, _, err := loadSigner(storeReadWrite)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			apply := s.EnableAccount
+			if action == "disable" {
+				apply = s.DisableAccount
+			}
+			acc, err := apply(cmd.Context(), address)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", acc.Address.Hex(), acc.State)
+			return nil
+		},
+	}
+}
+
+func newKeysArchiveCmd() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "archive <address>",
+		Short: "Retire an account and archive its key material",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			address, err := parseAddress(args[0])
+			if err != nil {
+				return err
+			}
+			if !yes {
+				fmt.Fprintf(os.Stderr, "This permanently retires %s; it can never sign again.\nType the address to confirm: ", address.Hex())
+				line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				if !strings.EqualFold(strings.TrimSpace(line), address.Hex()) {
+					return errors.New("confirmation did not match, aborting")
+				}
+			}
+
+			s, _, err := loadSigner(storeReadWrite)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			acc, err := s.ArchiveAccount(cmd.Context(), address)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", acc.Address.Hex(), acc.State)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&yes, "yes", false, "skip the confirmation prompt")
+	return cmd
+}
+
+// readKeystore decrypts a V3 keystore file, prompting for its password if needed.
+func readKeystore(file, passwordFile string) (*ecdsa.PrivateKey, error) {
+	keyJson, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	password, err := readPassword(passwordFile, "Keystore password", false)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(keyJson, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+	return key.PrivateKey, nil
+}
+
+// readHexKey reads a hex-encoded private key from file, or from stdin if file is "-".
+func readHexKey(file string) (*ecdsa.PrivateKey, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return privateKey, nil
+}
+
+func newKeysRotatePasswordCmd() *cobra.Command {
+	var passwordFile string
+	var scrypt signer.ScryptParams
+
+	cmd := &cobra.Command{
+		Use:   "rotate-password",
+		Short: "Re-encrypt every local keystore file with a new password",
+		Long: `Re-encrypt every keystore file in key_dir with a new password. The current
+password is taken from the configuration. New files are written next to the
+originals and swapped in only once all of them were written; on failure the
+originals are restored. Update local.password before starting the signer again.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var params *signer.ScryptParams
+			if scrypt.N != 0 || scrypt.P != 0 {
+				if scrypt.P == 0 {
+					scrypt.P = keystore.StandardScryptP
+				}
+				if err := scrypt.Validate(); err != nil {
+					return err
+				}
+				params = &scrypt
+			}
+			password, err := readPassword(passwordFile, "New keystore password", true)
+			if err != nil {
+				return err
+			}
+
+			s, cfg, err := loadSigner(storeReadOnly)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			rotated, err := s.RotatePassword(cmd.Context(), sharedLocalPassword(cfg), password, params)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Re-encrypted %d keystore files. Update local.password in %s before restarting the signer.\n", rotated, config.FileUsed())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&passwordFile, "new-password-file", "", "file holding the new password (prompted if omitted)")
+	cmd.Flags().IntVar(&scrypt.N, "scrypt-n", 0, "scrypt N for the re-encrypted files, e.g. 262144 (default: keep each file's parameters)")
+	cmd.Flags().IntVar(&scrypt.P, "scrypt-p", 0, "scrypt P for the re-encrypted files (default 1 when --scrypt-n is set)")
+	return cmd
+}
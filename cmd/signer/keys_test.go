@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+)
+
+// testCLI runs commands against a configuration with a local key manager holding one key
+// encrypted with "password".
+type testCLI struct {
+	dir       string
+	config    string
+	storePath string
+	account   common.Address
+}
+
+func newTestCLI(t *testing.T) *testCLI {
+	t.Helper()
+	c := &testCLI{dir: t.TempDir()}
+	keyDir := filepath.Join(c.dir, "keys")
+	account, err := keystore.StoreKey(keyDir, "password", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.account = account.Address
+	c.storePath = filepath.Join(c.dir, "signer.db")
+	c.config = c.writeFile(t, "config.yaml",
+		fmt.Sprintf("key_manager:\n  type: local\n  local:\n    key_dir: %s\n    password: password\nstore:\n  path: %s\n", keyDir, c.storePath))
+	return c
+}
+
+// writeFile writes content to name in the test directory and returns its path.
+func (c *testCLI) writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(c.dir, name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// run executes the signer with args and returns what it printed to stdout.
+func (c *testCLI) run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := newRootCmd()
+	cmd.SetArgs(append([]string{"--config", c.config}, args...))
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+// mustRun is run for commands that must succeed.
+func (c *testCLI) mustRun(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := c.run(t, args...)
+	if err != nil {
+		t.Fatalf("signer %s: %v", strings.Join(args, " "), err)
+	}
+	return out
+}
+
+func TestKeysCommands(t *testing.T) {
+	c := newTestCLI(t)
+	existing := c.account.Hex()
+	other, err := keystore.StoreKey(filepath.Join(c.dir, "keys"), "password", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	labelled := other.Address.Hex()
+
+	lines := strings.Fields(c.mustRun(t, "keys", "list"))
+	if want := []string{existing, labelled}; !slices.Equal(slices.Sorted(slices.Values(lines)), slices.Sorted(slices.Values(want))) {
+		t.Fatalf("keys list = %v, want %v", lines, want)
+	}
+	c.mustRun(t, "keys", "label", labelled, "--label", "hot wallet", "--owner", "ops", "--tag", "hot")
+	if out := c.mustRun(t, "keys", "list", "--tag", "hot"); strings.TrimSpace(out) != labelled {
+		t.Fatalf("keys list --tag hot = %q, want only %s", out, labelled)
+	}
+	if out := c.mustRun(t, "keys", "list", "-l", "--owner", "ops"); !strings.Contains(out, "hot wallet") || strings.Contains(out, existing) {
+		t.Fatalf("keys list -l --owner ops = %q", out)
+	}
+
+	// A disabled account is hidden, cannot sign, and signs again once enabled.
+	if out := c.mustRun(t, "keys", "disable", existing); out != existing+" disabled\n" {
+		t.Fatalf("keys disable printed %q", out)
+	}
+	if out := c.mustRun(t, "keys", "list"); strings.Contains(out, existing) {
+		t.Fatalf("keys list shows the disabled account: %q", out)
+	}
+	if out := c.mustRun(t, "keys", "list", "--all"); !strings.Contains(out, existing) {
+		t.Fatalf("keys list --all omits the disabled account: %q", out)
+	}
+	if _, err := c.run(t, "sign", "message", "--from", existing, "--message", "hello"); !errors.Is(err, signer.ErrAccountDisabled) {
+		t.Fatalf("sign message from a disabled account: got %v, want ErrAccountDisabled", err)
+	}
+	c.mustRun(t, "keys", "enable", existing)
+	signature, err := hexutil.Decode(strings.TrimSpace(c.mustRun(t, "sign", "message", "--from", existing, "--message", "hello")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature[crypto.RecoveryIDOffset] -= 27 // the signature carries V as 27 or 28
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte("hello")), signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := crypto.PubkeyToAddress(*pub); got != c.account {
+		t.Fatalf("signature recovers to %s, want %s", got, existing)
+	}
+
+	// An exported key opens with the export password.
+	passwordFile := c.writeFile(t, "export-password", "export\n")
+	keyJson := c.mustRun(t, "keys", "export", existing, "--password-file", passwordFile)
+	key, err := keystore.DecryptKey([]byte(keyJson), "export")
+	if err != nil {
+		t.Fatalf("exported keystore: %v", err)
+	}
+	if key.Address != c.account {
+		t.Fatalf("exported key is for %s, want %s", key.Address.Hex(), existing)
+	}
+
+	if _, err := c.run(t, "keys", "disable", existing[:20]); err == nil {
+		t.Fatal("keys disable accepted a truncated address")
+	}
+
+	// Created last: keys of the local key manager use the standard scrypt parameters,
+	// which make every later command slow to start.
+	created := strings.TrimSpace(c.mustRun(t, "keys", "create", "--tag", "new"))
+	if !common.IsHexAddress(created) {
+		t.Fatalf("keys create printed %q, want an address", created)
+	}
+	if out := c.mustRun(t, "keys", "list", "--tag", "new"); strings.TrimSpace(out) != created {
+		t.Fatalf("keys list --tag new = %q, want only %s", out, created)
+	}
+}
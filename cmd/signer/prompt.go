@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/term"
+)
+
+// readPassword returns the password stored in file, or prompts for it on the terminal
+// when file is empty. With confirm set, an interactive prompt asks twice.
+func readPassword(file, prompt string, confirm bool) (string, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	password, err := promptSecret(prompt)
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		again, err := promptSecret("Repeat " + strings.ToLower(prompt[:1]) + prompt[1:])
+		if err != nil {
+			return "", err
+		}
+		if again != password {
+			return "", errors.New("passwords do not match")
+		}
+	}
+	return password, nil
+}
+
+// promptSecret reads a line from the terminal without echoing it. When stdin is not
+// a terminal the line is read as-is, so secrets can be piped in.
+func promptSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt+": ")
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		secret, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+		return string(secret), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// parseAddress parses a hex-encoded Ethereum address, rejecting malformed input.
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid address: %q", s)
+	}
+	return common.HexToAddress(s), nil
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/xueqianLu/ethsigner/internal/config"
+	"github.com/xueqianLu/ethsigner/internal/eth2"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+)
+
+const (
+	// reloadDebounce coalesces bursts of file events (editors, copies) into one reload.
+	reloadDebounce = 500 * time.Millisecond
+	// reloadTimeout bounds a single reload, including decrypting new keystore files.
+	reloadTimeout = 2 * time.Minute
+)
+
+// reloadableSettings are the configuration keys a reload applies. Every other change only
+// takes effect after a restart, which the reload logs.
+var reloadableSettings = []string{
+	"key_manager.timeouts.list",
+	"key_manager.timeouts.create",
+	"key_manager.timeouts.sign",
+}
+
+// reloader re-reads the configuration and rescans key storage on SIGHUP or when the
+// configuration file, a local key directory or the validator keystore directory changes.
+type reloader struct {
+	signer  *signer.Signer
+	eth2    *eth2.Signer // nil unless eth2 is enabled
+	initial config.Config
+
+	mu    sync.Mutex // serializes reloads
+	timer *time.Timer
+	tmu   sync.Mutex // guards timer
+}
+
+func newReloader(s *signer.Signer, eth2Signer *eth2.Signer, cfg config.Config) *reloader {
+	return &reloader{signer: s, eth2: eth2Signer, initial: cfg}
+}
+
+// Start begins listening for reload triggers until ctx is cancelled.
+func (r *reloader) Start(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				r.reload("SIGHUP")
+			}
+		}
+	}()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("Warning: file watching disabled, reload with SIGHUP instead: %v", err)
+		return
+	}
+
+	configFile := config.FileUsed()
+	if configFile != "" {
+		configFile, _ = filepath.Abs(configFile)
+		// Watch the directory rather than the file so editors that replace the file still trigger a reload.
+		if err := watcher.Add(filepath.Dir(configFile)); err != nil {
+			log.Printf("Warning: failed to watch configuration file: %v", err)
+		}
+	}
+	keyDirs := make(map[string]bool)
+	for _, dir := range r.watchedKeyDirs() {
+		dir, _ = filepath.Abs(dir)
+		if err := watcher.Add(dir); err != nil {
+			log.Printf("Warning: failed to watch key directory: %v", err)
+			continue
+		}
+		keyDirs[dir] = true
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				name, _ := filepath.Abs(event.Name)
+				if name == configFile || keyDirs[filepath.Dir(name)] {
+					r.schedule()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Warning: file watcher error: %v", err)
+			}
+		}
+	}()
+}
+
+// watchedKeyDirs returns the key directories of the local key managers in use and, with
+// eth2 enabled, the validator keystore and password directories.
+func (r *reloader) watchedKeyDirs() []string {
+	var dirs []string
+	km := r.initial.KeyManager
+	switch km.Type {
+	case "local":
+		dirs = append(dirs, km.Local.KeyDir)
+	case "composite":
+		for _, b := range km.Backends {
+			if b.Type == "local" {
+				dirs = append(dirs, b.Local.KeyDir)
+			}
+		}
+	}
+	if r.eth2 != nil {
+		dirs = append(dirs, r.initial.Eth2.KeystoreDir)
+		if r.initial.Eth2.PasswordDir != "" {
+			dirs = append(dirs, r.initial.Eth2.PasswordDir)
+		}
+	}
+	return dirs
+}
+
+// schedule queues a reload, restarting the debounce window if one is already pending.
+func (r *reloader) schedule() {
+	r.tmu.Lock()
+	defer r.tmu.Unlock()
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	r.timer = time.AfterFunc(reloadDebounce, func() { r.reload("file change") })
+}
+
+// reload applies the reloadable parts of the configuration and rescans the key storage.
+// A configuration that fails to load leaves the running settings untouched.
+func (r *reloader) reload(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log.Printf("Reloading configuration and keys (%s)", reason)
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Reload: failed to load configuration, keeping current settings: %v", err)
+	} else {
+		// Settings are compared with those the signer started with, so a pending change
+		// is reported on every reload until the signer is restarted.
+		var restart []string
+		for _, key := range changedSettings(r.initial, cfg) {
+			if !slices.Contains(reloadableSettings, key) {
+				restart = append(restart, key)
+			}
+		}
+		if len(restart) > 0 {
+			log.Printf("Warning: changed settings not applied until the signer is restarted: %s", strings.Join(restart, ", "))
+		}
+		r.signer.SetTimeouts(timeoutsFromConfig(cfg))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reloadTimeout)
+	defer cancel()
+	failed := false
+	if err := r.signer.Reload(ctx); err != nil {
+		log.Printf("Reload: failed to refresh keys: %v", err)
+		failed = true
+	}
+	if r.eth2 != nil {
+		if err := r.eth2.Reload(); err != nil {
+			log.Printf("Reload: failed to refresh validator keys: %v", err)
+			failed = true
+		}
+	}
+	if !failed {
+		log.Println("Reload complete")
+	}
+}
+
+// changedSettings returns the configuration keys, e.g. "server.port", whose values differ
+// between a and b. Lists are compared as a whole. Values are not returned, since many of
+// them are secrets.
+func changedSettings(a, b config.Config) []string {
+	var changed []string
+	var walk func(prefix string, a, b reflect.Value)
+	walk = func(prefix string, a, b reflect.Value) {
+		if a.Kind() != reflect.Struct {
+			if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+				changed = append(changed, prefix)
+			}
+			return
+		}
+		for i := 0; i < a.NumField(); i++ {
+			name, _, _ := strings.Cut(a.Type().Field(i).Tag.Get("mapstructure"), ",")
+			if name == "" {
+				name = strings.ToLower(a.Type().Field(i).Name)
+			}
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			walk(name, a.Field(i), b.Field(i))
+		}
+	}
+	walk("", reflect.ValueOf(a), reflect.ValueOf(b))
+	return changed
+}
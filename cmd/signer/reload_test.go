@@ -0,0 +1,31 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/xueqianLu/ethsigner/internal/config"
+)
+
+func TestChangedSettings(t *testing.T) {
+	var a config.Config
+	a.Server.Port = "8550"
+	a.KeyManager.Type = "composite"
+	a.KeyManager.Backends = []config.BackendConfig{{Name: "local", Type: "local"}}
+
+	b := a
+	b.KeyManager.Backends = slices.Clone(a.KeyManager.Backends)
+	if got := changedSettings(a, b); len(got) != 0 {
+		t.Fatalf("identical configurations: got changes %v", got)
+	}
+
+	b.Server.Port = "8551"
+	b.KeyManager.Timeouts.Sign = time.Second
+	b.KeyManager.Backends[0].Local.KeyDir = "/keys"
+	b.Admin.APISecret = "secret"
+	want := []string{"server.port", "key_manager.backends", "key_manager.timeouts.sign", "admin.api_secret"}
+	if got := changedSettings(a, b); !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/spf13/cobra"
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+	"github.com/xueqianLu/ethsigner/internal/clef"
+	"github.com/xueqianLu/ethsigner/internal/config"
+	"github.com/xueqianLu/ethsigner/internal/eth2"
+	"github.com/xueqianLu/ethsigner/internal/grpcapi"
+	"github.com/xueqianLu/ethsigner/internal/handler"
+	"github.com/xueqianLu/ethsigner/internal/middleware"
+	"github.com/xueqianLu/ethsigner/internal/server"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+)
+
+func newServeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run the signing HTTP server",
+		Args:  cobra.NoArgs,
+		RunE:  runServe,
+	}
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	ethSigner, cfg, err := loadSigner(storeReadWrite)
+	if err != nil {
+		return err
+	}
+
+	// Clef-compatible external API, for geth --signer.
+	var clefServers []*rpc.Server
+	var clefHandler http.Handler
+	if cfg.Clef.Enabled {
+		clefSrv, err := clef.NewServer(ethSigner)
+		if err != nil {
+			ethSigner.Close()
+			return err
+		}
+		clefServers = append(clefServers, clefSrv)
+		clefHandler = clefSrv
+
+		if cfg.Clef.IPCPath != "" {
+			ipcListener, ipcSrv, err := rpc.StartIPCEndpoint(cfg.Clef.IPCPath, clef.APIs(ethSigner))
+			if err != nil {
+				ethSigner.Close()
+				return fmt.Errorf("failed to start clef IPC endpoint: %w", err)
+			}
+			defer ipcListener.Close()
+			clefServers = append(clefServers, ipcSrv)
+			log.Printf("Clef API listening on %s", cfg.Clef.IPCPath)
+		}
+	}
+
+	// Web3Signer-compatible eth2 API for validator keys.
+	var eth2Signer *eth2.Signer
+	if cfg.Eth2.Enabled {
+		eth2Signer, err = newEth2Signer(cfg.Eth2)
+		if err != nil {
+			ethSigner.Close()
+			return err
+		}
+	}
+
+	mux := http.NewServeMux()
+	registerRoutes(mux, cfg, ethSigner, eth2Signer, clefHandler)
+	if cfg.Admin.APIKey == "" {
+		log.Println("Admin endpoints disabled: admin.api_key is not configured")
+	}
+
+	// Apply middleware
+	var finalHandler http.Handler = mux
+	finalHandler = handler.ValidateRequests(finalHandler)
+	finalHandler = middleware.Logging(finalHandler)
+	finalHandler = middleware.RequestID(finalHandler)
+
+	// Create a new server
+	socketMode, _ := cfg.Server.Socket.FileMode() // checked by cfg.Validate
+	srv := server.NewServer(finalHandler, server.Options{
+		Port: cfg.Server.Port,
+		Socket: server.SocketOptions{
+			Path:        cfg.Server.Socket.Path,
+			Mode:        socketMode,
+			Owner:       cfg.Server.Socket.Owner,
+			AllowedUIDs: cfg.Server.Socket.AllowedUIDs,
+			AllowedGIDs: cfg.Server.Socket.AllowedGIDs,
+		},
+	})
+
+	// Stop on SIGINT/SIGTERM so in-flight signing requests can finish before exit.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Pick up configuration and keystore changes on SIGHUP or file modification.
+	newReloader(ethSigner, eth2Signer, cfg).Start(ctx)
+
+	// The gRPC API shares the signer; either server failing stops both.
+	var grpcErrCh chan error
+	if cfg.GRPC.Enabled {
+		grpcSrv := grpcapi.NewServer(ethSigner, cfg.GRPC.APIKey, cfg.GRPC.APISecret)
+		grpcErrCh = make(chan error, 1)
+		go func() {
+			err := server.RunGRPC(ctx, grpcSrv, cfg.GRPC.Port, cfg.Server.ShutdownTimeout)
+			stop()
+			grpcErrCh <- err
+		}()
+		log.Printf("gRPC server starting on port %s", cfg.GRPC.Port)
+	}
+
+	// Start the server
+	log.Printf("Server starting on %s", srv.Describe())
+	fmt.Printf("Server listening on %s\n", srv.Describe())
+	serveErr := server.Run(ctx, srv, cfg.Server.ShutdownTimeout)
+	if serveErr != nil {
+		log.Printf("Server error: %v", serveErr)
+	}
+	stop()
+	if grpcErrCh != nil {
+		if err := <-grpcErrCh; err != nil {
+			log.Printf("gRPC server error: %v", err)
+			if serveErr == nil {
+				serveErr = err
+			}
+		}
+	}
+
+	for _, clefSrv := range clefServers {
+		clefSrv.Stop()
+	}
+
+	// Only release keys once no request can still be using them.
+	if err := ethSigner.Close(); err != nil {
+		log.Printf("Failed to close key manager: %v", err)
+	}
+	if eth2Signer != nil {
+		if err := eth2Signer.Close(); err != nil {
+			log.Printf("Failed to close slashing protection database: %v", err)
+		}
+	}
+	if serveErr != nil {
+		return serveErr
+	}
+	log.Println("Server stopped")
+	return nil
+}
+
+// routeRegistrar is the part of http.ServeMux routes are registered with.
+type routeRegistrar interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// registerRoutes registers the HTTP API on mux. eth2Signer and clefHandler are nil when
+// their APIs are disabled. Every route must be described by an operation of the OpenAPI
+// document in internal/handler; TestRoutesMatchOpenAPI checks that they agree.
+func registerRoutes(mux routeRegistrar, cfg config.Config, ethSigner *signer.Signer, eth2Signer *eth2.Signer, clefHandler http.Handler) {
+	// The API routes are served both unversioned, with the plain text errors existing
+	// clients expect, and under /v1/ with structured JSON errors.
+	handle := func(path string, h http.Handler) {
+		mux.Handle(path, h)
+		mux.Handle(strings.TrimSuffix(apierror.VersionPrefix, "/")+path, h)
+	}
+	handle("/accounts", handler.NewAccountsHandler(ethSigner))
+	handle("/create-account", handler.NewCreateAccountHandler(ethSigner))
+	handle("/sign-transaction", handler.NewSignTxHandler(ethSigner, cfg.Server.LegacyTxFormat))
+	handle("/sign-raw-transaction", handler.NewSignRawTxHandler(ethSigner))
+	handle("/sign-message", handler.NewSignMessageHandler(ethSigner))
+	handle("/health", handler.NewHealthHandler())
+	handle("/livez", handler.NewHealthHandler())
+	handle("/readyz", handler.NewReadinessHandler(ethSigner))
+
+	// Web3Signer-compatible eth1 API.
+	mux.Handle("/api/v1/eth1/publicKeys", handler.NewEth1PublicKeysHandler(ethSigner))
+	mux.Handle("/api/v1/eth1/sign/{identifier}", handler.NewEth1SignHandler(ethSigner))
+	mux.Handle("/upcheck", handler.NewUpcheckHandler())
+	mux.Handle("/openapi.json", handler.NewOpenAPIHandler())
+
+	// Clef-compatible external API, for geth --signer.
+	if clefHandler != nil {
+		mux.Handle("/clef", clefHandler)
+	}
+
+	// Web3Signer-compatible eth2 API for validator keys.
+	if eth2Signer != nil {
+		mux.Handle("/api/v1/eth2/publicKeys", handler.NewEth2PublicKeysHandler(eth2Signer))
+		mux.Handle("/api/v1/eth2/sign/{identifier}", handler.NewEth2SignHandler(eth2Signer))
+	}
+
+	// Admin endpoints are only exposed when dedicated credentials are configured.
+	if cfg.Admin.APIKey == "" {
+		return
+	}
+	adminAuth := middleware.NewAuthMiddleware(cfg.Admin.APIKey, cfg.Admin.APISecret)
+	wrapper := signer.NewImportWrapper()
+	handle("/admin/wrapping-key", adminAuth.Wrap(handler.NewWrappingKeyHandler(wrapper)))
+	handle("/admin/import-account", adminAuth.Wrap(handler.NewImportAccountHandler(ethSigner, wrapper)))
+	handle("/admin/disable-account", adminAuth.Wrap(handler.NewDisableAccountHandler(ethSigner)))
+	handle("/admin/enable-account", adminAuth.Wrap(handler.NewEnableAccountHandler(ethSigner)))
+	handle("/admin/archive-account", adminAuth.Wrap(handler.NewArchiveAccountHandler(ethSigner)))
+	handle("/admin/account-metadata", adminAuth.Wrap(handler.NewAccountMetadataHandler(ethSigner)))
+	handle("/admin/rotate-password", adminAuth.Wrap(handler.NewRotatePasswordHandler(ethSigner)))
+	// Unlocking takes an account password, so it must not be open to password guessing.
+	handle("/unlock-account", adminAuth.Wrap(handler.NewUnlockAccountHandler(ethSigner)))
+	handle("/lock-account", adminAuth.Wrap(handler.NewLockAccountHandler(ethSigner)))
+	if eth2Signer != nil {
+		mux.Handle("/admin/slashing-protection", adminAuth.Wrap(handler.NewSlashingProtectionHandler(eth2Signer)))
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xueqianLu/ethsigner/internal/config"
+	"github.com/xueqianLu/ethsigner/internal/eth2"
+	"github.com/xueqianLu/ethsigner/internal/handler"
+	"github.com/xueqianLu/ethsigner/internal/signer/signertest"
+)
+
+// routeRecorder records the patterns registered on a ServeMux.
+type routeRecorder struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func (r *routeRecorder) Handle(pattern string, h http.Handler) {
+	r.patterns = append(r.patterns, pattern)
+	r.ServeMux.Handle(pattern, h)
+}
+
+// TestRoutesMatchOpenAPI checks that the routes of the server, with every optional API
+// enabled, are exactly the operations of the OpenAPI document.
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	ethSigner := signertest.NewSigner(t, signertest.NewKeyManager(t, 0))
+	validatorKeys, err := eth2.NewKeyManager(t.TempDir(), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	protection, err := eth2.OpenSlashingProtection(filepath.Join(t.TempDir(), "slashing.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eth2Signer := eth2.NewSigner(validatorKeys, protection, nil)
+	defer eth2Signer.Close()
+	var cfg config.Config
+	cfg.Admin.APIKey = "admin"
+	cfg.Admin.APISecret = "secret"
+	// The Clef JSON-RPC server is not ours; stand in for it.
+	clefHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux := &routeRecorder{ServeMux: http.NewServeMux()}
+	registerRoutes(mux, cfg, ethSigner, eth2Signer, clefHandler)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var doc struct {
+		Paths map[string]map[string]struct {
+			Security    []any           `json:"security"`
+			RequestBody json.RawMessage `json:"requestBody"`
+			Validated   *bool           `json:"x-validated"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("GET /openapi.json: %v", err)
+	}
+
+	// Versioned paths are also served without the /v1 prefix.
+	want := map[string]bool{}
+	for path := range doc.Paths {
+		want[path] = true
+		if unversioned, ok := strings.CutPrefix(path, "/v1/"); ok {
+			want["/"+unversioned] = true
+		}
+	}
+	got := map[string]bool{}
+	for _, pattern := range mux.patterns {
+		if got[pattern] {
+			t.Errorf("route %s is registered twice", pattern)
+		}
+		got[pattern] = true
+		if !want[pattern] {
+			t.Errorf("route %s has no operation in the OpenAPI document", pattern)
+		}
+	}
+	for path := range want {
+		if !got[path] {
+			t.Errorf("OpenAPI path %s is not served", path)
+		}
+	}
+
+	// Each route answers the methods of its operations, and only those; admin operations
+	// refuse requests without credentials.
+	for path, ops := range doc.Paths {
+		target := strings.ReplaceAll(path, "{identifier}", "0x00")
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete} {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader("{}")))
+			op, documented := ops[strings.ToLower(method)]
+			admin := documented && len(op.Security) > 0
+			switch {
+			case admin && rec.Code != http.StatusUnauthorized:
+				t.Errorf("%s %s without credentials: status %d, want 401", method, path, rec.Code)
+			case documented && !admin && (rec.Code == http.StatusMethodNotAllowed || rec.Code == http.StatusUnauthorized):
+				t.Errorf("%s %s: status %d for a documented operation", method, path, rec.Code)
+			case !documented && rec.Code != http.StatusMethodNotAllowed && rec.Code != http.StatusUnauthorized:
+				t.Errorf("%s %s: status %d, want 405 for an undocumented method", method, path, rec.Code)
+			}
+		}
+	}
+
+	// Operations with a request body state whether ValidateRequests checks it, and it
+	// does exactly for those: a body with an unknown field is rejected with the
+	// validator's 400 before reaching the handler or the admin authentication.
+	validated := handler.ValidateRequests(mux)
+	for path, ops := range doc.Paths {
+		target := strings.ReplaceAll(path, "{identifier}", "0x00")
+		for method, op := range ops {
+			if op.RequestBody == nil {
+				continue
+			}
+			if op.Validated == nil {
+				t.Errorf("%s %s: request body without x-validated", method, path)
+				continue
+			}
+			if *op.Validated != strings.HasPrefix(path, "/v1/") {
+				t.Errorf("%s %s: x-validated is %t; only /v1/ operations are validated", method, path, *op.Validated)
+			}
+			rec := httptest.NewRecorder()
+			validated.ServeHTTP(rec, httptest.NewRequest(strings.ToUpper(method), target, strings.NewReader(`{"unexpected": 1}`)))
+			rejected := rec.Code == http.StatusBadRequest && strings.Contains(rec.Body.String(), "Invalid request body: ")
+			if rejected != *op.Validated {
+				t.Errorf("%s %s with an unknown field: status %d, x-validated %t: %s", method, path, rec.Code, *op.Validated, rec.Body)
+			}
+		}
+	}
+}
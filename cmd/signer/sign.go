@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+)
+
+func newSignCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Sign transactions and messages with a managed key",
+	}
+	cmd.AddCommand(newSignTxCmd(), newSignMessageCmd())
+	return cmd
+}
+
+func newSignTxCmd() *cobra.Command {
+	var (
+		from, to, value, data, chainID string
+		gasPrice, gasFeeCap, gasTipCap string
+		accountPasswordFile            string
+		nonce, gasLimit                uint64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tx",
+		Short: "Sign a legacy or EIP-1559 transaction and print the raw signed transaction",
+		Long: `Sign a transaction and print the raw signed transaction as hex.
+Passing --max-fee and --max-priority-fee produces an EIP-1559 transaction,
+otherwise a legacy transaction using --gas-price is signed.
+Amounts accept decimal or 0x-prefixed hex.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fromAddr, err := parseAddress(from)
+			if err != nil {
+				return err
+			}
+			var toAddr *common.Address
+			if to != "" {
+				addr, err := parseAddress(to)
+				if err != nil {
+					return err
+				}
+				toAddr = &addr
+			}
+			chain, err := parseBig("chain-id", chainID)
+			if err != nil {
+				return err
+			}
+			if chain == nil {
+				return errors.New("--chain-id is required")
+			}
+			amount, err := parseBig("value", value)
+			if err != nil {
+				return err
+			}
+			var payload []byte
+			if data != "" {
+				if payload, err = hexutil.Decode(data); err != nil {
+					return fmt.Errorf("invalid --data: %w", err)
+				}
+			}
+			feeCap, err := parseBig("max-fee", gasFeeCap)
+			if err != nil {
+				return err
+			}
+			tipCap, err := parseBig("max-priority-fee", gasTipCap)
+			if err != nil {
+				return err
+			}
+			price, err := parseBig("gas-price", gasPrice)
+			if err != nil {
+				return err
+			}
+
+			var tx *types.Transaction
+			if feeCap != nil && tipCap != nil {
+				tx = types.NewTx(&types.DynamicFeeTx{
+					ChainID:   chain,
+					Nonce:     nonce,
+					GasFeeCap: feeCap,
+					GasTipCap: tipCap,
+					Gas:       gasLimit,
+					To:        toAddr,
+					Value:     amount,
+					Data:      payload,
+				})
+			} else {
+				if price == nil {
+					return errors.New("either --gas-price or both --max-fee and --max-priority-fee are required")
+				}
+				tx = types.NewTx(&types.LegacyTx{
+					Nonce:    nonce,
+					GasPrice: price,
+					Gas:      gasLimit,
+					To:       toAddr,
+					Value:    amount,
+					Data:     payload,
+				})
+			}
+
+			s, _, err := loadSigner(storeReadStates)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			var signedTx *types.Transaction
+			err = withUnlock(cmd, s, fromAddr, accountPasswordFile, func() error {
+				signedTx, err = s.SignTx(cmd.Context(), fromAddr, tx, chain)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			rawTx, err := signedTx.MarshalBinary()
+			if err != nil {
+				return fmt.Errorf("failed to marshal signed transaction: %w", err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), hexutil.Encode(rawTx))
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&from, "from", "", "signing account address")
+	flags.StringVar(&to, "to", "", "recipient address (omit for contract creation)")
+	flags.Uint64Var(&nonce, "nonce", 0, "transaction nonce")
+	flags.StringVar(&value, "value", "0", "amount in wei")
+	flags.StringVar(&data, "data", "", "0x-prefixed call data")
+	flags.Uint64Var(&gasLimit, "gas-limit", 21000, "gas limit")
+	flags.StringVar(&gasPrice, "gas-price", "", "gas price in wei (legacy transactions)")
+	flags.StringVar(&gasFeeCap, "max-fee", "", "max fee per gas in wei (EIP-1559)")
+	flags.StringVar(&gasTipCap, "max-priority-fee", "", "max priority fee per gas in wei (EIP-1559)")
+	flags.StringVar(&chainID, "chain-id", "", "chain ID for replay protection")
+	flags.StringVar(&accountPasswordFile, "account-password-file", "", "file holding the account password, if the account is locked (prompted if omitted)")
+	cmd.MarkFlagRequired("from")
+	cmd.MarkFlagRequired("chain-id")
+	return cmd
+}
+
+func newSignMessageCmd() *cobra.Command {
+	var from, message, accountPasswordFile string
+
+	cmd := &cobra.Command{
+		Use:   "message",
+		Short: "Sign a message following EIP-191 and print the signature",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fromAddr, err := parseAddress(from)
+			if err != nil {
+				return err
+			}
+
+			s, _, err := loadSigner(storeReadStates)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			var signature []byte
+			err = withUnlock(cmd, s, fromAddr, accountPasswordFile, func() error {
+				signature, err = s.SignMessage(cmd.Context(), fromAddr, []byte(message))
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), hexutil.Encode(signature))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "signing account address")
+	cmd.Flags().StringVar(&message, "message", "", "message to sign")
+	cmd.Flags().StringVar(&accountPasswordFile, "account-password-file", "", "file holding the account password, if the account is locked (prompted if omitted)")
+	cmd.MarkFlagRequired("from")
+	cmd.MarkFlagRequired("message")
+	return cmd
+}
+
+// parseBig parses a decimal or 0x-prefixed integer flag. An empty value yields nil.
+func parseBig(flag, s string) (*big.Int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("invalid --%s: %q", flag, s)
+	}
+	return n, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/store"
+)
+
+func TestSignRefusesDisabledAccountWhileStoreLocked(t *testing.T) {
+	c := newTestCLI(t)
+	passwordFile := c.writeFile(t, "export-password", "export")
+
+	// Stand in for a running signer that disabled the account and holds the store.
+	accounts, err := store.Open(c.storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer accounts.Close()
+	if _, err := accounts.UpdateAccount(c.account, func(acc *store.Account) error {
+		acc.State = store.StateDisabled
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	commands := map[string][]string{
+		"sign message": {"sign", "message", "--from", c.account.Hex(), "--message", "hello"},
+		"sign tx":      {"sign", "tx", "--from", c.account.Hex(), "--chain-id", "1", "--gas-price", "1"},
+		"keys export":  {"keys", "export", c.account.Hex(), "--password-file", passwordFile},
+	}
+	for name, args := range commands {
+		t.Run(name, func(t *testing.T) {
+			if _, err := c.run(t, args...); !errors.Is(err, store.ErrLocked) {
+				t.Fatalf("got %v, want ErrLocked", err)
+			}
+		})
+	}
+
+	// Once the store is free the recorded state applies.
+	if err := accounts.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.run(t, "sign", "message", "--from", c.account.Hex(), "--message", "hello"); !errors.Is(err, signer.ErrAccountDisabled) {
+		t.Fatalf("got %v, want ErrAccountDisabled", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Package apierror writes the error responses of the HTTP API. The unversioned routes keep
+// answering with plain text for existing clients; routes under /v1/ answer with a JSON body
+// carrying a stable, machine-readable code and the request ID.
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// VersionPrefix is the path prefix of the versioned API.
+const VersionPrefix = "/v1/"
+
+// RequestIDHeader carries the ID of a request, in requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+// Error codes of the versioned API. Clients may rely on them; messages may change.
+const (
+	CodeInvalidRequest     = "invalid_request"
+	CodeInvalidAddress     = "invalid_address"
+	CodeInvalidChainID     = "invalid_chain_id"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeUnauthorized       = "unauthorized"
+	CodeAccountNotFound    = "account_not_found"
+	CodeAccountExists      = "account_exists"
+	CodeAccountArchived    = "account_archived"
+	CodeAccountLocked      = "account_locked"
+	CodePolicyDenied       = "policy_denied"
+	CodeWrongPassword      = "wrong_password"
+	CodeNotSupported       = "not_supported"
+	CodeConflict           = "conflict"
+	CodeBackendUnavailable = "backend_unavailable"
+	CodeInternal           = "internal_error"
+)
+
+// ErrorResponse is the JSON body of an error response on the versioned API.
+type ErrorResponse struct {
+	Error     string `json:"error"` // human-readable message
+	Code      string `json:"code"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// IsVersioned reports whether r was made to the versioned API.
+func IsVersioned(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, VersionPrefix)
+}
+
+// Write replies to r with an error. Requests to the versioned API get a JSON ErrorResponse;
+// others get message as plain text, as http.Error writes it.
+func Write(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	if !IsVersioned(r) {
+		http.Error(w, message, status)
+		return
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:     message,
+		Code:      code,
+		RequestID: h.Get(RequestIDHeader),
+	})
+}
@@ -0,0 +1,226 @@
+// Package clef serves Clef's external signer API, the JSON-RPC API go-ethereum uses to talk
+// to an external signer (geth --signer), on top of signer.Signer.
+package clef
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/store"
+)
+
+// ExternalAPIVersion is the version of Clef's external API implemented here.
+const ExternalAPIVersion = "6.1.0"
+
+// APIs returns the external API of s, in the "account" namespace.
+func APIs(s *signer.Signer) []rpc.API {
+	return []rpc.API{{Namespace: "account", Service: &API{signer: s}}}
+}
+
+// NewServer creates a JSON-RPC server exposing the external API of s. rpc.Server
+// implements http.Handler; for IPC, pass APIs(s) to rpc.StartIPCEndpoint instead.
+func NewServer(s *signer.Signer) (*rpc.Server, error) {
+	srv := rpc.NewServer()
+	for _, api := range APIs(s) {
+		if err := srv.RegisterName(api.Namespace, api.Service); err != nil {
+			return nil, err
+		}
+	}
+	return srv, nil
+}
+
+// API implements Clef's external API. Requests are signed without interactive approval;
+// the signer's account states apply as they do to the HTTP API.
+type API struct {
+	signer *signer.Signer
+}
+
+// SignTransactionResult is the result of account_signTransaction.
+type SignTransactionResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// List implements account_list.
+func (api *API) List(ctx context.Context) ([]common.Address, error) {
+	return api.signer.GetAccounts(ctx), nil
+}
+
+// New implements account_new. The key is created on the default backend without
+// metadata; with per-account passwords enabled it fails, as no password can be passed.
+func (api *API) New(ctx context.Context) (common.Address, error) {
+	return api.signer.CreateKey(ctx, signer.CreateKeyOptions{}, store.Metadata{})
+}
+
+// SignTransaction implements account_signTransaction. methodSelector is accepted for
+// compatibility; it only serves Clef's interactive approval.
+func (api *API) SignTransaction(ctx context.Context, args apitypes.SendTxArgs, methodSelector *string) (*SignTransactionResult, error) {
+	if args.ChainID == nil {
+		return nil, errors.New("chainId is required")
+	}
+	tx, err := args.ToTransaction()
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := api.signer.SignTx(ctx, args.From.Address(), tx, args.ChainID.ToInt())
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signedTx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal signed transaction: %w", err)
+	}
+	return &SignTransactionResult{Raw: raw, Tx: signedTx}, nil
+}
+
+// SignData implements account_signData for the content types geth sends:
+//   - text/plain: data is signed with the EIP-191 personal message prefix.
+//   - data/validator: data is {"address", "message"}, signed as EIP-191 version 0x00.
+//   - application/x-clique-header: data is a header as encoded for clique sealing.
+//   - data/typed: data is EIP-712 typed data.
+//
+// The signature has V 27 or 28, except for clique headers where it is 0 or 1.
+func (api *API) SignData(ctx context.Context, contentType string, addr common.MixedcaseAddress, data any) (hexutil.Bytes, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		hash    []byte
+		cliqueV bool
+	)
+	switch mediaType {
+	case accounts.MimetypeTextPlain:
+		msg, err := decodeHex(data)
+		if err != nil {
+			return nil, err
+		}
+		hash = accounts.TextHash(msg)
+	case accounts.MimetypeDataWithValidator:
+		hash, err = validatorHash(data)
+		if err != nil {
+			return nil, err
+		}
+	case accounts.MimetypeClique:
+		raw, err := decodeHex(data)
+		if err != nil {
+			return nil, err
+		}
+		hash, err = cliqueSealHash(raw)
+		if err != nil {
+			return nil, err
+		}
+		cliqueV = true
+	case accounts.MimetypeTypedData:
+		raw, err := decodeHex(data)
+		if err != nil {
+			return nil, err
+		}
+		var typedData apitypes.TypedData
+		if err := json.Unmarshal(raw, &typedData); err != nil {
+			return nil, fmt.Errorf("%w: %v", signer.ErrInvalidTypedData, err)
+		}
+		return api.SignTypedData(ctx, addr, typedData)
+	default:
+		return nil, fmt.Errorf("content type %q is not supported", contentType)
+	}
+
+	signature, err := api.signer.SignHash(ctx, addr.Address(), hash)
+	if err != nil {
+		return nil, err
+	}
+	if !cliqueV {
+		signature[crypto.RecoveryIDOffset] += 27
+	}
+	return signature, nil
+}
+
+// SignTypedData implements account_signTypedData.
+func (api *API) SignTypedData(ctx context.Context, addr common.MixedcaseAddress, typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	signature, _, err := api.signer.SignTypedData(ctx, addr.Address(), typedData)
+	return signature, err
+}
+
+// Version implements account_version.
+func (api *API) Version(ctx context.Context) (string, error) {
+	return ExternalAPIVersion, nil
+}
+
+// validatorHash returns the EIP-191 version 0x00 hash of the {"address", "message"}
+// data of a data/validator request.
+func validatorHash(data any) ([]byte, error) {
+	fields, ok := data.(map[string]any)
+	if !ok {
+		return nil, errors.New("data/validator data must be an object with address and message")
+	}
+	address, err := decodeHex(fields["address"])
+	if err != nil || len(address) != common.AddressLength {
+		return nil, errors.New("data/validator: invalid validator address")
+	}
+	message, err := decodeHex(fields["message"])
+	if err != nil {
+		return nil, fmt.Errorf("data/validator: invalid message: %w", err)
+	}
+	return crypto.Keccak256(bytes.Join([][]byte{{0x19, 0x00}, address, message}, nil)), nil
+}
+
+// cliqueSealHash returns the hash a clique sealer signs. geth sends the header as encoded
+// for sealing, that is with the extra data lacking the 65-byte signature; it is decoded
+// and re-encoded so that only well-formed headers are signed.
+func cliqueSealHash(raw []byte) ([]byte, error) {
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(raw, header); err != nil {
+		return nil, fmt.Errorf("invalid clique header: %w", err)
+	}
+	if header.WithdrawalsHash != nil || header.ExcessBlobGas != nil || header.BlobGasUsed != nil || header.ParentBeaconRoot != nil {
+		return nil, errors.New("invalid clique header: post-merge fields are set")
+	}
+	enc := []any{
+		header.ParentHash,
+		header.UncleHash,
+		header.Coinbase,
+		header.Root,
+		header.TxHash,
+		header.ReceiptHash,
+		header.Bloom,
+		header.Difficulty,
+		header.Number,
+		header.GasLimit,
+		header.GasUsed,
+		header.Time,
+		header.Extra,
+		header.MixDigest,
+		header.Nonce,
+	}
+	if header.BaseFee != nil {
+		enc = append(enc, header.BaseFee)
+	}
+	sealRLP, err := rlp.EncodeToBytes(enc)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(sealRLP), nil
+}
+
+// decodeHex decodes a 0x-prefixed hex string passed as data.
+func decodeHex(data any) ([]byte, error) {
+	s, ok := data.(string)
+	if !ok {
+		return nil, errors.New("data must be a hex string")
+	}
+	return hexutil.Decode(s)
+}
@@ -0,0 +1,268 @@
+package clef
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/xueqianLu/ethsigner/internal/signer/signertest"
+)
+
+// serve starts the external API over HTTP and returns its endpoint and an account it
+// holds a key for.
+func serve(t *testing.T) (string, common.Address) {
+	t.Helper()
+	km := signertest.NewKeyManager(t, 1)
+	srv, err := NewServer(signertest.NewSigner(t, km))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return ts.URL, km.GetAccounts(t.Context())[0]
+}
+
+func dial(t *testing.T, endpoint string) *rpc.Client {
+	t.Helper()
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+// recoverAddress returns the signer of hash. v is the value of V for recovery ID 0.
+func recoverAddress(t *testing.T, hash, signature []byte, v byte) common.Address {
+	t.Helper()
+	if len(signature) != crypto.SignatureLength {
+		t.Fatalf("signature is %d bytes", len(signature))
+	}
+	sig := bytes.Clone(signature)
+	if sig[crypto.RecoveryIDOffset] != v && sig[crypto.RecoveryIDOffset] != v+1 {
+		t.Fatalf("V = %d, want %d or %d", sig[crypto.RecoveryIDOffset], v, v+1)
+	}
+	sig[crypto.RecoveryIDOffset] -= v
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crypto.PubkeyToAddress(*pub)
+}
+
+// TestExternalSigner drives the API with geth's own client for external signers.
+func TestExternalSigner(t *testing.T) {
+	endpoint, address := serve(t)
+	ext, err := external.NewExternalSigner(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := accounts.Account{Address: address}
+	if accs := ext.Accounts(); len(accs) != 1 || accs[0].Address != address {
+		t.Fatalf("accounts = %v, want [%s]", accs, address)
+	}
+
+	to := common.HexToAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359")
+	chainID := big.NewInt(11155111)
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID: chainID, Nonce: 3, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(3e10),
+		Gas: 21000, To: &to, Value: big.NewInt(1e15),
+	})
+	signedTx, err := ext.SignTx(account, tx, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signedTx.Hash() == tx.Hash() || signedTx.Nonce() != 3 || *signedTx.To() != to {
+		t.Fatalf("signed transaction does not match the request: %+v", signedTx)
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(chainID), signedTx); err != nil || from != address {
+		t.Fatalf("transaction sender = %s, %v, want %s", from, err, address)
+	}
+
+	signature, err := ext.SignText(account, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recoverAddress(t, accounts.TextHash([]byte("hello")), signature, 0); got != address {
+		t.Fatalf("text signature recovers to %s, want %s", got, address)
+	}
+
+	header := &types.Header{
+		ParentHash: common.HexToHash("0x01"), Difficulty: big.NewInt(2), Number: big.NewInt(1),
+		GasLimit: 8_000_000, Time: 1_700_000_000, Extra: make([]byte, 32+crypto.SignatureLength),
+	}
+	signature, err = ext.SignData(account, accounts.MimetypeClique, clique.CliqueRLP(header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recoverAddress(t, clique.SealHash(header).Bytes(), signature, 0); got != address {
+		t.Fatalf("clique seal recovers to %s, want %s", got, address)
+	}
+}
+
+func TestSignTransactionResult(t *testing.T) {
+	endpoint, address := serve(t)
+	client := dial(t, endpoint)
+	to := common.NewMixedcaseAddress(common.HexToAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"))
+	args := apitypes.SendTxArgs{
+		From:     common.NewMixedcaseAddress(address),
+		To:       &to,
+		Gas:      21000,
+		GasPrice: (*hexutil.Big)(big.NewInt(2e10)),
+		Value:    hexutil.Big(*big.NewInt(1000)),
+		Nonce:    7,
+		ChainID:  (*hexutil.Big)(big.NewInt(11155111)),
+	}
+
+	var res SignTransactionResult
+	if err := client.Call(&res, "account_signTransaction", args); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := res.Tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(res.Raw, raw) {
+		t.Fatalf("raw = %x, want the encoding of tx %x", res.Raw, raw)
+	}
+	if res.Tx.Nonce() != 7 || res.Tx.ChainId().Int64() != 11155111 {
+		t.Fatalf("signed transaction does not match the request: %+v", res.Tx)
+	}
+
+	// The method selector only serves interactive approval and is ignored.
+	if err := client.Call(&res, "account_signTransaction", args, "transfer(address,uint256)"); err != nil {
+		t.Fatalf("with a method selector: %v", err)
+	}
+
+	args.ChainID = nil
+	if err := client.Call(&res, "account_signTransaction", args); err == nil {
+		t.Fatal("signed a transaction without a chain ID")
+	}
+	args.ChainID = (*hexutil.Big)(big.NewInt(1))
+	args.From = to
+	if err := client.Call(&res, "account_signTransaction", args); err == nil {
+		t.Fatal("signed a transaction for an unknown account")
+	}
+}
+
+func TestSignData(t *testing.T) {
+	endpoint, address := serve(t)
+	client := dial(t, endpoint)
+	addr := common.NewMixedcaseAddress(address)
+	signData := func(contentType string, data any) ([]byte, error) {
+		var signature hexutil.Bytes
+		err := client.Call(&signature, "account_signData", contentType, &addr, data)
+		return signature, err
+	}
+
+	// text/plain, with a media type parameter.
+	signature, err := signData("text/plain; charset=utf-8", hexutil.Encode([]byte("hello")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recoverAddress(t, accounts.TextHash([]byte("hello")), signature, 27); got != address {
+		t.Fatalf("text/plain signature recovers to %s, want %s", got, address)
+	}
+
+	validator := common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	signature, err = signData(accounts.MimetypeDataWithValidator, map[string]string{
+		"address": validator.Hex(),
+		"message": hexutil.Encode([]byte("hello")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := crypto.Keccak256([]byte{0x19, 0x00}, validator.Bytes(), []byte("hello"))
+	if got := recoverAddress(t, hash, signature, 27); got != address {
+		t.Fatalf("data/validator signature recovers to %s, want %s", got, address)
+	}
+
+	// Clique seals keep V as 0 or 1.
+	header := &types.Header{
+		Difficulty: big.NewInt(1), Number: big.NewInt(10), GasLimit: 8_000_000,
+		Extra: make([]byte, 32+crypto.SignatureLength), BaseFee: big.NewInt(7),
+	}
+	signature, err = signData(accounts.MimetypeClique, hexutil.Encode(clique.CliqueRLP(header)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recoverAddress(t, clique.SealHash(header).Bytes(), signature, 0); got != address {
+		t.Fatalf("clique signature recovers to %s, want %s", got, address)
+	}
+
+	typedJSON := []byte(`{
+		"types": {
+			"EIP712Domain": [{"name": "name", "type": "string"}, {"name": "chainId", "type": "uint256"}],
+			"Mail": [{"name": "contents", "type": "string"}]
+		},
+		"primaryType": "Mail",
+		"domain": {"name": "test", "chainId": "1"},
+		"message": {"contents": "hello"}
+	}`)
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(typedJSON, &typedData); err != nil {
+		t.Fatal(err)
+	}
+	hash, _, err = apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err = signData(accounts.MimetypeTypedData, hexutil.Encode(typedJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := recoverAddress(t, hash, signature, 27); got != address {
+		t.Fatalf("data/typed signature recovers to %s, want %s", got, address)
+	}
+	var typedSignature hexutil.Bytes
+	if err := client.Call(&typedSignature, "account_signTypedData", &addr, typedData); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(typedSignature, signature) {
+		t.Fatal("account_signTypedData and data/typed signatures differ")
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        any
+	}{
+		{"unsupported content type", "application/json", "0x01"},
+		{"malformed content type", "text/plain; charset", "0x01"},
+		{"text not hex", accounts.MimetypeTextPlain, "hello"},
+		{"validator data not an object", accounts.MimetypeDataWithValidator, "0x01"},
+		{"short validator address", accounts.MimetypeDataWithValidator, map[string]string{"address": "0x01", "message": "0x01"}},
+		{"malformed clique header", accounts.MimetypeClique, "0x0102"},
+		{"malformed typed data", accounts.MimetypeTypedData, hexutil.Encode([]byte("{"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := signData(tt.contentType, tt.data); err == nil {
+				t.Fatal("signed invalid data")
+			}
+		})
+	}
+}
+
+func TestVersion(t *testing.T) {
+	endpoint, _ := serve(t)
+	var version string
+	if err := dial(t, endpoint).Call(&version, "account_version"); err != nil {
+		t.Fatal(err)
+	}
+	if version != ExternalAPIVersion {
+		t.Fatalf("version = %s, want %s", version, ExternalAPIVersion)
+	}
+}
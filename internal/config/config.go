@@ -1,54 +1,208 @@
 package config
 
 import (
-	"github.com/spf13/viper"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/viper"
 )
 
 // Config holds the application configuration.
 type Config struct {
 	Server     ServerConfig     `mapstructure:"server"`
 	KeyManager KeyManagerConfig `mapstructure:"key_manager"`
+	Store      StoreConfig      `mapstructure:"store"`
+	Admin      AdminConfig      `mapstructure:"admin"`
+	Eth2       Eth2Config       `mapstructure:"eth2"`
+	GRPC       GRPCConfig       `mapstructure:"grpc"`
+	Clef       ClefConfig       `mapstructure:"clef"`
+}
+
+// ClefConfig configures Clef's external signer API, which lets geth use the signer through
+// --signer. It is served on the HTTP API at /clef and, when IPCPath is set, on an IPC socket.
+type ClefConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	IPCPath string `mapstructure:"ipc_path"` // empty serves the API over HTTP only
+}
+
+// GRPCConfig configures the gRPC API served alongside the HTTP API. Every gRPC call is
+// authenticated with the HMAC scheme of the admin endpoints, using its own credentials.
+type GRPCConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	Port      string `mapstructure:"port"`
+	APIKey    string `mapstructure:"api_key"`
+	APISecret string `mapstructure:"api_secret"`
+}
+
+// Eth2Config configures consensus-layer validator signing with BLS12-381 keys.
+type Eth2Config struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	KeystoreDir string `mapstructure:"keystore_dir"` // EIP-2335 keystores (*.json)
+	// PasswordDir holds one <keystore name without .json>.txt password file per keystore.
+	// When empty, every keystore is decrypted with Password.
+	PasswordDir          string `mapstructure:"password_dir"`
+	Password             string `mapstructure:"password"`
+	SlashingProtectionDB string `mapstructure:"slashing_protection_db"`
+	// GenesisForkVersion is the network's 0x-prefixed 4-byte GENESIS_FORK_VERSION, needed
+	// to sign validator registrations.
+	GenesisForkVersion string `mapstructure:"genesis_fork_version"`
+}
+
+// StoreConfig holds the configuration for the embedded account store.
+type StoreConfig struct {
+	Path string `mapstructure:"path"`
+}
+
+// AdminConfig holds the HMAC credentials guarding the admin endpoints.
+// The admin endpoints are disabled when no API key is configured.
+type AdminConfig struct {
+	APIKey    string `mapstructure:"api_key"`
+	APISecret string `mapstructure:"api_secret"`
 }
 
 // KeyManagerConfig holds the configuration for the key manager.
 type KeyManagerConfig struct {
+	Type  string      `mapstructure:"type"` // "local", "vault" or "composite"
+	Local LocalConfig `mapstructure:"local"`
+	Vault VaultConfig `mapstructure:"vault"`
+	// Backends lists the key managers combined by type "composite".
+	Backends       []BackendConfig `mapstructure:"backends"`
+	DefaultBackend string          `mapstructure:"default_backend"` // receives new keys; defaults to the first backend
+	Timeouts       TimeoutsConfig  `mapstructure:"timeouts"`
+}
+
+// BackendConfig configures one named key manager of a composite key manager.
+type BackendConfig struct {
+	Name  string      `mapstructure:"name"`
 	Type  string      `mapstructure:"type"` // "local" or "vault"
 	Local LocalConfig `mapstructure:"local"`
 	Vault VaultConfig `mapstructure:"vault"`
 }
 
+// TimeoutsConfig holds the per-operation deadlines applied to key manager calls.
+// A zero duration disables the corresponding timeout.
+type TimeoutsConfig struct {
+	List   time.Duration `mapstructure:"list"`
+	Create time.Duration `mapstructure:"create"`
+	Sign   time.Duration `mapstructure:"sign"`
+}
+
 // LocalConfig holds the configuration for the local key manager.
 type LocalConfig struct {
-	KeyDir   string `mapstructure:"key_dir"`
-	Password string `mapstructure:"password"`
+	KeyDir     string `mapstructure:"key_dir"`
+	ArchiveDir string `mapstructure:"archive_dir"` // defaults to <key_dir>/archive
+	Password   string `mapstructure:"password"`
+	// PerAccountPasswords encrypts every key with its own password instead of Password.
+	// Keys are then loaded locked and must be unlocked before they can sign.
+	PerAccountPasswords bool          `mapstructure:"per_account_passwords"`
+	UnlockTTL           time.Duration `mapstructure:"unlock_ttl"`     // default unlock duration
+	MaxUnlockTTL        time.Duration `mapstructure:"max_unlock_ttl"` // upper bound for requested durations; 0 means none
 }
 
 // ServerConfig holds the server configuration.
 type ServerConfig struct {
+	// Port is the TCP port of the HTTP API. It may be empty when Socket.Path is set.
 	Port    string `mapstructure:"port"`
 	Address string `mapstructure:"address"`
+	// ShutdownTimeout is how long in-flight requests may take to finish after a termination signal.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	Socket          SocketConfig  `mapstructure:"socket"`
+	// LegacyTxFormat makes the unversioned /sign-transaction route also accept the former
+	// request format (JSON numbers, base64 data, decimal chainId). /v1/ is always strict.
+	LegacyTxFormat bool `mapstructure:"legacy_tx_format"`
+}
+
+// SocketConfig configures serving the HTTP API on a Unix domain socket.
+type SocketConfig struct {
+	Path  string `mapstructure:"path"`  // empty disables the socket
+	Mode  string `mapstructure:"mode"`  // octal permission of the socket file
+	Owner string `mapstructure:"owner"` // "user[:group]"; empty keeps the process owner
+	// AllowedUIDs and AllowedGIDs admit only socket callers whose peer credentials
+	// (SO_PEERCRED, Linux only) match a listed uid or gid. Empty admits every caller.
+	AllowedUIDs []int `mapstructure:"allowed_uids"`
+	AllowedGIDs []int `mapstructure:"allowed_gids"`
+}
+
+// FileMode parses Mode.
+func (s SocketConfig) FileMode() (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s.Mode, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid mode %q", s.Mode)
+	}
+	return os.FileMode(mode), nil
 }
 
 // VaultConfig holds the Vault configuration.
 type VaultConfig struct {
-	Address     string `mapstructure:"address"`
-	Token       string `mapstructure:"token"`
-	TransitPath string `mapstructure:"transit_path"`
+	Address     string          `mapstructure:"address"`
+	Token       string          `mapstructure:"token"` // used with auth.method "token"
+	TransitPath string          `mapstructure:"transit_path"`
+	Auth        VaultAuthConfig `mapstructure:"auth"`
+	// RefreshInterval is how often the key inventory is re-read, to pick up keys created
+	// elsewhere, e.g. by another signer replica. Zero disables the periodic refresh.
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+	// KeyPrefix is prepended to the random UUID naming each new transit key; defaults to "eth-key-".
+	KeyPrefix string `mapstructure:"key_prefix"`
+}
+
+// VaultAuthConfig selects how the signer logs in to Vault. With "approle" or "kubernetes"
+// the token is obtained at startup, renewed in the background and re-acquired on failure.
+type VaultAuthConfig struct {
+	Method    string `mapstructure:"method"`     // "token" (default), "approle" or "kubernetes"
+	MountPath string `mapstructure:"mount_path"` // defaults to the method name
+	RoleID    string `mapstructure:"role_id"`    // approle
+	SecretID  string `mapstructure:"secret_id"`  // approle
+	Role      string `mapstructure:"role"`       // kubernetes
+	JWTPath   string `mapstructure:"jwt_path"`   // kubernetes; defaults to the service account token
+}
+
+const (
+	defaultUnlockTTL            = 15 * time.Minute
+	defaultVaultRefreshInterval = time.Minute
+)
+
+// configFile overrides the config.yaml lookup in the working directory when set.
+var configFile string
+
+// SetConfigFile makes LoadConfig read the given file instead of searching for config.yaml
+// in the working directory.
+func SetConfigFile(path string) {
+	configFile = path
 }
 
 // LoadConfig reads configuration from file or environment variables.
+// Secret references in passwords, tokens and API credentials are resolved, see resolveSecrets.
 func LoadConfig() (config Config, err error) {
 	viper.AddConfigPath(".")
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
+	if configFile != "" {
+		viper.SetConfigFile(configFile)
+	}
 	viper.AutomaticEnv()
 
 	// Set default values
 	viper.SetDefault("server.port", "8080")
+	viper.SetDefault("server.shutdown_timeout", "15s")
+	viper.SetDefault("server.socket.mode", "0660")
+	viper.SetDefault("grpc.port", "9090")
 	viper.SetDefault("vault.addr", "http://127.0.0.1:8200")
 	viper.SetDefault("vault.token", "root")
 	viper.SetDefault("vault.transit_path", "transit")
+	viper.SetDefault("key_manager.local.unlock_ttl", defaultUnlockTTL)
+	viper.SetDefault("key_manager.vault.refresh_interval", defaultVaultRefreshInterval)
+	viper.SetDefault("key_manager.local.max_unlock_ttl", "8h")
+	viper.SetDefault("store.path", "./data/signer.db")
+	viper.SetDefault("eth2.slashing_protection_db", "./data/slashing-protection.db")
+	viper.SetDefault("key_manager.timeouts.list", "2s")
+	viper.SetDefault("key_manager.timeouts.create", "8s")
+	viper.SetDefault("key_manager.timeouts.sign", "5s")
 
 	if err = viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -57,7 +211,187 @@ func LoadConfig() (config Config, err error) {
 		}
 	}
 
-	err = viper.Unmarshal(&config)
-	log.Printf("Loaded config: %+v", config)
+	if err = viper.Unmarshal(&config); err != nil {
+		return
+	}
+	// Defaults cannot be declared for list entries, so backends get theirs here.
+	for i := range config.KeyManager.Backends {
+		if config.KeyManager.Backends[i].Local.UnlockTTL == 0 {
+			config.KeyManager.Backends[i].Local.UnlockTTL = defaultUnlockTTL
+		}
+		if config.KeyManager.Backends[i].Vault.RefreshInterval == 0 {
+			config.KeyManager.Backends[i].Vault.RefreshInterval = defaultVaultRefreshInterval
+		}
+	}
+	log.Printf("Loaded config: %+v", config.Redacted())
+	err = config.resolveSecrets()
 	return
 }
+
+// Validate reports every problem with the configuration that would prevent the signer from starting.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Server.Port == "" && c.Server.Socket.Path == "" {
+		errs = append(errs, errors.New("server: port or socket.path must be set"))
+	} else if port, err := strconv.Atoi(c.Server.Port); c.Server.Port != "" && (err != nil || port <= 0 || port > 65535) {
+		errs = append(errs, fmt.Errorf("server.port: invalid port %q", c.Server.Port))
+	}
+	if c.Server.Socket.Path != "" {
+		if _, err := c.Server.Socket.FileMode(); err != nil {
+			errs = append(errs, fmt.Errorf("server.socket.mode: %w", err))
+		}
+	}
+	if (len(c.Server.Socket.AllowedUIDs) > 0 || len(c.Server.Socket.AllowedGIDs) > 0) && runtime.GOOS != "linux" {
+		errs = append(errs, errors.New("server.socket: allowed_uids and allowed_gids need peer credentials, which are only supported on Linux"))
+	}
+	if c.Server.ShutdownTimeout < 0 {
+		errs = append(errs, errors.New("server.shutdown_timeout: must not be negative"))
+	}
+
+	switch c.KeyManager.Type {
+	case "local":
+		errs = append(errs, validateLocal("key_manager.local", c.KeyManager.Local)...)
+	case "vault":
+		errs = append(errs, validateVault("key_manager.vault", c.KeyManager.Vault)...)
+	case "composite":
+		errs = append(errs, c.KeyManager.validateBackends()...)
+	default:
+		errs = append(errs, fmt.Errorf("key_manager.type: must be \"local\", \"vault\" or \"composite\", got %q", c.KeyManager.Type))
+	}
+
+	if c.Store.Path == "" {
+		errs = append(errs, errors.New("store.path: must be set"))
+	}
+
+	if c.Eth2.Enabled {
+		if c.Eth2.KeystoreDir == "" {
+			errs = append(errs, errors.New("eth2.keystore_dir: must be set"))
+		}
+		if c.Eth2.PasswordDir == "" && c.Eth2.Password == "" {
+			errs = append(errs, errors.New("eth2: password or password_dir must be set"))
+		}
+		if c.Eth2.SlashingProtectionDB == "" {
+			errs = append(errs, errors.New("eth2.slashing_protection_db: must be set"))
+		}
+		if v := c.Eth2.GenesisForkVersion; v != "" {
+			if b, err := hexutil.Decode(v); err != nil || len(b) != 4 {
+				errs = append(errs, fmt.Errorf("eth2.genesis_fork_version: %q is not a 0x-prefixed 4-byte hex value", v))
+			}
+		}
+	}
+
+	if c.GRPC.Enabled {
+		if port, err := strconv.Atoi(c.GRPC.Port); err != nil || port <= 0 || port > 65535 {
+			errs = append(errs, fmt.Errorf("grpc.port: invalid port %q", c.GRPC.Port))
+		} else if c.GRPC.Port == c.Server.Port {
+			errs = append(errs, errors.New("grpc.port: must differ from server.port"))
+		}
+		if c.GRPC.APIKey == "" || c.GRPC.APISecret == "" {
+			errs = append(errs, errors.New("grpc: api_key and api_secret must be set"))
+		}
+	}
+
+	if c.Clef.IPCPath != "" {
+		if !c.Clef.Enabled {
+			errs = append(errs, errors.New("clef.ipc_path: requires clef.enabled"))
+		} else if c.Clef.IPCPath == c.Server.Socket.Path {
+			errs = append(errs, errors.New("clef.ipc_path: must differ from server.socket.path"))
+		}
+	}
+
+	if (c.Admin.APIKey == "") != (c.Admin.APISecret == "") {
+		errs = append(errs, errors.New("admin: api_key and api_secret must be set together"))
+	}
+
+	t := c.KeyManager.Timeouts
+	if t.List < 0 || t.Create < 0 || t.Sign < 0 {
+		errs = append(errs, errors.New("key_manager.timeouts: must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateBackends checks the backend list of a composite key manager.
+func (k KeyManagerConfig) validateBackends() []error {
+	var errs []error
+	if len(k.Backends) == 0 {
+		errs = append(errs, errors.New("key_manager.backends: at least one backend must be configured"))
+	}
+	names := make(map[string]bool)
+	for i, b := range k.Backends {
+		prefix := fmt.Sprintf("key_manager.backends[%d]", i)
+		if b.Name == "" {
+			errs = append(errs, fmt.Errorf("%s.name: must be set", prefix))
+		} else if names[b.Name] {
+			errs = append(errs, fmt.Errorf("%s.name: duplicate backend name %q", prefix, b.Name))
+		}
+		names[b.Name] = true
+		switch b.Type {
+		case "local":
+			errs = append(errs, validateLocal(prefix+".local", b.Local)...)
+		case "vault":
+			errs = append(errs, validateVault(prefix+".vault", b.Vault)...)
+		default:
+			errs = append(errs, fmt.Errorf("%s.type: must be \"local\" or \"vault\", got %q", prefix, b.Type))
+		}
+	}
+	if k.DefaultBackend != "" && !names[k.DefaultBackend] {
+		errs = append(errs, fmt.Errorf("key_manager.default_backend: no backend named %q", k.DefaultBackend))
+	}
+	return errs
+}
+
+func validateLocal(prefix string, local LocalConfig) []error {
+	var errs []error
+	if local.KeyDir == "" {
+		errs = append(errs, fmt.Errorf("%s.key_dir: must be set", prefix))
+	}
+	if local.Password == "" && !local.PerAccountPasswords {
+		errs = append(errs, fmt.Errorf("%s.password: must be set unless per_account_passwords is enabled", prefix))
+	}
+	if local.UnlockTTL < 0 || local.MaxUnlockTTL < 0 {
+		errs = append(errs, fmt.Errorf("%s: unlock_ttl and max_unlock_ttl must not be negative", prefix))
+	}
+	if local.MaxUnlockTTL > 0 && local.UnlockTTL > local.MaxUnlockTTL {
+		errs = append(errs, fmt.Errorf("%s.unlock_ttl: must not exceed max_unlock_ttl", prefix))
+	}
+	return errs
+}
+
+func validateVault(prefix string, vault VaultConfig) []error {
+	var errs []error
+	if vault.Address == "" {
+		errs = append(errs, fmt.Errorf("%s.address: must be set", prefix))
+	}
+	auth := vault.Auth
+	switch auth.Method {
+	case "", "token":
+		if vault.Token == "" {
+			errs = append(errs, fmt.Errorf("%s.token: must be set", prefix))
+		}
+	case "approle":
+		if auth.RoleID == "" || auth.SecretID == "" {
+			errs = append(errs, fmt.Errorf("%s.auth: role_id and secret_id must be set for approle", prefix))
+		}
+	case "kubernetes":
+		if auth.Role == "" {
+			errs = append(errs, fmt.Errorf("%s.auth.role: must be set for kubernetes", prefix))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s.auth.method: must be \"token\", \"approle\" or \"kubernetes\", got %q", prefix, auth.Method))
+	}
+	if vault.TransitPath == "" {
+		errs = append(errs, fmt.Errorf("%s.transit_path: must be set", prefix))
+	}
+	if vault.RefreshInterval < 0 {
+		errs = append(errs, fmt.Errorf("%s.refresh_interval: must not be negative", prefix))
+	}
+	return errs
+}
+
+// FileUsed returns the path of the configuration file read by LoadConfig,
+// or an empty string if configuration came from defaults and the environment only.
+func FileUsed() string {
+	return viper.ConfigFileUsed()
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// Secret reference prefixes. A secret setting whose value starts with one of these is
+// resolved when the configuration is loaded instead of being used literally:
+//
+//	file:///run/secrets/signer-password   contents of the file, without trailing newlines
+//	env:SIGNER_PASSWORD                   value of the environment variable
+//	vault-kv:secret/data/signer#password  field of a Vault KV secret (v1 or v2)
+//
+// vault-kv references are read with vault.token (or VAULT_TOKEN), not with a token
+// obtained through vault.auth.
+const (
+	secretFilePrefix    = "file://"
+	secretEnvPrefix     = "env:"
+	secretVaultKVPrefix = "vault-kv:"
+)
+
+const redacted = "[REDACTED]"
+
+// isSecretRef reports whether value is a secret reference rather than a literal secret.
+func isSecretRef(value string) bool {
+	return strings.HasPrefix(value, secretFilePrefix) ||
+		strings.HasPrefix(value, secretEnvPrefix) ||
+		strings.HasPrefix(value, secretVaultKVPrefix)
+}
+
+// redact hides a literal secret. References are kept since they are safe to log.
+func redact(value string) string {
+	if value == "" || isSecretRef(value) {
+		return value
+	}
+	return redacted
+}
+
+// Redacted returns a copy of c with literal secrets masked, for logging.
+func (c Config) Redacted() Config {
+	c.KeyManager.Local.Password = redact(c.KeyManager.Local.Password)
+	c.KeyManager.Vault.Token = redact(c.KeyManager.Vault.Token)
+	c.KeyManager.Vault.Auth.SecretID = redact(c.KeyManager.Vault.Auth.SecretID)
+	c.KeyManager.Backends = slices.Clone(c.KeyManager.Backends)
+	for i := range c.KeyManager.Backends {
+		b := &c.KeyManager.Backends[i]
+		b.Local.Password = redact(b.Local.Password)
+		b.Vault.Token = redact(b.Vault.Token)
+		b.Vault.Auth.SecretID = redact(b.Vault.Auth.SecretID)
+	}
+	c.Admin.APISecret = redact(c.Admin.APISecret)
+	c.GRPC.APISecret = redact(c.GRPC.APISecret)
+	c.Eth2.Password = redact(c.Eth2.Password)
+	return c
+}
+
+// resolveSecrets replaces secret references in c with the secrets they point to. Settings
+// of the key manager backend that is not in use are left alone. The Vault token is
+// resolved first, since vault-kv references log in with it; composite backends read
+// vault-kv references with key_manager.vault as well.
+func (c *Config) resolveSecrets() error {
+	r := &secretResolver{vault: c.KeyManager.Vault}
+
+	var errs []error
+	resolve := func(name string, value *string) {
+		resolved, err := r.resolve(*value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			return
+		}
+		*value = resolved
+	}
+
+	usesVaultKV := strings.HasPrefix(c.KeyManager.Local.Password, secretVaultKVPrefix) ||
+		strings.HasPrefix(c.Admin.APIKey, secretVaultKVPrefix) ||
+		strings.HasPrefix(c.Admin.APISecret, secretVaultKVPrefix) ||
+		(c.GRPC.Enabled && strings.HasPrefix(c.GRPC.APIKey, secretVaultKVPrefix)) ||
+		(c.GRPC.Enabled && strings.HasPrefix(c.GRPC.APISecret, secretVaultKVPrefix)) ||
+		(c.Eth2.Enabled && strings.HasPrefix(c.Eth2.Password, secretVaultKVPrefix))
+	for _, b := range c.KeyManager.Backends {
+		usesVaultKV = usesVaultKV ||
+			strings.HasPrefix(b.Local.Password, secretVaultKVPrefix) ||
+			strings.HasPrefix(b.Vault.Token, secretVaultKVPrefix) ||
+			strings.HasPrefix(b.Vault.Auth.SecretID, secretVaultKVPrefix)
+	}
+	if c.KeyManager.Type == "vault" || usesVaultKV {
+		if strings.HasPrefix(c.KeyManager.Vault.Token, secretVaultKVPrefix) {
+			errs = append(errs, errors.New("key_manager.vault.token: cannot be read from Vault itself"))
+		} else {
+			resolve("key_manager.vault.token", &c.KeyManager.Vault.Token)
+			r.vault.Token = c.KeyManager.Vault.Token
+		}
+	}
+	if c.KeyManager.Type == "vault" {
+		resolve("key_manager.vault.auth.secret_id", &c.KeyManager.Vault.Auth.SecretID)
+	}
+	if c.KeyManager.Type == "local" {
+		resolve("key_manager.local.password", &c.KeyManager.Local.Password)
+	}
+	if c.KeyManager.Type == "composite" {
+		for i := range c.KeyManager.Backends {
+			b := &c.KeyManager.Backends[i]
+			prefix := fmt.Sprintf("key_manager.backends[%d]", i)
+			switch b.Type {
+			case "local":
+				resolve(prefix+".local.password", &b.Local.Password)
+			case "vault":
+				resolve(prefix+".vault.token", &b.Vault.Token)
+				resolve(prefix+".vault.auth.secret_id", &b.Vault.Auth.SecretID)
+			}
+		}
+	}
+	resolve("admin.api_key", &c.Admin.APIKey)
+	resolve("admin.api_secret", &c.Admin.APISecret)
+	if c.GRPC.Enabled {
+		resolve("grpc.api_key", &c.GRPC.APIKey)
+		resolve("grpc.api_secret", &c.GRPC.APISecret)
+	}
+	if c.Eth2.Enabled {
+		resolve("eth2.password", &c.Eth2.Password)
+	}
+
+	return errors.Join(errs...)
+}
+
+// secretResolver resolves secret references, creating a Vault client on first use.
+type secretResolver struct {
+	vault  VaultConfig
+	client *api.Client
+}
+
+func (r *secretResolver) resolve(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, secretFilePrefix):
+		path := strings.TrimPrefix(value, secretFilePrefix)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case strings.HasPrefix(value, secretEnvPrefix):
+		name := strings.TrimPrefix(value, secretEnvPrefix)
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return secret, nil
+	case strings.HasPrefix(value, secretVaultKVPrefix):
+		return r.readVaultKV(strings.TrimPrefix(value, secretVaultKVPrefix))
+	default:
+		return value, nil
+	}
+}
+
+// readVaultKV reads "path#field" from Vault. For KV version 2 the path must include the
+// data/ segment, e.g. secret/data/signer#password.
+func (r *secretResolver) readVaultKV(ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("vault-kv reference must be of the form path#field, got %q", ref)
+	}
+
+	if r.client == nil {
+		// The default configuration honours VAULT_ADDR, VAULT_TOKEN and the TLS variables.
+		vaultConfig := api.DefaultConfig()
+		if r.vault.Address != "" {
+			vaultConfig.Address = r.vault.Address
+		}
+		client, err := api.NewClient(vaultConfig)
+		if err != nil {
+			return "", fmt.Errorf("failed to create Vault client: %w", err)
+		}
+		if r.vault.Token != "" {
+			client.SetToken(r.vault.Token)
+		}
+		r.client = client
+	}
+
+	secret, err := r.client.Logical().Read(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from Vault: %w", path, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return "", fmt.Errorf("no secret found at %s", path)
+	}
+	data := secret.Data
+	// KV version 2 nests the fields under "data".
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %s has no string field %q", path, field)
+	}
+	return value, nil
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testVaultToken = "kv-token"
+
+// newFakeVaultKV serves KV version 1 secrets under kv/ and version 2 secrets under
+// secret/data/, and requires testVaultToken.
+func newFakeVaultKV(t *testing.T) *httptest.Server {
+	t.Helper()
+	secrets := map[string]interface{}{
+		"/v1/kv/signer": map[string]interface{}{"password": "kv1-secret"},
+		"/v1/secret/data/signer": map[string]interface{}{
+			"data":     map[string]interface{}{"password": "kv2-secret", "api_key": "grpc-key"},
+			"metadata": map[string]interface{}{"version": 1},
+		},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != testVaultToken {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		data, ok := secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSecretResolver(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(secretFile, []byte("file-secret\r\n\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SIGNER_TEST_SECRET", "env-secret")
+	t.Setenv("SIGNER_TEST_EMPTY", "")
+	vault := newFakeVaultKV(t)
+	r := &secretResolver{vault: VaultConfig{Address: vault.URL, Token: testVaultToken}}
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr string
+	}{
+		{value: "literal", want: "literal"},
+		{value: "", want: ""},
+		{value: "file://" + secretFile, want: "file-secret"},
+		{value: "file://" + filepath.Join(dir, "missing"), wantErr: "failed to read secret file"},
+		{value: "env:SIGNER_TEST_SECRET", want: "env-secret"},
+		{value: "env:SIGNER_TEST_EMPTY", want: ""},
+		{value: "env:SIGNER_TEST_UNSET", wantErr: "environment variable SIGNER_TEST_UNSET is not set"},
+		{value: "vault-kv:kv/signer#password", want: "kv1-secret"},
+		{value: "vault-kv:secret/data/signer#password", want: "kv2-secret"},
+		{value: "vault-kv:secret/data/signer#missing", wantErr: `secret secret/data/signer has no string field "missing"`},
+		{value: "vault-kv:secret/data/other#password", wantErr: "no secret found at secret/data/other"},
+		{value: "vault-kv:secret/data/signer", wantErr: "must be of the form path#field"},
+		{value: "vault-kv:#password", wantErr: "must be of the form path#field"},
+	}
+	for _, tt := range tests {
+		got, err := r.resolve(tt.value)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("resolve(%q): got error %v, want %q", tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("resolve(%q): %v", tt.value, err)
+		} else if got != tt.want {
+			t.Errorf("resolve(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	denied := &secretResolver{vault: VaultConfig{Address: vault.URL, Token: "wrong"}}
+	if _, err := denied.resolve("vault-kv:kv/signer#password"); err == nil || !strings.Contains(err.Error(), "failed to read kv/signer from Vault") {
+		t.Errorf("resolve with a rejected token: got error %v", err)
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "admin-key"), []byte("admin\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SIGNER_TEST_ADMIN_SECRET", "admin-secret")
+	t.Setenv("SIGNER_TEST_VAULT_TOKEN", testVaultToken)
+	vault := newFakeVaultKV(t)
+
+	c := Config{
+		KeyManager: KeyManagerConfig{
+			Type:  "local",
+			Local: LocalConfig{Password: "vault-kv:secret/data/signer#password"},
+			Vault: VaultConfig{Address: vault.URL, Token: "env:SIGNER_TEST_VAULT_TOKEN"},
+		},
+		Admin: AdminConfig{APIKey: "file://" + filepath.Join(dir, "admin-key"), APISecret: "env:SIGNER_TEST_ADMIN_SECRET"},
+		GRPC:  GRPCConfig{Enabled: true, APIKey: "vault-kv:secret/data/signer#api_key", APISecret: "vault-kv:kv/signer#password"},
+	}
+	if err := c.resolveSecrets(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct{ name, got, want string }{
+		{"key_manager.local.password", c.KeyManager.Local.Password, "kv2-secret"},
+		{"admin.api_key", c.Admin.APIKey, "admin"},
+		{"admin.api_secret", c.Admin.APISecret, "admin-secret"},
+		{"grpc.api_key", c.GRPC.APIKey, "grpc-key"},
+		{"grpc.api_secret", c.GRPC.APISecret, "kv1-secret"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// Every unresolvable setting is reported, by name.
+	c = Config{
+		KeyManager: KeyManagerConfig{Type: "local", Local: LocalConfig{Password: "env:SIGNER_TEST_UNSET"}},
+		Admin:      AdminConfig{APIKey: "file://" + filepath.Join(dir, "missing"), APISecret: "secret"},
+		GRPC:       GRPCConfig{Enabled: true, APIKey: "env:SIGNER_TEST_UNSET", APISecret: "secret"},
+	}
+	err := c.resolveSecrets()
+	if err == nil {
+		t.Fatal("resolveSecrets succeeded with unresolvable references")
+	}
+	for _, name := range []string{"key_manager.local.password", "admin.api_key", "grpc.api_key"} {
+		if !strings.Contains(err.Error(), name+": ") {
+			t.Errorf("error %q does not name %s", err, name)
+		}
+	}
+
+	// The token that reads vault-kv references cannot itself come from Vault.
+	c = Config{
+		KeyManager: KeyManagerConfig{
+			Type:  "local",
+			Vault: VaultConfig{Address: vault.URL, Token: "vault-kv:secret/data/signer#password"},
+		},
+		Admin: AdminConfig{APIKey: "vault-kv:secret/data/signer#api_key", APISecret: "secret"},
+	}
+	if err := c.resolveSecrets(); err == nil || !strings.Contains(err.Error(), "cannot be read from Vault itself") {
+		t.Errorf("vault-kv token: got error %v", err)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	c := Config{
+		KeyManager: KeyManagerConfig{Local: LocalConfig{Password: "literal"}, Vault: VaultConfig{Token: "env:VAULT_TOKEN"}},
+		Admin:      AdminConfig{APIKey: "admin", APISecret: "secret"},
+	}
+	r := c.Redacted()
+	if r.KeyManager.Local.Password != redacted || r.Admin.APISecret != redacted {
+		t.Errorf("literal secrets not redacted: %+v", r)
+	}
+	if r.KeyManager.Vault.Token != "env:VAULT_TOKEN" {
+		t.Errorf("reference redacted: %q", r.KeyManager.Vault.Token)
+	}
+	if c.KeyManager.Local.Password != "literal" {
+		t.Error("Redacted modified the original")
+	}
+}
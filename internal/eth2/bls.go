@@ -0,0 +1,95 @@
+// Package eth2 implements consensus-layer validator signing: BLS12-381 keys loaded from
+// EIP-2335 keystores and EIP-3076 slashing protection.
+package eth2
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// signatureDST is the domain separation tag of the proof-of-possession ciphersuite used
+// by the Ethereum consensus layer: signatures in G2, public keys in G1.
+var signatureDST = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+// PublicKey is a compressed BLS12-381 G1 point.
+type PublicKey [bls12381.SizeOfG1AffineCompressed]byte
+
+// Signature is a compressed BLS12-381 G2 point.
+type Signature [bls12381.SizeOfG2AffineCompressed]byte
+
+// Hex returns the 0x-prefixed hex encoding of the public key.
+func (p PublicKey) Hex() string {
+	return hexutil.Encode(p[:])
+}
+
+// Hex returns the 0x-prefixed hex encoding of the signature.
+func (s Signature) Hex() string {
+	return hexutil.Encode(s[:])
+}
+
+// ParsePublicKey decodes a 0x-prefixed hex public key and checks that it is a valid point.
+func ParsePublicKey(s string) (PublicKey, error) {
+	var pub PublicKey
+	raw, err := hexutil.Decode(s)
+	if err != nil {
+		return pub, fmt.Errorf("invalid public key %q: %w", s, err)
+	}
+	if len(raw) != len(pub) {
+		return pub, fmt.Errorf("invalid public key %q: expected %d bytes, got %d", s, len(pub), len(raw))
+	}
+	var point bls12381.G1Affine
+	if _, err := point.SetBytes(raw); err != nil {
+		return pub, fmt.Errorf("invalid public key %q: %w", s, err)
+	}
+	copy(pub[:], raw)
+	return pub, nil
+}
+
+// SecretKey is a BLS12-381 secret scalar.
+type SecretKey struct {
+	scalar *big.Int
+	public PublicKey
+}
+
+// SecretKeyFromBytes parses a 32-byte big-endian secret key.
+func SecretKeyFromBytes(b []byte) (*SecretKey, error) {
+	if len(b) != fr.Bytes {
+		return nil, fmt.Errorf("secret key must be %d bytes, got %d", fr.Bytes, len(b))
+	}
+	scalar := new(big.Int).SetBytes(b)
+	if scalar.Sign() == 0 || scalar.Cmp(fr.Modulus()) >= 0 {
+		return nil, errors.New("secret key is out of range")
+	}
+	var point bls12381.G1Affine
+	point.ScalarMultiplicationBase(scalar)
+	return &SecretKey{scalar: scalar, public: point.Bytes()}, nil
+}
+
+// PublicKey returns the public key belonging to sk.
+func (sk *SecretKey) PublicKey() PublicKey {
+	return sk.public
+}
+
+// Sign signs message, typically a 32-byte signing root.
+func (sk *SecretKey) Sign(message []byte) (Signature, error) {
+	point, err := bls12381.HashToG2(message, signatureDST)
+	if err != nil {
+		return Signature{}, fmt.Errorf("failed to hash message to curve: %w", err)
+	}
+	point.ScalarMultiplication(&point, sk.scalar)
+	return point.Bytes(), nil
+}
+
+// zero overwrites the secret scalar.
+func (sk *SecretKey) zero() {
+	words := sk.scalar.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	sk.scalar.SetInt64(0)
+}
@@ -0,0 +1,123 @@
+package eth2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+// Vectors of the bls/sign tests of the consensus spec tests.
+var signVectors = []struct {
+	secret, pubkey, message, signature string
+}{
+	{
+		secret:    "263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3",
+		pubkey:    "a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a",
+		message:   "5656565656565656565656565656565656565656565656565656565656565656",
+		signature: "882730e5d03f6b42c3abc26d3372625034e1d871b65a8a6b900a56dae22da98abbe1b68f85e49fe7652a55ec3d0591c20767677e33e5cbb1207315c41a9ac03be39c2e7668edc043d6cb1d9fd93033caa8a1c5b0e84bedaeb6c64972503a43eb",
+	},
+	{
+		secret:    "263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3",
+		pubkey:    "a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a",
+		message:   "0000000000000000000000000000000000000000000000000000000000000000",
+		signature: "b6ed936746e01f8ecf281f020953fbf1f01debd5657c4a383940b020b26507f6076334f91e2366c96e9ab279fb5158090352ea1c5b0c9274504f4f0e7053af24802e51e4568d164fe986834f41e55c8e850ce1f98458c0cfc9ab380b55285a55",
+	},
+	{
+		secret:    "47b8192d77bf871b62e87859d653922725724a5c031afeabc60bcef5ff665138",
+		pubkey:    "b301803f8b5ac4a1133581fc676dfedc60d891dd5fa99028805e5ea5b08d3491af75d0707adab3b70c6a6a580217bf81",
+		message:   "abababababababababababababababababababababababababababababababab",
+		signature: "9674e2228034527f4c083206032b020310face156d4a4685e2fcaec2f6f3665aa635d90347b6ce124eb879266b1e801d185de36a0a289b85e9039662634f2eea1e02e670bc7ab849d006a70b2f93b84597558a05b879c8d445f387a5d5b653df",
+	},
+	{
+		secret:    "328388aff0d4a5b7dc9205abd374e7e98f3cd9f3418edb4eafda5fb16473d216",
+		pubkey:    "b53d21a4cfd562c469cc81514d4ce5a6b577d8403d32a394dc265dd190b47fa9f829fdd7963afdf972e5e77854051f6f",
+		message:   "5656565656565656565656565656565656565656565656565656565656565656",
+		signature: "a4efa926610b8bd1c8330c918b7a5e9bf374e53435ef8b7ec186abf62e1b1f65aeaaeb365677ac1d1172a1f5b44b4e6d022c252c58486c0a759fbdc7de15a756acc4d343064035667a594b4c2a6f0b0b421975977f297dba63ee2f63ffe47bb6",
+	},
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestSignVectors(t *testing.T) {
+	for _, v := range signVectors {
+		sk, err := SecretKeyFromBytes(mustDecodeHex(t, v.secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := sk.PublicKey()
+		if got := hex.EncodeToString(pub[:]); got != v.pubkey {
+			t.Errorf("pubkey of %s = %s, want %s", v.secret, got, v.pubkey)
+		}
+		message := mustDecodeHex(t, v.message)
+		sig, err := sk.Sign(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(sig[:]); got != v.signature {
+			t.Errorf("signature of %s by %s = %s, want %s", v.message, v.pubkey, got, v.signature)
+		}
+		if !verify(t, pub, message, sig) {
+			t.Errorf("signature of %s by %s does not verify", v.message, v.pubkey)
+		}
+	}
+}
+
+// verify checks e(pub, H(message)) == e(G1, sig), the verification of the POP ciphersuite.
+func verify(t *testing.T, pub PublicKey, message []byte, sig Signature) bool {
+	t.Helper()
+	var pk bls12381.G1Affine
+	if _, err := pk.SetBytes(pub[:]); err != nil {
+		t.Fatal(err)
+	}
+	var s bls12381.G2Affine
+	if _, err := s.SetBytes(sig[:]); err != nil {
+		t.Fatal(err)
+	}
+	h, err := bls12381.HashToG2(message, signatureDST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, g1, _ := bls12381.Generators()
+	var negG1 bls12381.G1Affine
+	negG1.Neg(&g1)
+	ok, err := bls12381.PairingCheck([]bls12381.G1Affine{pk, negG1}, []bls12381.G2Affine{h, s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ok
+}
+
+func TestVerifyRejectsOtherMessage(t *testing.T) {
+	v := signVectors[0]
+	sk, err := SecretKeyFromBytes(mustDecodeHex(t, v.secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := sk.Sign(mustDecodeHex(t, v.message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verify(t, sk.PublicKey(), bytes.Repeat([]byte{0x57}, 32), sig) {
+		t.Error("signature verifies for another message")
+	}
+}
+
+func TestSecretKeyFromBytesRange(t *testing.T) {
+	if _, err := SecretKeyFromBytes(make([]byte, 32)); err == nil {
+		t.Error("zero secret key accepted")
+	}
+	// The group order r.
+	r := mustDecodeHex(t, "73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001")
+	if _, err := SecretKeyFromBytes(r); err == nil {
+		t.Error("secret key equal to the group order accepted")
+	}
+}
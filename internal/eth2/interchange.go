@@ -0,0 +1,199 @@
+package eth2
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	bolt "go.etcd.io/bbolt"
+)
+
+// InterchangeFormatVersion is the EIP-3076 interchange format version read and written.
+const InterchangeFormatVersion = "5"
+
+// Interchange is an EIP-3076 slashing protection interchange file. Integers are decimal strings.
+type Interchange struct {
+	Metadata InterchangeMetadata    `json:"metadata"`
+	Data     []InterchangeValidator `json:"data"`
+}
+
+// InterchangeMetadata identifies the format version and the network of an interchange file.
+type InterchangeMetadata struct {
+	InterchangeFormatVersion string `json:"interchange_format_version"`
+	GenesisValidatorsRoot    string `json:"genesis_validators_root"`
+}
+
+// InterchangeValidator holds the signing history of one validator.
+type InterchangeValidator struct {
+	Pubkey             string                   `json:"pubkey"`
+	SignedBlocks       []InterchangeBlock       `json:"signed_blocks"`
+	SignedAttestations []InterchangeAttestation `json:"signed_attestations"`
+}
+
+// InterchangeBlock is a signed block proposal.
+type InterchangeBlock struct {
+	Slot        string `json:"slot"`
+	SigningRoot string `json:"signing_root,omitempty"`
+}
+
+// InterchangeAttestation is a signed attestation.
+type InterchangeAttestation struct {
+	SourceEpoch string `json:"source_epoch"`
+	TargetEpoch string `json:"target_epoch"`
+	SigningRoot string `json:"signing_root,omitempty"`
+}
+
+// Export returns the complete signing history as an interchange file.
+func (sp *SlashingProtection) Export() (*Interchange, error) {
+	out := &Interchange{
+		Metadata: InterchangeMetadata{InterchangeFormatVersion: InterchangeFormatVersion},
+		Data:     []InterchangeValidator{},
+	}
+	err := sp.db.View(func(tx *bolt.Tx) error {
+		out.Metadata.GenesisValidatorsRoot = hexutil.Encode(make([]byte, 32))
+		if root := tx.Bucket(metaBucket).Get(genesisValidatorsRootKey); root != nil {
+			out.Metadata.GenesisValidatorsRoot = hexutil.Encode(root)
+		}
+		return tx.Bucket(validatorsBucket).ForEachBucket(func(pub []byte) error {
+			v := tx.Bucket(validatorsBucket).Bucket(pub)
+			validator := InterchangeValidator{
+				Pubkey:             hexutil.Encode(pub),
+				SignedBlocks:       []InterchangeBlock{},
+				SignedAttestations: []InterchangeAttestation{},
+			}
+			err := v.Bucket(blocksBucket).ForEach(func(slot, root []byte) error {
+				validator.SignedBlocks = append(validator.SignedBlocks, InterchangeBlock{
+					Slot:        formatUint64(slot),
+					SigningRoot: encodeRoot(root),
+				})
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			err = v.Bucket(attestsBucket).ForEach(func(target, value []byte) error {
+				validator.SignedAttestations = append(validator.SignedAttestations, InterchangeAttestation{
+					SourceEpoch: formatUint64(value[:8]),
+					TargetEpoch: formatUint64(target),
+					SigningRoot: encodeRoot(value[8:]),
+				})
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			out.Data = append(out.Data, validator)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export slashing protection data: %w", err)
+	}
+	return out, nil
+}
+
+// Import merges an interchange file into the database and returns the number of validators
+// it covered. Records already present are kept, so importing can only tighten protection.
+// The import is atomic: an invalid file leaves the database untouched.
+func (sp *SlashingProtection) Import(in *Interchange) (int, error) {
+	if in.Metadata.InterchangeFormatVersion != InterchangeFormatVersion {
+		return 0, fmt.Errorf("unsupported interchange format version %q, expected %q", in.Metadata.InterchangeFormatVersion, InterchangeFormatVersion)
+	}
+	genesisRoot, err := parseRoot(in.Metadata.GenesisValidatorsRoot)
+	if err != nil {
+		return 0, fmt.Errorf("invalid genesis_validators_root: %w", err)
+	}
+	if len(in.Data) == 0 {
+		return 0, nil
+	}
+
+	err = sp.db.Update(func(tx *bolt.Tx) error {
+		if err := checkGenesis(tx, genesisRoot); err != nil {
+			return err
+		}
+		for _, validator := range in.Data {
+			pub, err := ParsePublicKey(validator.Pubkey)
+			if err != nil {
+				return err
+			}
+			v, err := validatorBucket(tx, pub)
+			if err != nil {
+				return err
+			}
+			for _, block := range validator.SignedBlocks {
+				slot, err := strconv.ParseUint(block.Slot, 10, 64)
+				if err != nil {
+					return fmt.Errorf("%s: invalid block slot %q", pub.Hex(), block.Slot)
+				}
+				root, err := parseOptionalRoot(block.SigningRoot)
+				if err != nil {
+					return fmt.Errorf("%s: invalid block signing root: %w", pub.Hex(), err)
+				}
+				blocks := v.Bucket(blocksBucket)
+				if blocks.Get(uint64Key(slot)) == nil {
+					if err := blocks.Put(uint64Key(slot), root[:]); err != nil {
+						return err
+					}
+				}
+			}
+			for _, att := range validator.SignedAttestations {
+				source, err := strconv.ParseUint(att.SourceEpoch, 10, 64)
+				if err != nil {
+					return fmt.Errorf("%s: invalid source epoch %q", pub.Hex(), att.SourceEpoch)
+				}
+				target, err := strconv.ParseUint(att.TargetEpoch, 10, 64)
+				if err != nil {
+					return fmt.Errorf("%s: invalid target epoch %q", pub.Hex(), att.TargetEpoch)
+				}
+				if source > target {
+					return fmt.Errorf("%s: source epoch %d is after target epoch %d", pub.Hex(), source, target)
+				}
+				root, err := parseOptionalRoot(att.SigningRoot)
+				if err != nil {
+					return fmt.Errorf("%s: invalid attestation signing root: %w", pub.Hex(), err)
+				}
+				if v.Bucket(attestsBucket).Get(uint64Key(target)) == nil {
+					if err := recordAttestation(v, source, target, root[:]); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to import slashing protection data: %w", err)
+	}
+	return len(in.Data), nil
+}
+
+func formatUint64(b []byte) string {
+	return strconv.FormatUint(binary.BigEndian.Uint64(b), 10)
+}
+
+// encodeRoot encodes a recorded signing root, leaving out unknown (zero) roots.
+func encodeRoot(root []byte) string {
+	if [32]byte(root) == ([32]byte{}) {
+		return ""
+	}
+	return hexutil.Encode(root)
+}
+
+func parseRoot(s string) ([32]byte, error) {
+	raw, err := hexutil.Decode(s)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if len(raw) != 32 {
+		return [32]byte{}, fmt.Errorf("expected 32 bytes, got %d", len(raw))
+	}
+	return [32]byte(raw), nil
+}
+
+func parseOptionalRoot(s string) ([32]byte, error) {
+	if s == "" {
+		return [32]byte{}, nil
+	}
+	return parseRoot(s)
+}
@@ -0,0 +1,140 @@
+package eth2
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrKeyNotFound is returned when a public key is not loaded by the KeyManager.
+var ErrKeyNotFound = errors.New("validator key not found")
+
+// KeyManager holds the BLS12-381 validator keys decrypted from the EIP-2335 keystores
+// (*.json) in a directory.
+//
+// Each keystore is decrypted with the password in <passwordDir>/<keystore name without
+// .json>.txt when a password directory is configured, and with the shared password otherwise.
+type KeyManager struct {
+	keystoreDir string
+	passwordDir string
+	password    string
+
+	mu   sync.RWMutex
+	keys map[PublicKey]*SecretKey
+}
+
+// NewKeyManager creates a KeyManager and loads the keystores in keystoreDir. Keystores
+// that cannot be decrypted are logged and skipped.
+func NewKeyManager(keystoreDir, passwordDir, password string) (*KeyManager, error) {
+	km := &KeyManager{
+		keystoreDir: keystoreDir,
+		passwordDir: passwordDir,
+		password:    password,
+		keys:        make(map[PublicKey]*SecretKey),
+	}
+	if err := km.Reload(); err != nil {
+		return nil, err
+	}
+	return km, nil
+}
+
+// Reload re-reads the keystore directory and replaces the set of loaded keys.
+func (km *KeyManager) Reload() error {
+	entries, err := os.ReadDir(km.keystoreDir)
+	if err != nil {
+		return fmt.Errorf("failed to read validator keystore directory: %w", err)
+	}
+
+	keys := make(map[PublicKey]*SecretKey)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		sk, err := km.loadKeystore(entry.Name())
+		if err != nil {
+			log.Printf("Warning: failed to load validator keystore %s: %v", entry.Name(), err)
+			continue
+		}
+		if _, dup := keys[sk.PublicKey()]; dup {
+			sk.zero()
+			continue
+		}
+		keys[sk.PublicKey()] = sk
+		log.Printf("Loaded validator key %s", sk.PublicKey().Hex())
+	}
+
+	km.mu.Lock()
+	old := km.keys
+	km.keys = keys
+	km.mu.Unlock()
+	for _, sk := range old {
+		sk.zero()
+	}
+	return nil
+}
+
+func (km *KeyManager) loadKeystore(name string) (*SecretKey, error) {
+	data, err := os.ReadFile(filepath.Join(km.keystoreDir, name))
+	if err != nil {
+		return nil, err
+	}
+	password := km.password
+	if km.passwordDir != "" {
+		passwordFile := filepath.Join(km.passwordDir, strings.TrimSuffix(name, ".json")+".txt")
+		raw, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read password file: %w", err)
+		}
+		password = strings.TrimRight(string(raw), "\r\n")
+	}
+	return DecryptKeystore(data, password)
+}
+
+// PublicKeys returns the loaded public keys in a stable order.
+func (km *KeyManager) PublicKeys() []PublicKey {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+
+	keys := make([]PublicKey, 0, len(km.keys))
+	for pub := range km.keys {
+		keys = append(keys, pub)
+	}
+	slices.SortFunc(keys, func(a, b PublicKey) int { return bytes.Compare(a[:], b[:]) })
+	return keys
+}
+
+// HasKey reports whether the key for pub is loaded.
+func (km *KeyManager) HasKey(pub PublicKey) bool {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	_, ok := km.keys[pub]
+	return ok
+}
+
+// Sign signs root with the key for pub. It applies no slashing protection; use Signer.
+func (km *KeyManager) Sign(pub PublicKey, root [32]byte) (Signature, error) {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+
+	sk, ok := km.keys[pub]
+	if !ok {
+		return Signature{}, fmt.Errorf("%w: %s", ErrKeyNotFound, pub.Hex())
+	}
+	return sk.Sign(root[:])
+}
+
+// Close wipes the loaded keys from memory.
+func (km *KeyManager) Close() {
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	for _, sk := range km.keys {
+		sk.zero()
+	}
+	km.keys = make(map[PublicKey]*SecretKey)
+}
@@ -0,0 +1,200 @@
+package eth2
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrWrongPassword is returned when a keystore's checksum does not match the password.
+var ErrWrongPassword = errors.New("wrong keystore password")
+
+// keystoreJSON is the EIP-2335 keystore format.
+type keystoreJSON struct {
+	Crypto struct {
+		KDF      keystoreModule `json:"kdf"`
+		Checksum keystoreModule `json:"checksum"`
+		Cipher   keystoreModule `json:"cipher"`
+	} `json:"crypto"`
+	Pubkey  string `json:"pubkey"`
+	Path    string `json:"path"`
+	Version int    `json:"version"`
+}
+
+type keystoreModule struct {
+	Function string          `json:"function"`
+	Params   json.RawMessage `json:"params"`
+	Message  string          `json:"message"`
+}
+
+type scryptParams struct {
+	DKLen int    `json:"dklen"`
+	N     int    `json:"n"`
+	P     int    `json:"p"`
+	R     int    `json:"r"`
+	Salt  string `json:"salt"`
+}
+
+type pbkdf2Params struct {
+	DKLen int    `json:"dklen"`
+	C     int    `json:"c"`
+	PRF   string `json:"prf"`
+	Salt  string `json:"salt"`
+}
+
+type cipherParams struct {
+	IV string `json:"iv"`
+}
+
+// DecryptKeystore decrypts an EIP-2335 keystore and checks that the secret key matches
+// the public key the keystore declares.
+func DecryptKeystore(data []byte, password string) (*SecretKey, error) {
+	var ks keystoreJSON
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("invalid keystore: %w", err)
+	}
+	if ks.Version != 4 {
+		return nil, fmt.Errorf("unsupported keystore version %d", ks.Version)
+	}
+
+	decryptionKey, err := deriveKey(ks.Crypto.KDF, processPassword(password))
+	if err != nil {
+		return nil, err
+	}
+	defer clear(decryptionKey)
+
+	cipherText, err := hex.DecodeString(ks.Crypto.Cipher.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore cipher message: %w", err)
+	}
+	if ks.Crypto.Checksum.Function != "sha256" {
+		return nil, fmt.Errorf("unsupported keystore checksum function %q", ks.Crypto.Checksum.Function)
+	}
+	checksum, err := hex.DecodeString(ks.Crypto.Checksum.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore checksum: %w", err)
+	}
+	h := sha256.New()
+	h.Write(decryptionKey[16:32])
+	h.Write(cipherText)
+	if !bytes.Equal(h.Sum(nil), checksum) {
+		return nil, ErrWrongPassword
+	}
+
+	if ks.Crypto.Cipher.Function != "aes-128-ctr" {
+		return nil, fmt.Errorf("unsupported keystore cipher %q", ks.Crypto.Cipher.Function)
+	}
+	var params cipherParams
+	if err := json.Unmarshal(ks.Crypto.Cipher.Params, &params); err != nil {
+		return nil, fmt.Errorf("invalid keystore cipher params: %w", err)
+	}
+	iv, err := hex.DecodeString(params.IV)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, errors.New("invalid keystore cipher iv")
+	}
+	block, err := aes.NewCipher(decryptionKey[:16])
+	if err != nil {
+		return nil, err
+	}
+	secret := make([]byte, len(cipherText))
+	defer clear(secret)
+	cipher.NewCTR(block, iv).XORKeyStream(secret, cipherText)
+
+	sk, err := SecretKeyFromBytes(secret)
+	if err != nil {
+		return nil, err
+	}
+	if ks.Pubkey != "" {
+		declared, err := ParsePublicKey("0x" + strings.TrimPrefix(ks.Pubkey, "0x"))
+		if err != nil {
+			sk.zero()
+			return nil, err
+		}
+		if declared != sk.PublicKey() {
+			sk.zero()
+			return nil, fmt.Errorf("keystore secret does not match its public key %s", declared.Hex())
+		}
+	}
+	return sk, nil
+}
+
+// Limits on the key derivation parameters of a keystore, so that a crafted keystore cannot
+// make the signer allocate gigabytes or spin for minutes. They are well above the
+// parameters of the EIP-2335 test vectors and of the common key generation tools.
+const (
+	maxScryptN      = 1 << 18 // 256 MiB of memory at r = 8
+	maxScryptR      = 8
+	maxScryptP      = 16
+	maxPBKDF2Rounds = 1 << 20
+	keystoreDKLen   = 32
+)
+
+// deriveKey runs the keystore's key derivation function.
+func deriveKey(kdf keystoreModule, password []byte) ([]byte, error) {
+	switch kdf.Function {
+	case "scrypt":
+		var p scryptParams
+		if err := json.Unmarshal(kdf.Params, &p); err != nil {
+			return nil, fmt.Errorf("invalid scrypt params: %w", err)
+		}
+		salt, err := hex.DecodeString(p.Salt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scrypt salt: %w", err)
+		}
+		switch {
+		case p.N < 2 || p.N > maxScryptN || p.N&(p.N-1) != 0:
+			return nil, fmt.Errorf("scrypt n must be a power of two up to %d, got %d", maxScryptN, p.N)
+		case p.R < 1 || p.R > maxScryptR:
+			return nil, fmt.Errorf("scrypt r must be between 1 and %d, got %d", maxScryptR, p.R)
+		case p.P < 1 || p.P > maxScryptP:
+			return nil, fmt.Errorf("scrypt p must be between 1 and %d, got %d", maxScryptP, p.P)
+		case p.DKLen != keystoreDKLen:
+			return nil, fmt.Errorf("scrypt dklen must be %d, got %d", keystoreDKLen, p.DKLen)
+		}
+		return scrypt.Key(password, salt, p.N, p.R, p.P, p.DKLen)
+	case "pbkdf2":
+		var p pbkdf2Params
+		if err := json.Unmarshal(kdf.Params, &p); err != nil {
+			return nil, fmt.Errorf("invalid pbkdf2 params: %w", err)
+		}
+		if p.PRF != "hmac-sha256" {
+			return nil, fmt.Errorf("unsupported pbkdf2 prf %q", p.PRF)
+		}
+		salt, err := hex.DecodeString(p.Salt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pbkdf2 salt: %w", err)
+		}
+		switch {
+		case p.C < 1 || p.C > maxPBKDF2Rounds:
+			return nil, fmt.Errorf("pbkdf2 c must be between 1 and %d, got %d", maxPBKDF2Rounds, p.C)
+		case p.DKLen != keystoreDKLen:
+			return nil, fmt.Errorf("pbkdf2 dklen must be %d, got %d", keystoreDKLen, p.DKLen)
+		}
+		return pbkdf2.Key(sha256.New, string(password), salt, p.C, p.DKLen)
+	default:
+		return nil, fmt.Errorf("unsupported keystore kdf %q", kdf.Function)
+	}
+}
+
+// processPassword applies the EIP-2335 password normalisation: NFKD, then removal of the
+// C0 and C1 control codes and DEL.
+func processPassword(password string) []byte {
+	var out []byte
+	for _, r := range norm.NFKD.String(password) {
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			continue
+		}
+		out = append(out, string(r)...)
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package eth2
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// The keystores in testdata are the test vectors of EIP-2335.
+const (
+	eip2335Password = "𝔱𝔢𝔰𝔱𝔭𝔞𝔰𝔰𝔴𝔬𝔯𝔡🔑"
+	eip2335Secret   = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	eip2335Pubkey   = "0x9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07"
+)
+
+func TestDecryptKeystoreVectors(t *testing.T) {
+	for _, name := range []string{"scrypt", "pbkdf2"} {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile("testdata/" + name + ".json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			sk, err := DecryptKeystore(data, eip2335Password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(sk.scalar.FillBytes(make([]byte, 32))); got != eip2335Secret {
+				t.Errorf("secret = %s, want %s", got, eip2335Secret)
+			}
+			if got := sk.PublicKey().Hex(); got != eip2335Pubkey {
+				t.Errorf("pubkey = %s, want %s", got, eip2335Pubkey)
+			}
+
+			if _, err := DecryptKeystore(data, "testpassword"); !errors.Is(err, ErrWrongPassword) {
+				t.Errorf("wrong password: got %v, want ErrWrongPassword", err)
+			}
+		})
+	}
+}
+
+func TestDecryptKeystoreRejectsCostlyParams(t *testing.T) {
+	tests := []struct {
+		keystore string
+		params   map[string]any
+		want     string
+	}{
+		{"scrypt", map[string]any{"n": 1 << 20}, "scrypt n"},
+		{"scrypt", map[string]any{"n": 1000}, "scrypt n"},
+		{"scrypt", map[string]any{"r": 64}, "scrypt r"},
+		{"scrypt", map[string]any{"p": 1 << 20}, "scrypt p"},
+		{"scrypt", map[string]any{"dklen": 1 << 30}, "scrypt dklen"},
+		{"scrypt", map[string]any{"dklen": 16}, "scrypt dklen"},
+		{"pbkdf2", map[string]any{"c": 1 << 30}, "pbkdf2 c"},
+		{"pbkdf2", map[string]any{"c": 0}, "pbkdf2 c"},
+		{"pbkdf2", map[string]any{"dklen": 1 << 30}, "pbkdf2 dklen"},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile("testdata/" + tt.keystore + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ks map[string]any
+		if err := json.Unmarshal(data, &ks); err != nil {
+			t.Fatal(err)
+		}
+		params := ks["crypto"].(map[string]any)["kdf"].(map[string]any)["params"].(map[string]any)
+		for k, v := range tt.params {
+			params[k] = v
+		}
+		data, err = json.Marshal(ks)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = DecryptKeystore(data, eip2335Password)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s %v: got %v, want a %s error", tt.keystore, tt.params, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package eth2
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ssz "github.com/ferranbt/fastssz"
+)
+
+// ErrInvalidMessage is returned for a signing request whose message is malformed or whose
+// signing root cannot be computed.
+var ErrInvalidMessage = errors.New("invalid signing request")
+
+// SSZ list and vector sizes of the mainnet preset.
+const (
+	// maxValidatorsPerCommittee limits the aggregation bits of an attestation; from Electra
+	// on attestations span all MAX_COMMITTEES_PER_SLOT committees of a slot.
+	maxValidatorsPerCommittee = 2048
+	maxValidatorsPerSlot      = maxValidatorsPerCommittee * 64
+	committeeBitsSize         = 64 / 8      // bytes of Bitvector[MAX_COMMITTEES_PER_SLOT]
+	syncSubcommitteeBitsSize  = 512 / 4 / 8 // bytes of Bitvector[SYNC_COMMITTEE_SIZE / SYNC_COMMITTEE_SUBNET_COUNT]
+)
+
+// Uint64 is a uint64 encoded in JSON as a decimal string, as in the beacon node API.
+type Uint64 uint64
+
+// MarshalText encodes the number in decimal.
+func (u Uint64) MarshalText() ([]byte, error) {
+	return strconv.AppendUint(nil, uint64(u), 10), nil
+}
+
+// UnmarshalText decodes a decimal number.
+func (u *Uint64) UnmarshalText(input []byte) error {
+	v, err := strconv.ParseUint(string(input), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid uint64 %q", input)
+	}
+	*u = Uint64(v)
+	return nil
+}
+
+// MarshalText encodes the public key as 0x-prefixed hex.
+func (p PublicKey) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(p[:]).MarshalText()
+}
+
+// UnmarshalText decodes a 0x-prefixed hex public key without checking that it is a valid
+// point; use ParsePublicKey for keys to sign with.
+func (p *PublicKey) UnmarshalText(input []byte) error {
+	return hexutil.UnmarshalFixedText("PublicKey", input, p[:])
+}
+
+// MarshalText encodes the signature as 0x-prefixed hex.
+func (s Signature) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(s[:]).MarshalText()
+}
+
+// UnmarshalText decodes a 0x-prefixed hex signature.
+func (s *Signature) UnmarshalText(input []byte) error {
+	return hexutil.UnmarshalFixedText("Signature", input, s[:])
+}
+
+// Message is a consensus-layer object a validator key signs. The signing root is computed
+// from the message itself, see SigningRoot, so what is signed is what slashing protection
+// checked.
+type Message interface {
+	hashTreeRootWith(hh *ssz.Hasher) error
+}
+
+// forkMessage is a Message signed in the domain of the fork active at its epoch.
+type forkMessage interface {
+	Message
+	domainType() DomainType
+	epoch() uint64
+}
+
+// BeaconBlockHeader is a block header. A block and its header share the hash tree root,
+// so signing the header signs the block.
+type BeaconBlockHeader struct {
+	Slot          Uint64      `json:"slot"`
+	ProposerIndex Uint64      `json:"proposer_index"`
+	ParentRoot    common.Hash `json:"parent_root"`
+	StateRoot     common.Hash `json:"state_root"`
+	BodyRoot      common.Hash `json:"body_root"`
+}
+
+func (h *BeaconBlockHeader) domainType() DomainType { return DomainBeaconProposer }
+func (h *BeaconBlockHeader) epoch() uint64          { return uint64(h.Slot) / slotsPerEpoch }
+
+func (h *BeaconBlockHeader) hashTreeRootWith(hh *ssz.Hasher) error {
+	indx := hh.Index()
+	hh.PutUint64(uint64(h.Slot))
+	hh.PutUint64(uint64(h.ProposerIndex))
+	hh.PutBytes(h.ParentRoot[:])
+	hh.PutBytes(h.StateRoot[:])
+	hh.PutBytes(h.BodyRoot[:])
+	hh.Merkleize(indx)
+	return nil
+}
+
+// Checkpoint is an epoch and the root of its first block.
+type Checkpoint struct {
+	Epoch Uint64      `json:"epoch"`
+	Root  common.Hash `json:"root"`
+}
+
+func (c *Checkpoint) hashTreeRootWith(hh *ssz.Hasher) {
+	indx := hh.Index()
+	hh.PutUint64(uint64(c.Epoch))
+	hh.PutBytes(c.Root[:])
+	hh.Merkleize(indx)
+}
+
+// AttestationData is the vote of an attestation.
+type AttestationData struct {
+	Slot            Uint64      `json:"slot"`
+	Index           Uint64      `json:"index"`
+	BeaconBlockRoot common.Hash `json:"beacon_block_root"`
+	Source          Checkpoint  `json:"source"`
+	Target          Checkpoint  `json:"target"`
+}
+
+func (d *AttestationData) domainType() DomainType { return DomainBeaconAttester }
+func (d *AttestationData) epoch() uint64          { return uint64(d.Target.Epoch) }
+
+func (d *AttestationData) hashTreeRootWith(hh *ssz.Hasher) error {
+	indx := hh.Index()
+	hh.PutUint64(uint64(d.Slot))
+	hh.PutUint64(uint64(d.Index))
+	hh.PutBytes(d.BeaconBlockRoot[:])
+	d.Source.hashTreeRootWith(hh)
+	d.Target.hashTreeRootWith(hh)
+	hh.Merkleize(indx)
+	return nil
+}
+
+// AggregationSlot is the slot whose selection proof is signed to become an aggregator.
+type AggregationSlot struct {
+	Slot Uint64 `json:"slot"`
+}
+
+func (a *AggregationSlot) domainType() DomainType { return DomainSelectionProof }
+func (a *AggregationSlot) epoch() uint64          { return uint64(a.Slot) / slotsPerEpoch }
+
+func (a *AggregationSlot) hashTreeRootWith(hh *ssz.Hasher) error {
+	hh.PutUint64(uint64(a.Slot))
+	return nil
+}
+
+// Attestation is an aggregate attestation. CommitteeBits is set from Electra on.
+type Attestation struct {
+	AggregationBits hexutil.Bytes   `json:"aggregation_bits"`
+	Data            AttestationData `json:"data"`
+	Signature       Signature       `json:"signature"`
+	CommitteeBits   hexutil.Bytes   `json:"committee_bits,omitempty"`
+}
+
+func (a *Attestation) hashTreeRootWith(hh *ssz.Hasher) error {
+	limit := uint64(maxValidatorsPerCommittee)
+	if a.CommitteeBits != nil {
+		limit = maxValidatorsPerSlot
+		if len(a.CommitteeBits) != committeeBitsSize {
+			return fmt.Errorf("committee_bits must be %d bytes, got %d", committeeBitsSize, len(a.CommitteeBits))
+		}
+	}
+	if err := ssz.ValidateBitlist(a.AggregationBits, limit); err != nil {
+		return fmt.Errorf("aggregation_bits: %w", err)
+	}
+
+	indx := hh.Index()
+	hh.PutBitlist(a.AggregationBits, limit)
+	a.Data.hashTreeRootWith(hh)
+	hh.PutBytes(a.Signature[:])
+	if a.CommitteeBits != nil {
+		hh.PutBytes(a.CommitteeBits)
+	}
+	hh.Merkleize(indx)
+	return nil
+}
+
+// AggregateAndProof is an aggregate attestation published by an aggregator.
+type AggregateAndProof struct {
+	AggregatorIndex Uint64      `json:"aggregator_index"`
+	Aggregate       Attestation `json:"aggregate"`
+	SelectionProof  Signature   `json:"selection_proof"`
+}
+
+func (a *AggregateAndProof) domainType() DomainType { return DomainAggregateAndProof }
+func (a *AggregateAndProof) epoch() uint64 {
+	return uint64(a.Aggregate.Data.Slot) / slotsPerEpoch
+}
+
+func (a *AggregateAndProof) hashTreeRootWith(hh *ssz.Hasher) error {
+	indx := hh.Index()
+	hh.PutUint64(uint64(a.AggregatorIndex))
+	if err := a.Aggregate.hashTreeRootWith(hh); err != nil {
+		return err
+	}
+	hh.PutBytes(a.SelectionProof[:])
+	hh.Merkleize(indx)
+	return nil
+}
+
+// CheckFork checks that the attestation format matches fork, a beacon API version name
+// such as "DENEB" or "ELECTRA".
+func (a *AggregateAndProof) CheckFork(fork string) error {
+	switch fork {
+	case "PHASE0", "ALTAIR", "BELLATRIX", "CAPELLA", "DENEB":
+		if a.Aggregate.CommitteeBits == nil {
+			return nil
+		}
+	case "ELECTRA", "FULU":
+		if a.Aggregate.CommitteeBits != nil {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unknown fork version %q", fork)
+	}
+	return fmt.Errorf("committee_bits must be set from Electra on and only then, got fork %s", fork)
+}
+
+// RandaoReveal is the epoch whose RANDAO reveal is signed.
+type RandaoReveal struct {
+	Epoch Uint64 `json:"epoch"`
+}
+
+func (r *RandaoReveal) domainType() DomainType { return DomainRandao }
+func (r *RandaoReveal) epoch() uint64          { return uint64(r.Epoch) }
+
+func (r *RandaoReveal) hashTreeRootWith(hh *ssz.Hasher) error {
+	hh.PutUint64(uint64(r.Epoch))
+	return nil
+}
+
+// VoluntaryExit asks for a validator to leave the validator set.
+type VoluntaryExit struct {
+	Epoch          Uint64 `json:"epoch"`
+	ValidatorIndex Uint64 `json:"validator_index"`
+}
+
+func (e *VoluntaryExit) domainType() DomainType { return DomainVoluntaryExit }
+func (e *VoluntaryExit) epoch() uint64          { return uint64(e.Epoch) }
+
+func (e *VoluntaryExit) hashTreeRootWith(hh *ssz.Hasher) error {
+	indx := hh.Index()
+	hh.PutUint64(uint64(e.Epoch))
+	hh.PutUint64(uint64(e.ValidatorIndex))
+	hh.Merkleize(indx)
+	return nil
+}
+
+// SyncCommitteeMessage is a sync committee member's vote for the head block.
+type SyncCommitteeMessage struct {
+	BeaconBlockRoot common.Hash `json:"beacon_block_root"`
+	Slot            Uint64      `json:"slot"`
+}
+
+func (m *SyncCommitteeMessage) domainType() DomainType { return DomainSyncCommittee }
+func (m *SyncCommitteeMessage) epoch() uint64          { return uint64(m.Slot) / slotsPerEpoch }
+
+// hashTreeRootWith hashes the signed object, which is the block root alone.
+func (m *SyncCommitteeMessage) hashTreeRootWith(hh *ssz.Hasher) error {
+	hh.PutBytes(m.BeaconBlockRoot[:])
+	return nil
+}
+
+// SyncAggregatorSelectionData is signed to become a sync subcommittee aggregator.
+type SyncAggregatorSelectionData struct {
+	Slot              Uint64 `json:"slot"`
+	SubcommitteeIndex Uint64 `json:"subcommittee_index"`
+}
+
+func (d *SyncAggregatorSelectionData) domainType() DomainType {
+	return DomainSyncCommitteeSelectionProof
+}
+func (d *SyncAggregatorSelectionData) epoch() uint64 { return uint64(d.Slot) / slotsPerEpoch }
+
+func (d *SyncAggregatorSelectionData) hashTreeRootWith(hh *ssz.Hasher) error {
+	indx := hh.Index()
+	hh.PutUint64(uint64(d.Slot))
+	hh.PutUint64(uint64(d.SubcommitteeIndex))
+	hh.Merkleize(indx)
+	return nil
+}
+
+// SyncCommitteeContribution aggregates the sync committee messages of a subcommittee.
+type SyncCommitteeContribution struct {
+	Slot              Uint64        `json:"slot"`
+	BeaconBlockRoot   common.Hash   `json:"beacon_block_root"`
+	SubcommitteeIndex Uint64        `json:"subcommittee_index"`
+	AggregationBits   hexutil.Bytes `json:"aggregation_bits"`
+	Signature         Signature     `json:"signature"`
+}
+
+func (c *SyncCommitteeContribution) hashTreeRootWith(hh *ssz.Hasher) error {
+	if len(c.AggregationBits) != syncSubcommitteeBitsSize {
+		return fmt.Errorf("aggregation_bits must be %d bytes, got %d", syncSubcommitteeBitsSize, len(c.AggregationBits))
+	}
+	indx := hh.Index()
+	hh.PutUint64(uint64(c.Slot))
+	hh.PutBytes(c.BeaconBlockRoot[:])
+	hh.PutUint64(uint64(c.SubcommitteeIndex))
+	hh.PutBytes(c.AggregationBits)
+	hh.PutBytes(c.Signature[:])
+	hh.Merkleize(indx)
+	return nil
+}
+
+// ContributionAndProof is a sync committee contribution published by an aggregator.
+type ContributionAndProof struct {
+	AggregatorIndex Uint64                    `json:"aggregator_index"`
+	Contribution    SyncCommitteeContribution `json:"contribution"`
+	SelectionProof  Signature                 `json:"selection_proof"`
+}
+
+func (c *ContributionAndProof) domainType() DomainType { return DomainContributionAndProof }
+func (c *ContributionAndProof) epoch() uint64 {
+	return uint64(c.Contribution.Slot) / slotsPerEpoch
+}
+
+func (c *ContributionAndProof) hashTreeRootWith(hh *ssz.Hasher) error {
+	indx := hh.Index()
+	hh.PutUint64(uint64(c.AggregatorIndex))
+	if err := c.Contribution.hashTreeRootWith(hh); err != nil {
+		return err
+	}
+	hh.PutBytes(c.SelectionProof[:])
+	hh.Merkleize(indx)
+	return nil
+}
+
+// DepositMessage is the part of a deposit signed by the deposited key. Amount is in Gwei.
+// GenesisForkVersion is not part of the message but selects its signing domain.
+type DepositMessage struct {
+	Pubkey                PublicKey   `json:"pubkey"`
+	WithdrawalCredentials common.Hash `json:"withdrawal_credentials"`
+	Amount                Uint64      `json:"amount"`
+	GenesisForkVersion    Version     `json:"genesis_fork_version"`
+}
+
+func (d *DepositMessage) hashTreeRootWith(hh *ssz.Hasher) error {
+	indx := hh.Index()
+	hh.PutBytes(d.Pubkey[:])
+	hh.PutBytes(d.WithdrawalCredentials[:])
+	hh.PutUint64(uint64(d.Amount))
+	hh.Merkleize(indx)
+	return nil
+}
+
+// ValidatorRegistration registers a validator's fee recipient with block builders.
+type ValidatorRegistration struct {
+	FeeRecipient common.Address `json:"fee_recipient"`
+	GasLimit     Uint64         `json:"gas_limit"`
+	Timestamp    Uint64         `json:"timestamp"`
+	Pubkey       PublicKey      `json:"pubkey"`
+}
+
+func (r *ValidatorRegistration) hashTreeRootWith(hh *ssz.Hasher) error {
+	indx := hh.Index()
+	hh.PutBytes(r.FeeRecipient[:])
+	hh.PutUint64(uint64(r.GasLimit))
+	hh.PutUint64(uint64(r.Timestamp))
+	hh.PutBytes(r.Pubkey[:])
+	hh.Merkleize(indx)
+	return nil
+}
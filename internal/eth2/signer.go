@@ -0,0 +1,117 @@
+package eth2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrUnsupportedType is returned for a signing request of an unknown type.
+var ErrUnsupportedType = errors.New("unsupported signing type")
+
+// Signing request types, as named by the Web3Signer eth2 API.
+const (
+	TypeBlock                             = "BLOCK"
+	TypeBlockV2                           = "BLOCK_V2"
+	TypeAttestation                       = "ATTESTATION"
+	TypeAggregationSlot                   = "AGGREGATION_SLOT"
+	TypeAggregateAndProof                 = "AGGREGATE_AND_PROOF"
+	TypeAggregateAndProofV2               = "AGGREGATE_AND_PROOF_V2"
+	TypeDeposit                           = "DEPOSIT"
+	TypeRandaoReveal                      = "RANDAO_REVEAL"
+	TypeVoluntaryExit                     = "VOLUNTARY_EXIT"
+	TypeSyncCommitteeMessage              = "SYNC_COMMITTEE_MESSAGE"
+	TypeSyncCommitteeSelectionProof       = "SYNC_COMMITTEE_SELECTION_PROOF"
+	TypeSyncCommitteeContributionAndProof = "SYNC_COMMITTEE_CONTRIBUTION_AND_PROOF"
+	TypeValidatorRegistration             = "VALIDATOR_REGISTRATION"
+)
+
+// SigningRequest describes a consensus-layer message to sign. The Signer computes the
+// signing root from Message and ForkInfo itself; SigningRoot, the root the caller
+// computed, is only compared with it.
+type SigningRequest struct {
+	Message  Message
+	ForkInfo *ForkInfo // required for every message but deposits and validator registrations
+	// SigningRoot is optional; a request whose root differs from the computed one fails.
+	SigningRoot *[32]byte
+}
+
+// Signer signs consensus-layer messages with validator keys, consulting slashing
+// protection before every block and attestation.
+type Signer struct {
+	keys       *KeyManager
+	protection *SlashingProtection
+	// genesisForkVersion is the network's GENESIS_FORK_VERSION, which validator
+	// registrations are signed with; nil refuses them.
+	genesisForkVersion *Version
+}
+
+// NewSigner creates a Signer. It takes ownership of keys and protection and closes them in
+// Close. genesisForkVersion may be nil, in which case validator registrations are refused.
+func NewSigner(keys *KeyManager, protection *SlashingProtection, genesisForkVersion *Version) *Signer {
+	return &Signer{keys: keys, protection: protection, genesisForkVersion: genesisForkVersion}
+}
+
+// PublicKeys returns the public keys of the loaded validator keys.
+func (s *Signer) PublicKeys() []PublicKey {
+	return s.keys.PublicKeys()
+}
+
+// Reload re-reads the validator keystores.
+func (s *Signer) Reload() error {
+	return s.keys.Reload()
+}
+
+// SlashingProtection returns the slashing protection database, e.g. for import and export.
+func (s *Signer) SlashingProtection() *SlashingProtection {
+	return s.protection
+}
+
+// Sign computes the signing root of req.Message and signs it with the key for pub. Blocks
+// and attestations are recorded in the slashing protection database first and refused
+// with ErrSlashable if they conflict with what the validator signed before.
+func (s *Signer) Sign(ctx context.Context, pub PublicKey, req SigningRequest) (Signature, error) {
+	if err := ctx.Err(); err != nil {
+		return Signature{}, err
+	}
+	if !s.keys.HasKey(pub) {
+		return Signature{}, fmt.Errorf("%w: %s", ErrKeyNotFound, pub.Hex())
+	}
+
+	root, err := SigningRoot(req.Message, req.ForkInfo, s.genesisForkVersion)
+	if err != nil {
+		return Signature{}, err
+	}
+	if req.SigningRoot != nil && *req.SigningRoot != root {
+		return Signature{}, fmt.Errorf("%w: signingRoot %s does not match the message, whose signing root is %s",
+			ErrInvalidMessage, hexutil.Encode(req.SigningRoot[:]), hexutil.Encode(root[:]))
+	}
+
+	switch m := req.Message.(type) {
+	case *BeaconBlockHeader:
+		if err := s.protection.CheckAndRecordBlock(pub, req.ForkInfo.GenesisValidatorsRoot, uint64(m.Slot), root); err != nil {
+			return Signature{}, err
+		}
+	case *AttestationData:
+		if err := s.protection.CheckAndRecordAttestation(pub, req.ForkInfo.GenesisValidatorsRoot, uint64(m.Source.Epoch), uint64(m.Target.Epoch), root); err != nil {
+			return Signature{}, err
+		}
+	case *DepositMessage:
+		if m.Pubkey != pub {
+			return Signature{}, fmt.Errorf("%w: deposit is for %s", ErrInvalidMessage, m.Pubkey.Hex())
+		}
+	case *ValidatorRegistration:
+		if m.Pubkey != pub {
+			return Signature{}, fmt.Errorf("%w: validator registration is for %s", ErrInvalidMessage, m.Pubkey.Hex())
+		}
+	}
+	return s.keys.Sign(pub, root)
+}
+
+// Close wipes the validator keys and closes the slashing protection database.
+func (s *Signer) Close() error {
+	s.keys.Close()
+	return s.protection.Close()
+}
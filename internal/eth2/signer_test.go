@@ -0,0 +1,123 @@
+package eth2
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestSigner returns a Signer holding the key of the EIP-2335 test keystore.
+func newTestSigner(t *testing.T, genesisForkVersion *Version) (*Signer, PublicKey) {
+	t.Helper()
+	dir := t.TempDir()
+	data, err := os.ReadFile("testdata/pbkdf2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "keystore.json"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := NewKeyManager(dir, "", eip2335Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	protection, err := OpenSlashingProtection(filepath.Join(t.TempDir(), "slashing.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSigner(keys, protection, genesisForkVersion)
+	t.Cleanup(func() { s.Close() })
+
+	pub, err := ParsePublicKey(eip2335Pubkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, pub
+}
+
+var testForkInfo = &ForkInfo{
+	Fork:                  Fork{PreviousVersion: Version{4}, CurrentVersion: Version{5}, Epoch: 100},
+	GenesisValidatorsRoot: fill(0xaa),
+}
+
+func TestSignerSignsComputedRoot(t *testing.T) {
+	s, pub := newTestSigner(t, nil)
+	msg := &RandaoReveal{Epoch: 150}
+	root, err := SigningRoot(msg, testForkInfo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := s.Sign(t.Context(), pub, SigningRequest{Message: msg, ForkInfo: testForkInfo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verify(t, pub, root[:], sig) {
+		t.Error("signature does not verify against the computed signing root")
+	}
+
+	// A matching client root is accepted, a different one refused.
+	if _, err := s.Sign(t.Context(), pub, SigningRequest{Message: msg, ForkInfo: testForkInfo, SigningRoot: &root}); err != nil {
+		t.Errorf("matching signingRoot: %v", err)
+	}
+	other := [32]byte{1}
+	_, err = s.Sign(t.Context(), pub, SigningRequest{Message: msg, ForkInfo: testForkInfo, SigningRoot: &other})
+	if !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("mismatching signingRoot: got %v, want ErrInvalidMessage", err)
+	}
+}
+
+func TestSignerSlashingProtectionUsesMessage(t *testing.T) {
+	s, pub := newTestSigner(t, nil)
+	sign := func(msg Message) error {
+		_, err := s.Sign(t.Context(), pub, SigningRequest{Message: msg, ForkInfo: testForkInfo})
+		return err
+	}
+
+	block := &BeaconBlockHeader{Slot: 3200, ProposerIndex: 1, BodyRoot: fill(1)}
+	if err := sign(block); err != nil {
+		t.Fatal(err)
+	}
+	if err := sign(block); err != nil {
+		t.Errorf("signing the same block again: %v", err)
+	}
+	if err := sign(&BeaconBlockHeader{Slot: 3200, ProposerIndex: 1, BodyRoot: fill(2)}); !errors.Is(err, ErrSlashable) {
+		t.Errorf("second block at slot 3200: got %v, want ErrSlashable", err)
+	}
+
+	attestation := testAttestationData
+	if err := sign(&attestation); err != nil {
+		t.Fatal(err)
+	}
+	double := testAttestationData
+	double.BeaconBlockRoot = fill(0x12)
+	if err := sign(&double); !errors.Is(err, ErrSlashable) {
+		t.Errorf("double vote: got %v, want ErrSlashable", err)
+	}
+	surround := testAttestationData
+	surround.Source.Epoch--
+	surround.Target.Epoch++
+	if err := sign(&surround); !errors.Is(err, ErrSlashable) {
+		t.Errorf("surround vote: got %v, want ErrSlashable", err)
+	}
+}
+
+func TestSignerChecksMessageKey(t *testing.T) {
+	s, pub := newTestSigner(t, &Version{})
+	tests := []Message{
+		&DepositMessage{Pubkey: testPubkey(1), Amount: 32_000_000_000},
+		&ValidatorRegistration{Pubkey: testPubkey(1), GasLimit: 30_000_000},
+	}
+	for _, msg := range tests {
+		if _, err := s.Sign(t.Context(), pub, SigningRequest{Message: msg}); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("%T for another key: got %v, want ErrInvalidMessage", msg, err)
+		}
+	}
+	if _, err := s.Sign(t.Context(), pub, SigningRequest{Message: &DepositMessage{Pubkey: pub, Amount: 32_000_000_000}}); err != nil {
+		t.Errorf("deposit: %v", err)
+	}
+	if _, err := s.Sign(t.Context(), testPubkey(1), SigningRequest{Message: &RandaoReveal{}}); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("unknown key: got %v, want ErrKeyNotFound", err)
+	}
+}
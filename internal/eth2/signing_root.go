@@ -0,0 +1,129 @@
+package eth2
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ssz "github.com/ferranbt/fastssz"
+)
+
+// slotsPerEpoch is SLOTS_PER_EPOCH of the mainnet preset, used by every public network.
+const slotsPerEpoch = 32
+
+// DomainType separates the signatures of different message types, as in the consensus specs.
+type DomainType [4]byte
+
+// Domain types of the consensus specs and the builder API.
+var (
+	DomainBeaconProposer              = DomainType{0x00, 0x00, 0x00, 0x00}
+	DomainBeaconAttester              = DomainType{0x01, 0x00, 0x00, 0x00}
+	DomainRandao                      = DomainType{0x02, 0x00, 0x00, 0x00}
+	DomainDeposit                     = DomainType{0x03, 0x00, 0x00, 0x00}
+	DomainVoluntaryExit               = DomainType{0x04, 0x00, 0x00, 0x00}
+	DomainSelectionProof              = DomainType{0x05, 0x00, 0x00, 0x00}
+	DomainAggregateAndProof           = DomainType{0x06, 0x00, 0x00, 0x00}
+	DomainSyncCommittee               = DomainType{0x07, 0x00, 0x00, 0x00}
+	DomainSyncCommitteeSelectionProof = DomainType{0x08, 0x00, 0x00, 0x00}
+	DomainContributionAndProof        = DomainType{0x09, 0x00, 0x00, 0x00}
+	DomainApplicationBuilder          = DomainType{0x00, 0x00, 0x00, 0x01}
+)
+
+// Version is a 4-byte fork version, e.g. 0x00000000 for mainnet's genesis fork.
+type Version [4]byte
+
+// MarshalText encodes the version as 0x-prefixed hex.
+func (v Version) MarshalText() ([]byte, error) {
+	return hexutil.Bytes(v[:]).MarshalText()
+}
+
+// UnmarshalText decodes a 0x-prefixed 4-byte hex version.
+func (v *Version) UnmarshalText(input []byte) error {
+	return hexutil.UnmarshalFixedText("Version", input, v[:])
+}
+
+// Fork is the fork of the beacon state a message is signed for.
+type Fork struct {
+	PreviousVersion Version `json:"previous_version"`
+	CurrentVersion  Version `json:"current_version"`
+	Epoch           Uint64  `json:"epoch"` // first epoch of CurrentVersion
+}
+
+// ForkInfo identifies the network and fork a message is signed for.
+type ForkInfo struct {
+	Fork                  Fork        `json:"fork"`
+	GenesisValidatorsRoot common.Hash `json:"genesis_validators_root"`
+}
+
+// domain returns the signing domain of domainType at epoch, as get_domain does.
+func (f *ForkInfo) domain(domainType DomainType, epoch uint64) [32]byte {
+	version := f.Fork.CurrentVersion
+	if epoch < uint64(f.Fork.Epoch) {
+		version = f.Fork.PreviousVersion
+	}
+	return computeDomain(domainType, version, f.GenesisValidatorsRoot)
+}
+
+// computeDomain is compute_domain of the consensus specs.
+func computeDomain(domainType DomainType, version Version, genesisValidatorsRoot [32]byte) [32]byte {
+	// hash_tree_root(ForkData(current_version, genesis_validators_root)): two chunks.
+	var forkData [64]byte
+	copy(forkData[:4], version[:])
+	copy(forkData[32:], genesisValidatorsRoot[:])
+	forkDataRoot := sha256.Sum256(forkData[:])
+
+	var domain [32]byte
+	copy(domain[:4], domainType[:])
+	copy(domain[4:], forkDataRoot[:28])
+	return domain
+}
+
+// computeSigningRoot is compute_signing_root of the consensus specs: the hash tree root of
+// SigningData(object_root, domain).
+func computeSigningRoot(objectRoot, domain [32]byte) [32]byte {
+	var signingData [64]byte
+	copy(signingData[:32], objectRoot[:])
+	copy(signingData[32:], domain[:])
+	return sha256.Sum256(signingData[:])
+}
+
+// hashTreeRoot returns the SSZ hash tree root of msg.
+func hashTreeRoot(msg Message) ([32]byte, error) {
+	hh := ssz.NewHasher()
+	if err := msg.hashTreeRootWith(hh); err != nil {
+		return [32]byte{}, err
+	}
+	return hh.HashRoot()
+}
+
+// SigningRoot computes the root a validator signs for msg. fork is required for every
+// message but deposits and validator registrations; genesisForkVersion, the network's
+// GENESIS_FORK_VERSION, only for validator registrations.
+func SigningRoot(msg Message, fork *ForkInfo, genesisForkVersion *Version) ([32]byte, error) {
+	var domain [32]byte
+	switch m := msg.(type) {
+	case *DepositMessage:
+		// Deposits are valid across forks, so they are signed in the genesis fork without
+		// a genesis validators root.
+		domain = computeDomain(DomainDeposit, m.GenesisForkVersion, [32]byte{})
+	case *ValidatorRegistration:
+		if genesisForkVersion == nil {
+			return [32]byte{}, fmt.Errorf("%w: signing validator registrations requires the network's genesis fork version", ErrInvalidMessage)
+		}
+		domain = computeDomain(DomainApplicationBuilder, *genesisForkVersion, [32]byte{})
+	case forkMessage:
+		if fork == nil {
+			return [32]byte{}, fmt.Errorf("%w: fork_info is required", ErrInvalidMessage)
+		}
+		domain = fork.domain(m.domainType(), m.epoch())
+	default:
+		return [32]byte{}, fmt.Errorf("%w: cannot compute the signing root of %T", ErrInvalidMessage, msg)
+	}
+
+	objectRoot, err := hashTreeRoot(msg)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+	return computeSigningRoot(objectRoot, domain), nil
+}
@@ -0,0 +1,304 @@
+package eth2
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// The reference SSZ merkleization below follows the consensus specs literally, to check
+// the hashing of the messages, which is written against fastssz's Hasher.
+
+type chunk = [32]byte
+
+func packBytes(b []byte) []chunk {
+	chunks := make([]chunk, (len(b)+31)/32)
+	for i := range chunks {
+		copy(chunks[i][:], b[32*i:])
+	}
+	return chunks
+}
+
+func uintChunk(n uint64) chunk {
+	var c chunk
+	binary.LittleEndian.PutUint64(c[:], n)
+	return c
+}
+
+func rootChunk(b []byte) chunk {
+	return merkleize(packBytes(b), 0)
+}
+
+// merkleize is merkleize(chunks, limit) of the SSZ spec; limit 0 means len(chunks).
+func merkleize(chunks []chunk, limit int) chunk {
+	size := max(limit, len(chunks), 1)
+	width := 1
+	for width < size {
+		width *= 2
+	}
+	layer := make([]chunk, width)
+	copy(layer, chunks)
+	for len(layer) > 1 {
+		next := make([]chunk, len(layer)/2)
+		for i := range next {
+			next[i] = sha256.Sum256(append(layer[2*i][:], layer[2*i+1][:]...))
+		}
+		layer = next
+	}
+	return layer[0]
+}
+
+func mixInLength(root chunk, n uint64) chunk {
+	length := uintChunk(n)
+	return sha256.Sum256(append(root[:], length[:]...))
+}
+
+// bitlistRoot hashes an SSZ-encoded Bitlist[limit], stripping its delimiter bit.
+func bitlistRoot(t *testing.T, encoded []byte, limit int) chunk {
+	t.Helper()
+	last := encoded[len(encoded)-1]
+	if last == 0 {
+		t.Fatal("bitlist without delimiter")
+	}
+	delimiter := 7
+	for last>>delimiter == 0 {
+		delimiter--
+	}
+	bits := bytes.Clone(encoded)
+	bits[len(bits)-1] &^= 1 << delimiter
+	length := uint64(8*(len(encoded)-1) + delimiter)
+	bits = bits[:(length+7)/8]
+	return mixInLength(merkleize(packBytes(bits), (limit+255)/256), length)
+}
+
+func checkpointRoot(c Checkpoint) chunk {
+	return merkleize([]chunk{uintChunk(uint64(c.Epoch)), c.Root}, 0)
+}
+
+func attestationDataRoot(d AttestationData) chunk {
+	return merkleize([]chunk{
+		uintChunk(uint64(d.Slot)), uintChunk(uint64(d.Index)), d.BeaconBlockRoot,
+		checkpointRoot(d.Source), checkpointRoot(d.Target),
+	}, 0)
+}
+
+func mustHashTreeRoot(t *testing.T, msg Message) chunk {
+	t.Helper()
+	root, err := hashTreeRoot(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func fill(b byte) common.Hash {
+	return common.BytesToHash(bytes.Repeat([]byte{b}, 32))
+}
+
+func testSignature(b byte) Signature {
+	var s Signature
+	for i := range s {
+		s[i] = b + byte(i)
+	}
+	return s
+}
+
+func testPubkey(b byte) PublicKey {
+	var p PublicKey
+	for i := range p {
+		p[i] = b + byte(i)
+	}
+	return p
+}
+
+var testAttestationData = AttestationData{
+	Slot:            4_000_123,
+	Index:           17,
+	BeaconBlockRoot: fill(0x11),
+	Source:          Checkpoint{Epoch: 125_002, Root: fill(0x22)},
+	Target:          Checkpoint{Epoch: 125_003, Root: fill(0x33)},
+}
+
+func TestHashTreeRoot(t *testing.T) {
+	header := &BeaconBlockHeader{Slot: 9_876_543, ProposerIndex: 123_456, ParentRoot: fill(1), StateRoot: fill(2), BodyRoot: fill(3)}
+	attestation := &Attestation{AggregationBits: hexutil.Bytes{0xff, 0x0d}, Data: testAttestationData, Signature: testSignature(0x40)}
+	electra := &Attestation{
+		AggregationBits: bytes.Repeat([]byte{0xa5}, 300),
+		Data:            testAttestationData,
+		Signature:       testSignature(0x50),
+		CommitteeBits:   hexutil.Bytes{0x03, 0, 0, 0, 0, 0, 0, 0},
+	}
+	electra.AggregationBits[299] = 0x01
+	contribution := SyncCommitteeContribution{
+		Slot: 77, BeaconBlockRoot: fill(4), SubcommitteeIndex: 2,
+		AggregationBits: bytes.Repeat([]byte{0xf0}, 16), Signature: testSignature(0x60),
+	}
+	feeRecipient := common.HexToAddress("0x1234567890abcdef1234567890abcdef12345678")
+	proofs := []Signature{testSignature(0x70), testSignature(0x71), testSignature(0x72)}
+	pubkeys := []PublicKey{testPubkey(0x80), testPubkey(0x90)}
+
+	tests := []struct {
+		name string
+		msg  Message
+		want chunk
+	}{
+		{"BeaconBlockHeader", header, merkleize([]chunk{
+			uintChunk(9_876_543), uintChunk(123_456), fill(1), fill(2), fill(3),
+		}, 0)},
+		{"AttestationData", &testAttestationData, attestationDataRoot(testAttestationData)},
+		{"AggregationSlot", &AggregationSlot{Slot: 42}, uintChunk(42)},
+		{"AggregateAndProof", &AggregateAndProof{AggregatorIndex: 9, Aggregate: *attestation, SelectionProof: proofs[0]},
+			merkleize([]chunk{
+				uintChunk(9),
+				merkleize([]chunk{
+					bitlistRoot(t, attestation.AggregationBits, maxValidatorsPerCommittee),
+					attestationDataRoot(testAttestationData),
+					rootChunk(attestation.Signature[:]),
+				}, 0),
+				rootChunk(proofs[0][:]),
+			}, 0)},
+		{"ElectraAggregateAndProof", &AggregateAndProof{AggregatorIndex: 10, Aggregate: *electra, SelectionProof: proofs[1]},
+			merkleize([]chunk{
+				uintChunk(10),
+				merkleize([]chunk{
+					bitlistRoot(t, electra.AggregationBits, maxValidatorsPerSlot),
+					attestationDataRoot(testAttestationData),
+					rootChunk(electra.Signature[:]),
+					rootChunk(electra.CommitteeBits),
+				}, 0),
+				rootChunk(proofs[1][:]),
+			}, 0)},
+		{"RandaoReveal", &RandaoReveal{Epoch: 3}, uintChunk(3)},
+		{"VoluntaryExit", &VoluntaryExit{Epoch: 5, ValidatorIndex: 6}, merkleize([]chunk{uintChunk(5), uintChunk(6)}, 0)},
+		{"SyncCommitteeMessage", &SyncCommitteeMessage{BeaconBlockRoot: fill(7), Slot: 8}, fill(7)},
+		{"SyncAggregatorSelectionData", &SyncAggregatorSelectionData{Slot: 9, SubcommitteeIndex: 3},
+			merkleize([]chunk{uintChunk(9), uintChunk(3)}, 0)},
+		{"ContributionAndProof", &ContributionAndProof{AggregatorIndex: 11, Contribution: contribution, SelectionProof: proofs[2]},
+			merkleize([]chunk{
+				uintChunk(11),
+				merkleize([]chunk{
+					uintChunk(77), fill(4), uintChunk(2), rootChunk(contribution.AggregationBits), rootChunk(contribution.Signature[:]),
+				}, 0),
+				rootChunk(proofs[2][:]),
+			}, 0)},
+		{"DepositMessage", &DepositMessage{Pubkey: pubkeys[0], WithdrawalCredentials: fill(9), Amount: 32_000_000_000},
+			merkleize([]chunk{rootChunk(pubkeys[0][:]), fill(9), uintChunk(32_000_000_000)}, 0)},
+		{"ValidatorRegistration", &ValidatorRegistration{FeeRecipient: feeRecipient, GasLimit: 30_000_000, Timestamp: 1_700_000_000, Pubkey: pubkeys[1]},
+			merkleize([]chunk{
+				rootChunk(feeRecipient[:]), uintChunk(30_000_000), uintChunk(1_700_000_000), rootChunk(pubkeys[1][:]),
+			}, 0)},
+	}
+	for _, tt := range tests {
+		if got := mustHashTreeRoot(t, tt.msg); got != tt.want {
+			t.Errorf("%s: hash tree root %x, want %x", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHashTreeRootRejectsMalformedBits(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+	}{
+		{"empty bitlist", &Attestation{AggregationBits: hexutil.Bytes{}}},
+		{"bitlist without delimiter", &Attestation{AggregationBits: hexutil.Bytes{0x01, 0x00}}},
+		{"bitlist over the limit", &Attestation{AggregationBits: append(make(hexutil.Bytes, 257), 0x01)}},
+		{"short committee bits", &Attestation{AggregationBits: hexutil.Bytes{0x01}, CommitteeBits: hexutil.Bytes{0x01}}},
+		{"short contribution bits", &ContributionAndProof{Contribution: SyncCommitteeContribution{AggregationBits: hexutil.Bytes{0xff}}}},
+	}
+	for _, tt := range tests {
+		if _, err := hashTreeRoot(tt.msg); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
+
+func TestComputeDomain(t *testing.T) {
+	// Deposits and builder registrations on mainnet: genesis fork version 0x00000000 and
+	// no genesis validators root.
+	tests := []struct {
+		domainType DomainType
+		want       string
+	}{
+		{DomainDeposit, "0x03000000f5a5fd42d16a20302798ef6ed309979b43003d2320d9f0e8ea9831a9"},
+		{DomainApplicationBuilder, "0x00000001f5a5fd42d16a20302798ef6ed309979b43003d2320d9f0e8ea9831a9"},
+	}
+	for _, tt := range tests {
+		domain := computeDomain(tt.domainType, Version{}, [32]byte{})
+		if got := hexutil.Encode(domain[:]); got != tt.want {
+			t.Errorf("domain %x = %s, want %s", tt.domainType, got, tt.want)
+		}
+	}
+}
+
+func TestSigningRootUsesForkOfEpoch(t *testing.T) {
+	fork := &ForkInfo{
+		Fork: Fork{
+			PreviousVersion: Version{0x04, 0, 0, 0},
+			CurrentVersion:  Version{0x05, 0, 0, 0},
+			Epoch:           100,
+		},
+		GenesisValidatorsRoot: fill(0xaa),
+	}
+	for _, tt := range []struct {
+		epoch   uint64
+		version Version
+	}{{99, fork.Fork.PreviousVersion}, {100, fork.Fork.CurrentVersion}} {
+		msg := &RandaoReveal{Epoch: Uint64(tt.epoch)}
+		got, err := SigningRoot(msg, fork, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		forkDataRoot := merkleize([]chunk{rootChunk(tt.version[:]), fork.GenesisValidatorsRoot}, 0)
+		var domain chunk
+		copy(domain[:4], DomainRandao[:])
+		copy(domain[4:], forkDataRoot[:28])
+		want := merkleize([]chunk{uintChunk(tt.epoch), domain}, 0)
+		if got != want {
+			t.Errorf("epoch %d: signing root %x, want %x", tt.epoch, got, want)
+		}
+	}
+}
+
+func TestSigningRootRequirements(t *testing.T) {
+	if _, err := SigningRoot(&RandaoReveal{Epoch: 1}, nil, nil); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("message without fork_info: got %v, want ErrInvalidMessage", err)
+	}
+	registration := &ValidatorRegistration{Pubkey: testPubkey(1)}
+	if _, err := SigningRoot(registration, nil, nil); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("registration without genesis fork version: got %v, want ErrInvalidMessage", err)
+	}
+	if _, err := SigningRoot(registration, nil, &Version{}); err != nil {
+		t.Errorf("registration: %v", err)
+	}
+	// Deposits carry their own fork version.
+	if _, err := SigningRoot(&DepositMessage{Pubkey: testPubkey(1)}, nil, nil); err != nil {
+		t.Errorf("deposit: %v", err)
+	}
+}
+
+func TestMessageJSON(t *testing.T) {
+	// As sent by validator clients to Web3Signer.
+	const input = `{
+		"slot": "4000123", "index": "17",
+		"beacon_block_root": "0x1111111111111111111111111111111111111111111111111111111111111111",
+		"source": {"epoch": "125002", "root": "0x2222222222222222222222222222222222222222222222222222222222222222"},
+		"target": {"epoch": "125003", "root": "0x3333333333333333333333333333333333333333333333333333333333333333"}
+	}`
+	var data AttestationData
+	if err := json.Unmarshal([]byte(input), &data); err != nil {
+		t.Fatal(err)
+	}
+	if data != testAttestationData {
+		t.Errorf("decoded %+v, want %+v", data, testAttestationData)
+	}
+	if err := json.Unmarshal([]byte(`{"slot": 1}`), &data); err == nil {
+		t.Error("numeric slot accepted; the API encodes integers as strings")
+	}
+}
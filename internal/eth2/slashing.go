@@ -0,0 +1,180 @@
+package eth2
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrSlashable is returned when signing could get a validator slashed.
+var ErrSlashable = errors.New("refused by slashing protection")
+
+// openTimeout bounds how long OpenSlashingProtection waits for the file lock held by another process.
+const openTimeout = 2 * time.Second
+
+var (
+	metaBucket       = []byte("meta")
+	validatorsBucket = []byte("validators")
+	blocksBucket     = []byte("blocks")       // per validator: slot -> signing root
+	attestsBucket    = []byte("attestations") // per validator: target epoch -> source epoch || signing root
+
+	genesisValidatorsRootKey = []byte("genesis_validators_root")
+	maxSourceKey             = []byte("max_source_epoch")
+)
+
+// SlashingProtection records what every validator signed in an embedded bbolt database and
+// refuses messages that could be slashable, following the minimal rules of EIP-3076:
+//
+//   - a block is signed only for a slot above every slot signed before;
+//   - an attestation is signed only if its source epoch is not below any source signed before
+//     and its target epoch is above every target signed before.
+//
+// Re-signing exactly the same block or attestation (same slot or target and same signing
+// root) is allowed, so a validator client can retry after a lost response.
+type SlashingProtection struct {
+	db *bolt.DB
+}
+
+// OpenSlashingProtection opens (creating if needed) the slashing protection database at path.
+func OpenSlashingProtection(path string) (*SlashingProtection, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create slashing protection directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		if errors.Is(err, bolt.ErrTimeout) {
+			return nil, fmt.Errorf("slashing protection database %s is locked by another process (is the signer running?)", path)
+		}
+		return nil, fmt.Errorf("failed to open slashing protection database: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(metaBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(validatorsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize slashing protection database: %w", err)
+	}
+	return &SlashingProtection{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (sp *SlashingProtection) Close() error {
+	return sp.db.Close()
+}
+
+// CheckAndRecordBlock records a block proposal at slot, or returns ErrSlashable if the
+// validator signed a different block at this or a later slot.
+func (sp *SlashingProtection) CheckAndRecordBlock(pub PublicKey, genesisValidatorsRoot [32]byte, slot uint64, signingRoot [32]byte) error {
+	return sp.db.Update(func(tx *bolt.Tx) error {
+		if err := checkGenesis(tx, genesisValidatorsRoot); err != nil {
+			return err
+		}
+		v, err := validatorBucket(tx, pub)
+		if err != nil {
+			return err
+		}
+		blocks := v.Bucket(blocksBucket)
+
+		if prev := blocks.Get(uint64Key(slot)); prev != nil {
+			if isKnownRoot(prev, signingRoot) {
+				return nil
+			}
+			return fmt.Errorf("%w: %s already signed a different block at slot %d", ErrSlashable, pub.Hex(), slot)
+		}
+		if k, _ := blocks.Cursor().Last(); k != nil && slot <= binary.BigEndian.Uint64(k) {
+			return fmt.Errorf("%w: %s already signed a block at slot %d, refusing slot %d", ErrSlashable, pub.Hex(), binary.BigEndian.Uint64(k), slot)
+		}
+		return blocks.Put(uint64Key(slot), signingRoot[:])
+	})
+}
+
+// CheckAndRecordAttestation records an attestation from source to target epoch, or returns
+// ErrSlashable if it could be a double vote or surround the validator's earlier votes.
+func (sp *SlashingProtection) CheckAndRecordAttestation(pub PublicKey, genesisValidatorsRoot [32]byte, source, target uint64, signingRoot [32]byte) error {
+	if source > target {
+		return fmt.Errorf("%w: source epoch %d is after target epoch %d", ErrSlashable, source, target)
+	}
+	return sp.db.Update(func(tx *bolt.Tx) error {
+		if err := checkGenesis(tx, genesisValidatorsRoot); err != nil {
+			return err
+		}
+		v, err := validatorBucket(tx, pub)
+		if err != nil {
+			return err
+		}
+		attests := v.Bucket(attestsBucket)
+
+		if prev := attests.Get(uint64Key(target)); prev != nil {
+			if binary.BigEndian.Uint64(prev[:8]) == source && isKnownRoot(prev[8:], signingRoot) {
+				return nil
+			}
+			return fmt.Errorf("%w: %s already attested to target epoch %d", ErrSlashable, pub.Hex(), target)
+		}
+		if k, _ := attests.Cursor().Last(); k != nil && target <= binary.BigEndian.Uint64(k) {
+			return fmt.Errorf("%w: %s already attested to target epoch %d, refusing target %d", ErrSlashable, pub.Hex(), binary.BigEndian.Uint64(k), target)
+		}
+		if maxSource := v.Get(maxSourceKey); maxSource != nil && source < binary.BigEndian.Uint64(maxSource) {
+			return fmt.Errorf("%w: %s already attested from source epoch %d, refusing source %d", ErrSlashable, pub.Hex(), binary.BigEndian.Uint64(maxSource), source)
+		}
+		return recordAttestation(v, source, target, signingRoot[:])
+	})
+}
+
+// checkGenesis pins the database to one network: the first signature or import stores
+// the genesis validators root and later requests for another network are refused.
+func checkGenesis(tx *bolt.Tx, root [32]byte) error {
+	meta := tx.Bucket(metaBucket)
+	stored := meta.Get(genesisValidatorsRootKey)
+	if stored == nil {
+		return meta.Put(genesisValidatorsRootKey, root[:])
+	}
+	if [32]byte(stored) != root {
+		return fmt.Errorf("%w: genesis validators root %x does not match the database's %x", ErrSlashable, root, stored)
+	}
+	return nil
+}
+
+// validatorBucket returns the bucket of pub, creating it if needed.
+func validatorBucket(tx *bolt.Tx, pub PublicKey) (*bolt.Bucket, error) {
+	v, err := tx.Bucket(validatorsBucket).CreateBucketIfNotExists(pub[:])
+	if err != nil {
+		return nil, err
+	}
+	if _, err := v.CreateBucketIfNotExists(blocksBucket); err != nil {
+		return nil, err
+	}
+	if _, err := v.CreateBucketIfNotExists(attestsBucket); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func recordAttestation(v *bolt.Bucket, source, target uint64, signingRoot []byte) error {
+	value := append(uint64Key(source), signingRoot...)
+	if err := v.Bucket(attestsBucket).Put(uint64Key(target), value); err != nil {
+		return err
+	}
+	if maxSource := v.Get(maxSourceKey); maxSource == nil || source > binary.BigEndian.Uint64(maxSource) {
+		return v.Put(maxSourceKey, uint64Key(source))
+	}
+	return nil
+}
+
+// isKnownRoot reports whether a recorded signing root equals root. Imported records may
+// lack a signing root and are stored as zero, which never matches.
+func isKnownRoot(recorded []byte, root [32]byte) bool {
+	return [32]byte(recorded) == root && root != [32]byte{}
+}
+
+func uint64Key(n uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, n)
+}
@@ -0,0 +1,34 @@
+// Package ethutil holds the parsing of Ethereum values shared by the HTTP and gRPC APIs.
+package ethutil
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// ErrInvalidAddress is returned by ParseAddress for input that is not a 0x-prefixed
+	// 20-byte hex address.
+	ErrInvalidAddress = errors.New("must be a 0x-prefixed 20-byte hex address")
+	// ErrInvalidChecksum is returned by ParseAddress for a mixed-case address whose EIP-55
+	// checksum does not match.
+	ErrInvalidChecksum = errors.New("mixed-case address has an invalid EIP-55 checksum")
+)
+
+// ParseAddress parses a 0x-prefixed hex address. Unlike common.HexToAddress it rejects
+// malformed input, and a mixed-case address must carry a valid EIP-55 checksum, so that
+// a mistyped address is caught instead of signed for. All-lowercase and all-uppercase
+// addresses carry no checksum and are accepted.
+func ParseAddress(s string) (common.Address, error) {
+	digits, ok := strings.CutPrefix(s, "0x")
+	if !ok || !common.IsHexAddress(s) {
+		return common.Address{}, ErrInvalidAddress
+	}
+	addr := common.HexToAddress(s)
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && addr.Hex() != s {
+		return common.Address{}, ErrInvalidChecksum
+	}
+	return addr, nil
+}
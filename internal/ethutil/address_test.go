@@ -0,0 +1,39 @@
+package ethutil
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestParseAddress(t *testing.T) {
+	// EIP-55 test vector.
+	const checksummed = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	want := common.HexToAddress(checksummed)
+	tests := []struct {
+		in  string
+		err error
+	}{
+		{checksummed, nil},
+		{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", nil},
+		{"0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", nil},
+		{"0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed", ErrInvalidChecksum},
+		{"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", ErrInvalidAddress},
+		{"0X5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", ErrInvalidAddress},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", ErrInvalidAddress},
+		{"0x005aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", ErrInvalidAddress},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAzz", ErrInvalidAddress},
+		{"", ErrInvalidAddress},
+	}
+	for _, tt := range tests {
+		got, err := ParseAddress(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseAddress(%q): got error %v, want %v", tt.in, err, tt.err)
+			continue
+		}
+		if err == nil && got != want {
+			t.Errorf("ParseAddress(%q) = %s, want %s", tt.in, got.Hex(), want.Hex())
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/xueqianLu/ethsigner/internal/middleware"
+	"github.com/xueqianLu/ethsigner/pkg/signerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// AuthInterceptor authenticates unary calls like middleware.AuthMiddleware authenticates
+// HTTP requests: the API key, timestamp and HMAC signature come from the call metadata,
+// and the signature covers the timestamp followed by the method name and the deterministic
+// protobuf encoding of the request (signerpb.SigningPayload).
+func AuthInterceptor(apiKey, apiSecret string) grpc.UnaryServerInterceptor {
+	auth := middleware.NewAuthMiddleware(apiKey, apiSecret)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return nil, status.Errorf(codes.Internal, "unexpected request type %T", req)
+		}
+		payload, err := signerpb.SigningPayload(info.FullMethod, msg)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to encode request: %v", err)
+		}
+
+		err = auth.Verify(first(md, signerpb.MetadataAPIKey), first(md, signerpb.MetadataTimestamp), first(md, signerpb.MetadataSignature), payload)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return handler(ctx, req)
+	}
+}
+
+// first returns the first value of key in md, or "".
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,255 @@
+// Package grpcapi serves the gRPC API defined in pkg/signerpb on top of signer.Signer.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/xueqianLu/ethsigner/internal/ethutil"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/store"
+	"github.com/xueqianLu/ethsigner/pkg/signerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NewServer creates a gRPC server exposing s. Every call is authenticated with apiKey and
+// apiSecret; see AuthInterceptor.
+func NewServer(s *signer.Signer, apiKey, apiSecret string) *grpc.Server {
+	srv := grpc.NewServer(grpc.UnaryInterceptor(AuthInterceptor(apiKey, apiSecret)))
+	signerpb.RegisterSignerServer(srv, &service{signer: s})
+	return srv
+}
+
+// service implements signerpb.SignerServer. It applies the same checks as the HTTP handlers.
+type service struct {
+	signerpb.UnimplementedSignerServer
+	signer *signer.Signer
+}
+
+// ListAccounts implements signerpb.SignerServer.
+func (svc *service) ListAccounts(ctx context.Context, req *signerpb.ListAccountsRequest) (*signerpb.ListAccountsResponse, error) {
+	filter := signer.AccountFilter{
+		States: req.States,
+		Tags:   req.Tags,
+		Owner:  req.Owner,
+	}
+	for _, state := range filter.States {
+		switch strings.ToLower(state) {
+		case "all":
+			filter.States = []string{store.StateActive, store.StateDisabled, store.StateArchived}
+		case store.StateActive, store.StateDisabled, store.StateArchived:
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "invalid state: %s", state)
+		}
+	}
+
+	accounts, err := svc.signer.ListAccounts(ctx, filter)
+	if err != nil {
+		return nil, statusError("failed to list accounts", err)
+	}
+	resp := &signerpb.ListAccountsResponse{Accounts: make([]*signerpb.Account, 0, len(accounts))}
+	for _, acc := range accounts {
+		account := &signerpb.Account{
+			Address: acc.Address.Hex(),
+			State:   acc.State,
+			Metadata: &signerpb.AccountMetadata{
+				Label:   acc.Label,
+				Owner:   acc.Owner,
+				Purpose: acc.Purpose,
+				Tags:    acc.Tags,
+			},
+			Backend: acc.Backend,
+			KeyRef:  acc.KeyRef,
+		}
+		if !acc.CreatedAt.IsZero() {
+			account.CreatedAt = timestamppb.New(acc.CreatedAt)
+		}
+		if !acc.UpdatedAt.IsZero() {
+			account.UpdatedAt = timestamppb.New(acc.UpdatedAt)
+		}
+		resp.Accounts = append(resp.Accounts, account)
+	}
+	return resp, nil
+}
+
+// CreateAccount implements signerpb.SignerServer.
+func (svc *service) CreateAccount(ctx context.Context, req *signerpb.CreateAccountRequest) (*signerpb.CreateAccountResponse, error) {
+	var meta store.Metadata
+	if m := req.GetMetadata(); m != nil {
+		meta = store.Metadata{Label: m.Label, Owner: m.Owner, Purpose: m.Purpose, Tags: m.Tags}
+	}
+	opts := signer.CreateKeyOptions{Password: req.Password, Backend: req.Backend, Name: req.KeyName}
+	address, err := svc.signer.CreateKey(ctx, opts, meta)
+	if err != nil {
+		return nil, statusError("failed to create new account", err)
+	}
+	return &signerpb.CreateAccountResponse{Address: address.Hex()}, nil
+}
+
+// SignTransaction implements signerpb.SignerServer.
+func (svc *service) SignTransaction(ctx context.Context, req *signerpb.SignTransactionRequest) (*signerpb.SignTransactionResponse, error) {
+	if req.ChainId == "" {
+		return nil, status.Error(codes.InvalidArgument, "chain_id is required")
+	}
+	chainID, ok := new(big.Int).SetString(req.ChainId, 10)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid chain_id")
+	}
+	value, err := parseAmount("value", req.Value)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := parseAmount("gas_price", req.GasPrice)
+	if err != nil {
+		return nil, err
+	}
+	gasFeeCap, err := parseAmount("gas_fee_cap", req.GasFeeCap)
+	if err != nil {
+		return nil, err
+	}
+	gasTipCap, err := parseAmount("gas_tip_cap", req.GasTipCap)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := parseAddress("from", req.From)
+	if err != nil {
+		return nil, err
+	}
+	var to *common.Address
+	if req.To != "" {
+		addr, err := parseAddress("to", req.To)
+		if err != nil {
+			return nil, err
+		}
+		to = &addr
+	}
+
+	var tx *types.Transaction
+	if gasFeeCap != nil && gasTipCap != nil {
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     req.Nonce,
+			GasFeeCap: gasFeeCap,
+			GasTipCap: gasTipCap,
+			Gas:       req.GasLimit,
+			To:        to,
+			Value:     value,
+			Data:      req.Data,
+		})
+	} else {
+		tx = types.NewTx(&types.LegacyTx{
+			Nonce:    req.Nonce,
+			GasPrice: gasPrice,
+			Gas:      req.GasLimit,
+			To:       to,
+			Value:    value,
+			Data:     req.Data,
+		})
+	}
+
+	signedTx, err := svc.signer.SignTx(ctx, from, tx, chainID)
+	if err != nil {
+		return nil, statusError("failed to sign transaction", err)
+	}
+	rawTx, err := signedTx.MarshalBinary()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal signed transaction: %v", err)
+	}
+	return &signerpb.SignTransactionResponse{RawTx: rawTx, Hash: signedTx.Hash().Hex()}, nil
+}
+
+// SignMessage implements signerpb.SignerServer.
+func (svc *service) SignMessage(ctx context.Context, req *signerpb.SignMessageRequest) (*signerpb.SignMessageResponse, error) {
+	from, err := parseAddress("from", req.From)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := svc.signer.SignMessage(ctx, from, req.Message)
+	if err != nil {
+		return nil, statusError("failed to sign message", err)
+	}
+	return &signerpb.SignMessageResponse{Signature: signature}, nil
+}
+
+// SignTypedData implements signerpb.SignerServer.
+func (svc *service) SignTypedData(ctx context.Context, req *signerpb.SignTypedDataRequest) (*signerpb.SignTypedDataResponse, error) {
+	from, err := parseAddress("from", req.From)
+	if err != nil {
+		return nil, err
+	}
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal([]byte(req.TypedData), &typedData); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid typed_data: %v", err)
+	}
+	signature, hash, err := svc.signer.SignTypedData(ctx, from, typedData)
+	if err != nil {
+		return nil, statusError("failed to sign typed data", err)
+	}
+	return &signerpb.SignTypedDataResponse{Signature: signature, Hash: hash}, nil
+}
+
+// parseAddress parses a 0x-prefixed hex address with ethutil.ParseAddress, as the HTTP
+// handlers do, and reports malformed input as InvalidArgument.
+func parseAddress(field, s string) (common.Address, error) {
+	addr, err := ethutil.ParseAddress(s)
+	if err != nil {
+		return common.Address{}, status.Errorf(codes.InvalidArgument, "invalid %s: %v", field, err)
+	}
+	return addr, nil
+}
+
+// parseAmount parses an optional decimal amount; empty yields nil. Unlike the /v1 HTTP
+// routes, which take 0x-prefixed hex quantities as JSON-RPC does, the gRPC API takes
+// decimal strings, so a hex quantity is rejected with a hint rather than misread.
+func parseAmount(field, s string) (*big.Int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s: must be a decimal string, not hex", field)
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok || n.Sign() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s", field)
+	}
+	return n, nil
+}
+
+// statusError converts a signer error into a gRPC status, with codes matching the HTTP
+// status codes of the handlers.
+func statusError(msg string, err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, signer.ErrAccountNotFound):
+		code = codes.NotFound
+	case errors.Is(err, signer.ErrAccountExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, signer.ErrAccountArchived):
+		code = codes.FailedPrecondition
+	case errors.Is(err, signer.ErrAccountDisabled):
+		code = codes.PermissionDenied
+	case errors.Is(err, signer.ErrAccountLocked):
+		code = codes.FailedPrecondition
+	case errors.Is(err, signer.ErrWrongPassword), errors.Is(err, signer.ErrPasswordRequired),
+		errors.Is(err, signer.ErrUnknownBackend), errors.Is(err, signer.ErrInvalidKeyName),
+		errors.Is(err, signer.ErrInvalidTypedData):
+		code = codes.InvalidArgument
+	case errors.Is(err, signer.ErrNotSupported):
+		code = codes.Unimplemented
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	}
+	return status.Errorf(code, "%s: %v", msg, err)
+}
@@ -0,0 +1,196 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/signer/signertest"
+	"github.com/xueqianLu/ethsigner/pkg/signerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	testAPIKey    = "key"
+	testAPISecret = "secret"
+)
+
+// startServer serves a signer over km on an in-memory connection and returns a connection
+// to it with the given client options.
+func startServer(t *testing.T, km signer.KeyManager, opts ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+	s := signertest.NewSigner(t, km)
+
+	lis := bufconn.Listen(1 << 20)
+	srv := NewServer(s, testAPIKey, testAPISecret)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	opts = append(opts,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }))
+	conn, err := grpc.NewClient("passthrough:///bufconn", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// signedContext returns ctx with the credentials of a call of method with req.
+func signedContext(t *testing.T, ctx context.Context, method string, req proto.Message) context.Context {
+	t.Helper()
+	payload, err := signerpb.SigningPayload(method, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testAPISecret))
+	mac.Write([]byte(timestamp))
+	mac.Write(payload)
+	return metadata.AppendToOutgoingContext(ctx,
+		signerpb.MetadataAPIKey, testAPIKey,
+		signerpb.MetadataTimestamp, timestamp,
+		signerpb.MetadataSignature, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestAuthCoversMethod(t *testing.T) {
+	km := signertest.NewKeyManager(t, 1)
+	client := signerpb.NewSignerClient(startServer(t, km))
+
+	// ListAccountsRequest and CreateAccountRequest encode to the same empty payload.
+	ctx := signedContext(t, t.Context(), signerpb.Signer_ListAccounts_FullMethodName, &signerpb.ListAccountsRequest{})
+	if _, err := client.ListAccounts(ctx, &signerpb.ListAccountsRequest{}); err != nil {
+		t.Fatalf("ListAccounts: %v", err)
+	}
+	_, err := client.CreateAccount(ctx, &signerpb.CreateAccountRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("CreateAccount with the credentials of ListAccounts: got %v, want Unauthenticated", err)
+	}
+
+	if _, err := client.ListAccounts(t.Context(), &signerpb.ListAccountsRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("ListAccounts without credentials: got %v, want Unauthenticated", err)
+	}
+}
+
+func TestClientAuthInterceptor(t *testing.T) {
+	km := signertest.NewKeyManager(t, 1)
+	address := km.GetAccounts(t.Context())[0]
+	conn := startServer(t, km, grpc.WithUnaryInterceptor(signerpb.ClientAuthInterceptor(testAPIKey, testAPISecret)))
+	client := signerpb.NewSignerClient(conn)
+
+	resp, err := client.SignMessage(t.Context(), &signerpb.SignMessageRequest{From: address.Hex(), Message: []byte("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte("hello")), resp.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := crypto.PubkeyToAddress(*pub); got != address {
+		t.Errorf("signature recovers to %s, want %s", got, address)
+	}
+}
+
+func TestInvalidAddresses(t *testing.T) {
+	km := signertest.NewKeyManager(t, 1)
+	account := km.GetAccounts(t.Context())[0]
+	conn := startServer(t, km, grpc.WithUnaryInterceptor(signerpb.ClientAuthInterceptor(testAPIKey, testAPISecret)))
+	client := signerpb.NewSignerClient(conn)
+
+	from := account.Hex()
+	// The EIP-55 test vector 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed with one letter's
+	// case flipped.
+	const badChecksum = "0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	for _, address := range []string{"", "0x1234", from[2:], "0x" + strings.Repeat("zz", 20), from + "00", badChecksum} {
+		calls := map[string]func() error{
+			"SignMessage": func() error {
+				_, err := client.SignMessage(t.Context(), &signerpb.SignMessageRequest{From: address, Message: []byte("hello")})
+				return err
+			},
+			"SignTypedData": func() error {
+				_, err := client.SignTypedData(t.Context(), &signerpb.SignTypedDataRequest{From: address, TypedData: "{}"})
+				return err
+			},
+			"SignTransaction from": func() error {
+				_, err := client.SignTransaction(t.Context(), &signerpb.SignTransactionRequest{From: address, To: from, ChainId: "1", GasPrice: "1", GasLimit: 21000})
+				return err
+			},
+		}
+		if address != "" {
+			calls["SignTransaction to"] = func() error {
+				_, err := client.SignTransaction(t.Context(), &signerpb.SignTransactionRequest{From: from, To: address, ChainId: "1", GasPrice: "1", GasLimit: 21000})
+				return err
+			}
+		}
+		for name, call := range calls {
+			if err := call(); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("%s with address %q: got %v, want InvalidArgument", name, address, err)
+			}
+		}
+	}
+
+	// All-lowercase addresses carry no checksum and are accepted.
+	resp, err := client.SignTransaction(t.Context(), &signerpb.SignTransactionRequest{
+		From: strings.ToLower(from), To: strings.ToLower(from), ChainId: "1", GasPrice: "1", GasLimit: 21000,
+	})
+	if err != nil {
+		t.Fatalf("SignTransaction with lowercase addresses: %v", err)
+	}
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(resp.RawTx); err != nil {
+		t.Fatal(err)
+	}
+	if *tx.To() != account {
+		t.Errorf("to = %s, want %s", tx.To(), account)
+	}
+}
+
+func TestDecimalAmounts(t *testing.T) {
+	km := signertest.NewKeyManager(t, 1)
+	account := km.GetAccounts(t.Context())[0]
+	conn := startServer(t, km, grpc.WithUnaryInterceptor(signerpb.ClientAuthInterceptor(testAPIKey, testAPISecret)))
+	client := signerpb.NewSignerClient(conn)
+	from := account.Hex()
+
+	resp, err := client.SignTransaction(t.Context(), &signerpb.SignTransactionRequest{
+		From: from, To: from, ChainId: "1", GasPrice: "1000000000", Value: "16", GasLimit: 21000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(resp.RawTx); err != nil {
+		t.Fatal(err)
+	}
+	if tx.Value().Int64() != 16 || tx.GasPrice().Int64() != 1000000000 {
+		t.Errorf("value = %s, gas price = %s; want 16 and 1000000000", tx.Value(), tx.GasPrice())
+	}
+
+	// Hex quantities, as taken by the /v1 HTTP API, are rejected rather than misread.
+	for _, req := range []*signerpb.SignTransactionRequest{
+		{From: from, To: from, ChainId: "1", GasPrice: "1", Value: "0x10", GasLimit: 21000},
+		{From: from, To: from, ChainId: "0x1", GasPrice: "1", GasLimit: 21000},
+		{From: from, To: from, ChainId: "1", GasPrice: "-1", GasLimit: 21000},
+	} {
+		if _, err := client.SignTransaction(t.Context(), req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("SignTransaction(value %q, chain_id %q, gas_price %q): got %v, want InvalidArgument", req.Value, req.ChainId, req.GasPrice, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+	"github.com/xueqianLu/ethsigner/internal/ethutil"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/store"
+)
+
+// AccountMetadataHandler handles requests to replace the metadata of an existing account.
+type AccountMetadataHandler struct {
+	signer *signer.Signer
+}
+
+// NewAccountMetadataHandler creates a new AccountMetadataHandler.
+func NewAccountMetadataHandler(s *signer.Signer) *AccountMetadataHandler {
+	return &AccountMetadataHandler{signer: s}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *AccountMetadataHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req UpdateAccountMetadataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+	address, err := ethutil.ParseAddress(req.Address)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidAddress, "Invalid address: "+err.Error())
+		return
+	}
+
+	acc, err := h.signer.UpdateMetadata(r.Context(), address, store.Metadata(req.AccountMetadata))
+	if err != nil {
+		writeSignerError(w, r, "Failed to update account metadata", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(accountInfo(acc)); err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encode response")
+	}
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+	"github.com/xueqianLu/ethsigner/internal/ethutil"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/store"
+)
+
+// archiveConfirmationTTL is how long an archive confirmation token stays valid.
+const archiveConfirmationTTL = 5 * time.Minute
+
+// AccountStateHandler handles requests to disable or re-enable an account.
+type AccountStateHandler struct {
+	apply func(ctx context.Context, address common.Address) (store.Account, error)
+}
+
+// NewDisableAccountHandler creates a handler that disables signing for an account.
+func NewDisableAccountHandler(s *signer.Signer) *AccountStateHandler {
+	return &AccountStateHandler{apply: s.DisableAccount}
+}
+
+// NewEnableAccountHandler creates a handler that re-enables signing for a disabled account.
+func NewEnableAccountHandler(s *signer.Signer) *AccountStateHandler {
+	return &AccountStateHandler{apply: s.EnableAccount}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *AccountStateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req AccountStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+	address, err := ethutil.ParseAddress(req.Address)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidAddress, "Invalid address: "+err.Error())
+		return
+	}
+
+	acc, err := h.apply(r.Context(), address)
+	if err != nil {
+		writeSignerError(w, r, "Failed to change account state", err)
+		return
+	}
+	writeAccountState(w, r, acc)
+}
+
+// ArchiveAccountHandler handles requests to archive an account's key material.
+// Archiving takes two calls: the first returns a confirmation token, and the second
+// must repeat the address together with that token before anything is changed.
+type ArchiveAccountHandler struct {
+	signer *signer.Signer
+
+	mu      sync.Mutex
+	pending map[string]pendingArchive // confirmation token -> request
+}
+
+type pendingArchive struct {
+	address   common.Address
+	expiresAt time.Time
+}
+
+// NewArchiveAccountHandler creates a new ArchiveAccountHandler.
+func NewArchiveAccountHandler(s *signer.Signer) *ArchiveAccountHandler {
+	return &ArchiveAccountHandler{
+		signer:  s,
+		pending: make(map[string]pendingArchive),
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *ArchiveAccountHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req AccountStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+	address, err := ethutil.ParseAddress(req.Address)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidAddress, "Invalid address: "+err.Error())
+		return
+	}
+
+	if req.ConfirmationToken == "" {
+		h.requestConfirmation(w, r, address)
+		return
+	}
+	if !h.confirm(req.ConfirmationToken, address) {
+		apierror.Write(w, r, http.StatusConflict, apierror.CodeConflict, "Invalid or expired confirmation token")
+		return
+	}
+
+	acc, err := h.signer.ArchiveAccount(r.Context(), address)
+	if err != nil {
+		writeSignerError(w, r, "Failed to archive account", err)
+		return
+	}
+	writeAccountState(w, r, acc)
+}
+
+func (h *ArchiveAccountHandler) requestConfirmation(w http.ResponseWriter, r *http.Request, address common.Address) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to generate confirmation token")
+		return
+	}
+	token := hex.EncodeToString(buf)
+	expiresAt := time.Now().Add(archiveConfirmationTTL).UTC()
+
+	h.mu.Lock()
+	now := time.Now()
+	for t, p := range h.pending {
+		if now.After(p.expiresAt) {
+			delete(h.pending, t)
+		}
+	}
+	h.pending[token] = pendingArchive{address: address, expiresAt: expiresAt}
+	h.mu.Unlock()
+
+	resp := ArchiveConfirmationResponse{
+		Address:           address.Hex(),
+		ConfirmationToken: token,
+		ExpiresAt:         expiresAt,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encode response")
+	}
+}
+
+// confirm consumes token if it was issued for address and has not expired.
+func (h *ArchiveAccountHandler) confirm(token string, address common.Address) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	p, ok := h.pending[token]
+	if !ok {
+		return false
+	}
+	delete(h.pending, token)
+	return p.address == address && time.Now().Before(p.expiresAt)
+}
+
+func writeAccountState(w http.ResponseWriter, r *http.Request, acc store.Account) {
+	resp := AccountStateResponse{
+		Address:   acc.Address.Hex(),
+		State:     acc.State,
+		UpdatedAt: acc.UpdatedAt,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encode response")
+	}
+}
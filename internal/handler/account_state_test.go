@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+	"github.com/xueqianLu/ethsigner/internal/signer/signertest"
+)
+
+func TestAccountStateHandlerAddresses(t *testing.T) {
+	km := signertest.NewKeyManager(t, 1)
+	s := signertest.NewSigner(t, km)
+	address := km.GetAccounts(t.Context())[0].Hex()
+	handlers := map[string]http.Handler{
+		"disable": NewDisableAccountHandler(s),
+		"enable":  NewEnableAccountHandler(s),
+		"archive": NewArchiveAccountHandler(s),
+	}
+
+	for name, h := range handlers {
+		for _, bad := range []string{
+			address[2:], // no 0x prefix
+			"0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed", // bad checksum
+			address + "00", // too long
+		} {
+			rec := httptest.NewRecorder()
+			body := `{"address":"` + bad + `"}`
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/admin/"+name+"-account", strings.NewReader(body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("%s %q: status %d, want 400: %s", name, bad, rec.Code, rec.Body)
+			}
+			var resp apierror.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Code != apierror.CodeInvalidAddress {
+				t.Fatalf("%s %q: got %s, want code %s", name, bad, rec.Body, apierror.CodeInvalidAddress)
+			}
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	body := `{"address":"` + strings.ToLower(address) + `"}`
+	handlers["disable"].ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/admin/disable-account", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("disable lower-case address: status %d: %s", rec.Code, rec.Body)
+	}
+}
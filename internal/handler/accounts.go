@@ -3,35 +3,92 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
+	"github.com/xueqianLu/ethsigner/internal/apierror"
 	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/store"
 )
 
 // AccountsHandler handles requests for the list of accounts.
+//
+// Query parameters narrow the listing: tag (repeatable, all must match), owner, and
+// state (repeatable; "all" lists every state). Without state only active accounts are listed.
+// The unversioned route answers with the bare addresses existing clients expect; /v1/
+// answers with each account's state and metadata.
 type AccountsHandler struct {
-	km signer.KeyManager
+	signer *signer.Signer
 }
 
 // NewAccountsHandler creates a new AccountsHandler.
-func NewAccountsHandler(km signer.KeyManager) *AccountsHandler {
-	return &AccountsHandler{km: km}
+func NewAccountsHandler(s *signer.Signer) *AccountsHandler {
+	return &AccountsHandler{signer: s}
 }
 
 // ServeHTTP implements the http.Handler interface.
 func (h *AccountsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	accounts := h.km.GetAccounts()
-	var accStrs []string
-	for _, acc := range accounts {
-		accStrs = append(accStrs, acc.Hex())
+	query := r.URL.Query()
+	filter := signer.AccountFilter{
+		States: query["state"],
+		Tags:   query["tag"],
+		Owner:  query.Get("owner"),
+	}
+	for _, state := range filter.States {
+		switch strings.ToLower(state) {
+		case "all":
+			filter.States = []string{store.StateActive, store.StateDisabled, store.StateArchived}
+		case store.StateActive, store.StateDisabled, store.StateArchived:
+		default:
+			apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid state: "+state)
+			return
+		}
+	}
+
+	accounts, err := h.signer.ListAccounts(r.Context(), filter)
+	if err != nil {
+		writeSignerError(w, r, "Failed to list accounts", err)
+		return
+	}
+	var resp any
+	if apierror.IsVersioned(r) {
+		infos := make([]AccountInfo, 0, len(accounts))
+		for _, acc := range accounts {
+			infos = append(infos, accountInfo(acc))
+		}
+		resp = infos
+	} else {
+		addresses := make([]string, 0, len(accounts))
+		for _, acc := range accounts {
+			addresses = append(addresses, acc.Address.Hex())
+		}
+		resp = addresses
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(accStrs); err != nil {
-		http.Error(w, "Failed to encode accounts", http.StatusInternalServerError)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encode accounts")
+	}
+}
+
+// accountInfo converts a stored account record into its API representation.
+func accountInfo(acc store.Account) AccountInfo {
+	info := AccountInfo{
+		Address:         acc.Address.Hex(),
+		State:           acc.State,
+		AccountMetadata: AccountMetadata(acc.Metadata),
+		Backend:         acc.Backend,
+		KeyRef:          acc.KeyRef,
+	}
+	if !acc.CreatedAt.IsZero() {
+		info.CreatedAt = &acc.CreatedAt
+	}
+	if !acc.UpdatedAt.IsZero() {
+		info.UpdatedAt = &acc.UpdatedAt
 	}
+	return info
 }
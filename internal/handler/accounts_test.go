@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xueqianLu/ethsigner/internal/signer/signertest"
+)
+
+func TestAccountsHandlerShapes(t *testing.T) {
+	km := signertest.NewKeyManager(t, 2)
+	h := NewAccountsHandler(signertest.NewSigner(t, km))
+
+	// The unversioned route keeps the bare address list existing clients decode.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/accounts", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /accounts: status %d: %s", rec.Code, rec.Body)
+	}
+	var addresses []string
+	if err := json.Unmarshal(rec.Body.Bytes(), &addresses); err != nil {
+		t.Fatalf("GET /accounts: %v: %s", err, rec.Body)
+	}
+	want := km.GetAccounts(t.Context())
+	if len(addresses) != len(want) {
+		t.Fatalf("GET /accounts = %v, want %v", addresses, want)
+	}
+	for i, address := range want {
+		if addresses[i] != address.Hex() {
+			t.Fatalf("GET /accounts = %v, want %v", addresses, want)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/accounts", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /v1/accounts: status %d: %s", rec.Code, rec.Body)
+	}
+	var infos []AccountInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &infos); err != nil {
+		t.Fatalf("GET /v1/accounts: %v: %s", err, rec.Body)
+	}
+	if len(infos) != len(want) {
+		t.Fatalf("GET /v1/accounts returned %d accounts, want %d", len(infos), len(want))
+	}
+	for i, info := range infos {
+		if info.Address != want[i].Hex() || info.State != "active" {
+			t.Errorf("GET /v1/accounts[%d] = %+v, want active %s", i, info, want[i].Hex())
+		}
+	}
+}
@@ -2,9 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 
+	"github.com/xueqianLu/ethsigner/internal/apierror"
 	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/store"
 )
 
 // CreateAccountResponse represents the response for a new account creation.
@@ -14,24 +18,31 @@ type CreateAccountResponse struct {
 
 // CreateAccountHandler handles requests to create a new account.
 type CreateAccountHandler struct {
-	km signer.KeyManager
+	signer *signer.Signer
 }
 
 // NewCreateAccountHandler creates a new CreateAccountHandler.
-func NewCreateAccountHandler(km signer.KeyManager) *CreateAccountHandler {
-	return &CreateAccountHandler{km: km}
+func NewCreateAccountHandler(s *signer.Signer) *CreateAccountHandler {
+	return &CreateAccountHandler{signer: s}
 }
 
 // ServeHTTP implements the http.Handler interface.
 func (h *CreateAccountHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	address, err := h.km.CreateKey()
+	// The body is optional so existing clients that POST nothing keep working.
+	var req CreateAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	address, err := h.signer.CreateKey(r.Context(), signer.CreateKeyOptions{Password: req.Password, Backend: req.Backend, Name: req.KeyName}, store.Metadata(req.AccountMetadata))
 	if err != nil {
-		http.Error(w, "Failed to create new account: "+err.Error(), http.StatusInternalServerError)
+		writeSignerError(w, r, "Failed to create new account", err)
 		return
 	}
 
@@ -42,6 +53,6 @@ func (h *CreateAccountHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encode response")
 	}
 }
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+)
+
+// statusForError maps signer errors to the HTTP status codes of the unversioned routes.
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, signer.ErrAccountNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, signer.ErrAccountExists), errors.Is(err, signer.ErrAccountArchived):
+		return http.StatusConflict
+	case errors.Is(err, signer.ErrAccountDisabled):
+		return http.StatusForbidden
+	case errors.Is(err, signer.ErrAccountLocked):
+		return http.StatusLocked
+	case errors.Is(err, signer.ErrWrongPassword), errors.Is(err, signer.ErrPasswordRequired),
+		errors.Is(err, signer.ErrUnknownBackend), errors.Is(err, signer.ErrInvalidKeyName),
+		errors.Is(err, signer.ErrInvalidTypedData):
+		return http.StatusBadRequest
+	case errors.Is(err, signer.ErrNotSupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// apiErrorFor maps signer errors to the status code and error code of the versioned API.
+// Errors not caused by the request are blamed on the key backend.
+func apiErrorFor(err error) (int, string) {
+	switch {
+	case errors.Is(err, signer.ErrAccountNotFound):
+		return http.StatusNotFound, apierror.CodeAccountNotFound
+	case errors.Is(err, signer.ErrAccountExists):
+		return http.StatusConflict, apierror.CodeAccountExists
+	case errors.Is(err, signer.ErrAccountArchived):
+		return http.StatusConflict, apierror.CodeAccountArchived
+	case errors.Is(err, signer.ErrAccountDisabled):
+		return http.StatusForbidden, apierror.CodePolicyDenied
+	case errors.Is(err, signer.ErrAccountLocked):
+		return http.StatusLocked, apierror.CodeAccountLocked
+	case errors.Is(err, signer.ErrWrongPassword):
+		return http.StatusBadRequest, apierror.CodeWrongPassword
+	case errors.Is(err, signer.ErrPasswordRequired), errors.Is(err, signer.ErrUnknownBackend),
+		errors.Is(err, signer.ErrInvalidKeyName), errors.Is(err, signer.ErrInvalidTypedData):
+		return http.StatusBadRequest, apierror.CodeInvalidRequest
+	case errors.Is(err, signer.ErrNotSupported):
+		return http.StatusNotImplemented, apierror.CodeNotSupported
+	default:
+		return http.StatusServiceUnavailable, apierror.CodeBackendUnavailable
+	}
+}
+
+// writeSignerError replies with the error of a failed signer operation. Unversioned routes
+// keep the plain "<msg>: <error>" text. Versioned routes get a stable code, and backend
+// errors are only logged so that their details do not reach the client.
+func writeSignerError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if !apierror.IsVersioned(r) {
+		http.Error(w, msg+": "+err.Error(), statusForError(err))
+		return
+	}
+	status, code := apiErrorFor(err)
+	message := msg + ": " + err.Error()
+	if code == apierror.CodeBackendUnavailable {
+		log.Printf("%s (request %s): %v", msg, w.Header().Get(apierror.RequestIDHeader), err)
+		message = msg + ": key backend unavailable"
+	}
+	apierror.Write(w, r, status, code, message)
+}
@@ -1,8 +1,15 @@
 package handler
 
-import "net/http"
+import (
+	"encoding/json"
+	"net/http"
 
-// HealthHandler handles health checks.
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+)
+
+// HealthHandler handles liveness checks. It only reports that the process is serving
+// requests and never probes the key backend.
 type HealthHandler struct{}
 
 // NewHealthHandler creates a new HealthHandler.
@@ -12,7 +19,51 @@ func NewHealthHandler() *HealthHandler {
 
 // ServeHTTP implements the http.Handler interface.
 func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"status": "ok"}`))
 }
 
+// ReadinessHandler handles readiness checks by probing every component of the key backend.
+type ReadinessHandler struct {
+	signer *signer.Signer
+}
+
+// NewReadinessHandler creates a new ReadinessHandler.
+func NewReadinessHandler(s *signer.Signer) *ReadinessHandler {
+	return &ReadinessHandler{signer: s}
+}
+
+// ServeHTTP implements the http.Handler interface. It responds with 503 if any component is unhealthy.
+func (h *ReadinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	resp := ReadinessResponse{Status: "ok"}
+	for _, c := range h.signer.HealthCheck(r.Context()) {
+		status := ComponentStatus{
+			Name:    c.Name,
+			Status:  "ok",
+			Message: c.Message,
+			Details: c.Details,
+		}
+		if !c.Healthy {
+			status.Status = "fail"
+			resp.Status = "fail"
+		}
+		resp.Components = append(resp.Components, status)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encode response")
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/signer/signertest"
+)
+
+// unhealthyKeyManager reports a failing backend next to a healthy one.
+type unhealthyKeyManager struct {
+	*signertest.KeyManager
+}
+
+func (km unhealthyKeyManager) HealthCheck(ctx context.Context) []signer.ComponentHealth {
+	return []signer.ComponentHealth{
+		{Name: "memory", Healthy: true},
+		{Name: "vault_seal", Message: "vault is sealed", Details: map[string]interface{}{"sealed": true}},
+	}
+}
+
+func readiness(t *testing.T, h http.Handler) (int, ReadinessResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var resp ReadinessResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	return rec.Code, resp
+}
+
+func TestReadinessHandler(t *testing.T) {
+	s := signertest.NewSigner(t, signertest.NewKeyManager(t, 0))
+	code, resp := readiness(t, NewReadinessHandler(s))
+	if code != http.StatusOK || resp.Status != "ok" || len(resp.Components) != 1 || resp.Components[0].Status != "ok" {
+		t.Fatalf("healthy backend: status %d, %+v", code, resp)
+	}
+
+	s = signertest.NewSigner(t, unhealthyKeyManager{signertest.NewKeyManager(t, 0)})
+	code, resp = readiness(t, NewReadinessHandler(s))
+	if code != http.StatusServiceUnavailable || resp.Status != "fail" {
+		t.Fatalf("unhealthy backend: status %d, %+v", code, resp)
+	}
+	if len(resp.Components) != 2 || resp.Components[0].Status != "ok" {
+		t.Fatalf("components = %+v, want the healthy one reported as ok", resp.Components)
+	}
+	if c := resp.Components[1]; c.Status != "fail" || c.Message != "vault is sealed" || c.Details["sealed"] != true {
+		t.Fatalf("failing component = %+v", c)
+	}
+
+	// Liveness does not depend on the backend.
+	rec := httptest.NewRecorder()
+	NewHealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("liveness with an unhealthy backend: status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	NewReadinessHandler(s).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/readyz", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST: status %d, want 405", rec.Code)
+	}
+}
+
+func TestReadinessFailsWhileClosing(t *testing.T) {
+	s := signertest.NewSigner(t, signertest.NewKeyManager(t, 0))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	code, resp := readiness(t, NewReadinessHandler(s))
+	if code != http.StatusServiceUnavailable || resp.Status != "fail" {
+		t.Fatalf("closed signer: status %d, %+v", code, resp)
+	}
+}
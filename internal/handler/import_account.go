@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/store"
+)
+
+// ImportAccountHandler handles requests to import an existing key into the signer.
+type ImportAccountHandler struct {
+	signer  *signer.Signer
+	wrapper *signer.ImportWrapper
+}
+
+// NewImportAccountHandler creates a new ImportAccountHandler.
+func NewImportAccountHandler(s *signer.Signer, wrapper *signer.ImportWrapper) *ImportAccountHandler {
+	return &ImportAccountHandler{signer: s, wrapper: wrapper}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *ImportAccountHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req ImportAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	var privateKey *ecdsa.PrivateKey
+	switch {
+	case len(req.Keystore) > 0 && req.Envelope == "":
+		key, err := keystore.DecryptKey(req.Keystore, req.Password)
+		if err != nil {
+			apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Failed to decrypt keystore: "+err.Error())
+			return
+		}
+		privateKey = key.PrivateKey
+	case req.Envelope != "" && len(req.Keystore) == 0:
+		sealed, err := base64.StdEncoding.DecodeString(req.Envelope)
+		if err != nil {
+			apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Envelope is not valid base64")
+			return
+		}
+		privateKey, err = h.wrapper.Open(sealed)
+		if err != nil {
+			apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error())
+			return
+		}
+	default:
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Exactly one of keystore or envelope is required")
+		return
+	}
+
+	address, err := h.signer.ImportKey(r.Context(), privateKey, signer.CreateKeyOptions{Password: req.AccountPassword, Backend: req.Backend, Name: req.KeyName}, store.Metadata(req.AccountMetadata))
+	if err != nil {
+		writeSignerError(w, r, "Failed to import account", err)
+		return
+	}
+
+	resp := CreateAccountResponse{
+		Address: address.Hex(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encode response")
+	}
+}
+
+// WrappingKeyHandler serves the public key clients use to seal import envelopes.
+type WrappingKeyHandler struct {
+	wrapper *signer.ImportWrapper
+}
+
+// NewWrappingKeyHandler creates a new WrappingKeyHandler.
+func NewWrappingKeyHandler(wrapper *signer.ImportWrapper) *WrappingKeyHandler {
+	return &WrappingKeyHandler{wrapper: wrapper}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *WrappingKeyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	publicKey, err := h.wrapper.PublicKeyPEM()
+	if err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(WrappingKeyResponse{PublicKey: publicKey}); err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encode response")
+	}
+}
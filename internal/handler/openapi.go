@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+	"github.com/xueqianLu/ethsigner/internal/eth2"
+)
+
+// operation describes an endpoint in the OpenAPI document.
+type operation struct {
+	method  string
+	path    string // without the /v1 prefix for versioned operations
+	summary string
+	tag     string
+	// versioned operations are served under /v1/, have their requests validated and
+	// answer errors with an ErrorResponse.
+	versioned bool
+	admin     bool // requires the HMAC admin credentials
+	params    []parameter
+	// request is a value of the request body type; nil when there is no body.
+	request         any
+	requestOptional bool
+	status          int // success status
+	// response is a value of the response body type, or a *schema; nil when there is no
+	// body. contentType defaults to application/json.
+	response    any
+	contentType string
+}
+
+// parameter describes a query or path parameter.
+type parameter struct {
+	name        string
+	in          string // "query" or "path"
+	description string
+	repeated    bool
+}
+
+var healthSchema = &schema{
+	Type:       "object",
+	Properties: map[string]*schema{"status": {Type: "string"}},
+}
+
+// operations lists every endpoint of the HTTP API.
+var operations = []operation{
+	{method: http.MethodGet, path: "/accounts", summary: "List accounts with their state and metadata", tag: "accounts", versioned: true,
+		params: []parameter{
+			{name: "tag", in: "query", description: "only accounts carrying all given tags", repeated: true},
+			{name: "owner", in: "query", description: "only accounts of this owner"},
+			{name: "state", in: "query", description: `"active" (default), "disabled", "archived" or "all"`, repeated: true},
+		},
+		status: http.StatusOK, response: []AccountInfo{}},
+	{method: http.MethodPost, path: "/create-account", summary: "Create an account", tag: "accounts", versioned: true,
+		request: CreateAccountRequest{}, requestOptional: true, status: http.StatusCreated, response: CreateAccountResponse{}},
+	{method: http.MethodPost, path: "/sign-transaction", summary: "Sign a legacy or EIP-1559 transaction", tag: "signing", versioned: true,
+		request: SignTxRequest{}, status: http.StatusOK, response: SignTxResponse{}},
+	{method: http.MethodPost, path: "/sign-raw-transaction", summary: "Sign an unsigned EIP-2718 typed transaction", tag: "signing", versioned: true,
+		request: SignRawTxRequest{}, status: http.StatusOK, response: SignRawTxResponse{}},
+	{method: http.MethodPost, path: "/sign-message", summary: "Sign a message with the EIP-191 personal message prefix", tag: "signing", versioned: true,
+		request: SignMessageRequest{}, status: http.StatusOK, response: SignMessageResponse{}},
+	{method: http.MethodPost, path: "/unlock-account", summary: "Unlock an account with its own password", tag: "accounts", versioned: true, admin: true,
+		request: UnlockAccountRequest{}, status: http.StatusOK, response: UnlockAccountResponse{}},
+	{method: http.MethodPost, path: "/lock-account", summary: "Lock an unlocked account", tag: "accounts", versioned: true, admin: true,
+		request: LockAccountRequest{}, status: http.StatusNoContent},
+	{method: http.MethodGet, path: "/health", summary: "Liveness check", tag: "health", versioned: true,
+		status: http.StatusOK, response: healthSchema},
+	{method: http.MethodGet, path: "/livez", summary: "Liveness check", tag: "health", versioned: true,
+		status: http.StatusOK, response: healthSchema},
+	{method: http.MethodGet, path: "/readyz", summary: "Readiness check probing the key backend; 503 when a component fails", tag: "health", versioned: true,
+		status: http.StatusOK, response: ReadinessResponse{}},
+
+	{method: http.MethodGet, path: "/admin/wrapping-key", summary: "Get the public key sealing import envelopes", tag: "admin", versioned: true, admin: true,
+		status: http.StatusOK, response: WrappingKeyResponse{}},
+	{method: http.MethodPost, path: "/admin/import-account", summary: "Import a key from a keystore or a sealed envelope", tag: "admin", versioned: true, admin: true,
+		request: ImportAccountRequest{}, status: http.StatusCreated, response: CreateAccountResponse{}},
+	{method: http.MethodPost, path: "/admin/disable-account", summary: "Stop an account from signing", tag: "admin", versioned: true, admin: true,
+		request: AccountStateRequest{}, status: http.StatusOK, response: AccountStateResponse{}},
+	{method: http.MethodPost, path: "/admin/enable-account", summary: "Allow a disabled account to sign again", tag: "admin", versioned: true, admin: true,
+		request: AccountStateRequest{}, status: http.StatusOK, response: AccountStateResponse{}},
+	{method: http.MethodPost, path: "/admin/archive-account", summary: "Archive an account; without a confirmation token, answers 202 with one", tag: "admin", versioned: true, admin: true,
+		request: AccountStateRequest{}, status: http.StatusOK, response: AccountStateResponse{}},
+	{method: http.MethodPost, path: "/admin/account-metadata", summary: "Replace the metadata of an account", tag: "admin", versioned: true, admin: true,
+		request: UpdateAccountMetadataRequest{}, status: http.StatusOK, response: AccountInfo{}},
+	{method: http.MethodPost, path: "/admin/rotate-password", summary: "Re-encrypt the local keystore with a new password", tag: "admin", versioned: true, admin: true,
+		request: RotatePasswordRequest{}, status: http.StatusOK, response: RotatePasswordResponse{}},
+
+	{method: http.MethodGet, path: "/openapi.json", summary: "This OpenAPI document", tag: "meta",
+		status: http.StatusOK, response: &schema{Type: "object"}},
+	{method: http.MethodGet, path: "/upcheck", summary: "Web3Signer liveness check", tag: "web3signer",
+		status: http.StatusOK, response: &schema{Type: "string"}, contentType: "text/plain"},
+	{method: http.MethodGet, path: "/api/v1/eth1/publicKeys", summary: "List the public keys of the active accounts", tag: "web3signer",
+		status: http.StatusOK, response: []string{}},
+	{method: http.MethodPost, path: "/api/v1/eth1/sign/{identifier}", summary: "Sign the Keccak-256 hash of data", tag: "web3signer",
+		params:  []parameter{{name: "identifier", in: "path", description: "public key or address of the account"}},
+		request: Eth1SignRequest{}, status: http.StatusOK, response: &schema{Type: "string"}, contentType: "text/plain"},
+	{method: http.MethodGet, path: "/api/v1/eth2/publicKeys", summary: "List the BLS public keys of the validator keys (eth2.enabled)", tag: "web3signer",
+		status: http.StatusOK, response: []string{}},
+	{method: http.MethodPost, path: "/api/v1/eth2/sign/{identifier}", summary: "Sign a consensus-layer message under slashing protection (eth2.enabled)", tag: "web3signer",
+		params:  []parameter{{name: "identifier", in: "path", description: "BLS public key of the validator"}},
+		request: Eth2SignRequest{}, status: http.StatusOK, response: Eth2SignResponse{}},
+	{method: http.MethodGet, path: "/admin/slashing-protection", summary: "Export the slashing protection database (EIP-3076)", tag: "web3signer", admin: true,
+		status: http.StatusOK, response: eth2.Interchange{}},
+	{method: http.MethodPost, path: "/admin/slashing-protection", summary: "Import an EIP-3076 interchange file", tag: "web3signer", admin: true,
+		request: eth2.Interchange{}, status: http.StatusOK, response: &schema{
+			Type:       "object",
+			Properties: map[string]*schema{"validators": {Type: "integer"}},
+		}},
+	{method: http.MethodPost, path: "/clef", summary: "Clef external signer JSON-RPC API (clef.enabled)", tag: "clef",
+		request: &schema{Type: "object", Description: "JSON-RPC 2.0 request"}, status: http.StatusOK,
+		response: &schema{Type: "object", Description: "JSON-RPC 2.0 response"}},
+}
+
+// apiSpec holds the OpenAPI document and the schemas requests are validated against.
+type apiSpec struct {
+	document []byte
+	builder  *schemaBuilder
+	requests map[string]*schema // "<method> <path>" of versioned operations -> request schema
+	optional map[string]bool    // request bodies that may be omitted
+}
+
+var (
+	specOnce sync.Once
+	spec     *apiSpec
+)
+
+// loadSpec builds the OpenAPI document on first use.
+func loadSpec() *apiSpec {
+	specOnce.Do(func() {
+		spec = buildSpec()
+	})
+	return spec
+}
+
+func buildSpec() *apiSpec {
+	b := &schemaBuilder{components: map[string]*schema{}}
+	s := &apiSpec{builder: b, requests: map[string]*schema{}, optional: map[string]bool{}}
+	schemaOf := func(v any, strict bool) *schema {
+		if sch, ok := v.(*schema); ok {
+			return sch
+		}
+		return b.schemaFor(reflect.TypeOf(v), strict)
+	}
+	errorResponse := map[string]any{
+		"description": "Error",
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": schemaOf(ErrorResponse{}, false)},
+		},
+	}
+
+	paths := map[string]map[string]any{}
+	for _, op := range operations {
+		path := op.path
+		if op.versioned {
+			path = strings.TrimSuffix(apierror.VersionPrefix, "/") + op.path
+		}
+		o := map[string]any{
+			"summary":     op.summary,
+			"tags":        []string{op.tag},
+			"operationId": operationID(op),
+		}
+		if op.admin {
+			o["security"] = []map[string][]string{{"apiKey": {}, "timestamp": {}, "signature": {}}}
+		}
+
+		var params []map[string]any
+		for _, p := range op.params {
+			param := map[string]any{
+				"name":        p.name,
+				"in":          p.in,
+				"description": p.description,
+				"required":    p.in == "path",
+				"schema":      &schema{Type: "string"},
+			}
+			if p.repeated {
+				param["schema"] = &schema{Type: "array", Items: &schema{Type: "string"}}
+				param["explode"] = true
+			}
+			params = append(params, param)
+		}
+		if params != nil {
+			o["parameters"] = params
+		}
+
+		if op.request != nil {
+			// Only versioned requests are validated, so only their objects are closed.
+			reqSchema := schemaOf(op.request, op.versioned)
+			o["requestBody"] = map[string]any{
+				"required": !op.requestOptional,
+				"content":  map[string]any{"application/json": map[string]any{"schema": reqSchema}},
+			}
+			// The schemas of the other operations document what their handlers accept;
+			// ValidateRequests does not enforce them.
+			o["x-validated"] = op.versioned
+			if op.versioned {
+				key := op.method + " " + op.path
+				s.requests[key] = reqSchema
+				s.optional[key] = op.requestOptional
+			}
+		}
+
+		resp := map[string]any{"description": http.StatusText(op.status)}
+		if op.response != nil {
+			contentType := op.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			resp["content"] = map[string]any{contentType: map[string]any{"schema": schemaOf(op.response, false)}}
+		}
+		responses := map[string]any{strconv.Itoa(op.status): resp}
+		if op.versioned {
+			responses["default"] = errorResponse
+		}
+		o["responses"] = responses
+
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+		paths[path][strings.ToLower(op.method)] = o
+	}
+
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "ethsigner",
+			"version": "1",
+			"description": "Ethereum signing service. Request bodies of the endpoints under /v1/ are validated " +
+				"against this document before they reach the handlers. Those endpoints are also served without " +
+				"the prefix for existing clients; those routes answer errors in plain text and do not validate " +
+				"requests, and GET /accounts lists bare addresses. The Web3Signer (/api/v1/eth1, /api/v1/eth2, " +
+				"/upcheck, /admin/slashing-protection) and Clef (/clef) endpoints follow their external " +
+				"specifications and are not validated either; their handlers check the requests. Operations " +
+				"with a request body carry x-validated to tell the two apart.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": b.components,
+			"securitySchemes": map[string]any{
+				"apiKey":    map[string]any{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"timestamp": map[string]any{"type": "apiKey", "in": "header", "name": "X-Timestamp", "description": "Unix time in seconds"},
+				"signature": map[string]any{"type": "apiKey", "in": "header", "name": "X-Signature",
+					"description": "hex HMAC-SHA256, keyed with the API secret, of the timestamp followed by the method, " +
+						"a space, the request path and query, a newline and the body"},
+			},
+		},
+	}
+	document, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		panic("handler: failed to encode OpenAPI document: " + err.Error())
+	}
+	s.document = document
+	return s
+}
+
+// operationID derives an operation ID such as "postSignTransaction" from an operation.
+func operationID(op operation) string {
+	id := strings.ToLower(op.method)
+	for _, part := range strings.FieldsFunc(op.path, func(r rune) bool { return r == '/' || r == '-' || r == '{' || r == '}' }) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+// OpenAPIHandler serves the OpenAPI 3 document of the HTTP API.
+type OpenAPIHandler struct{}
+
+// NewOpenAPIHandler creates a new OpenAPIHandler.
+func NewOpenAPIHandler() *OpenAPIHandler {
+	return &OpenAPIHandler{}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *OpenAPIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(loadSpec().document)
+}
+
+// ValidateRequests is a middleware that checks the bodies of requests to the versioned API
+// against the OpenAPI document before they reach the handlers: unknown fields, missing
+// required fields and malformed values are rejected with 400. The unversioned, Web3Signer
+// and Clef routes are passed through unchecked, as the document states.
+func ValidateRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !apierror.IsVersioned(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		s := loadSpec()
+		key := r.Method + " " + strings.TrimPrefix(r.URL.Path, strings.TrimSuffix(apierror.VersionPrefix, "/"))
+		reqSchema, ok := s.requests[key]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if len(bytes.TrimSpace(body)) == 0 && s.optional[key] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err := s.validateBody(body, reqSchema); err != nil {
+			code := apierror.CodeInvalidRequest
+			var verr *validationError
+			if errors.As(err, &verr) {
+				switch {
+				case verr.field == "chainId":
+					code = apierror.CodeInvalidChainID
+				case verr.format == "address":
+					code = apierror.CodeInvalidAddress
+				}
+			}
+			apierror.Write(w, r, http.StatusBadRequest, code, "Invalid request body: "+err.Error())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validateBody decodes a JSON body and validates it against s.
+func (s *apiSpec) validateBody(body []byte, reqSchema *schema) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return &validationError{msg: "malformed JSON"}
+	}
+	if dec.More() {
+		return &validationError{msg: "unexpected data after the JSON value"}
+	}
+	return s.builder.validate(v, reqSchema, "")
+}
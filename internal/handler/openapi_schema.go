@@ -0,0 +1,342 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/xueqianLu/ethsigner/internal/eth2"
+)
+
+// The OpenAPI schemas are derived from the request and response types by reflection, so
+// the published document cannot drift from what the handlers decode. Request fields carry
+// an `openapi` struct tag listing "required" and/or one of the formats below.
+
+// formats maps the format names usable in `openapi` tags to the pattern they impose.
+var formats = map[string]string{
+	"address":  `^0x[0-9a-fA-F]{40}$`,
+	"hex":      `^0x([0-9a-fA-F]{2})*$`,
+	"bytes32":  `^0x[0-9a-fA-F]{64}$`,
+	"decimal":  `^[0-9]+$`,
+	"quantity": `^0x(0|[1-9a-fA-F][0-9a-fA-F]*)$`,
+	"duration": `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`,
+}
+
+// schema is the subset of the OpenAPI 3.0 schema object used by the API. It is also what
+// incoming requests are validated against.
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	AllOf                []*schema          `json:"allOf,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"` // bool or *schema
+}
+
+var (
+	bigIntType  = reflect.TypeOf(big.Int{})
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+	hexBigType  = reflect.TypeOf(hexutil.Big{})
+	hexU64Type  = reflect.TypeOf(hexutil.Uint64(0))
+	hexBytes    = reflect.TypeOf(hexutil.Bytes{})
+	hashType    = reflect.TypeOf(common.Hash{})
+	addrType    = reflect.TypeOf(common.Address{})
+	txType      = reflect.TypeOf(types.Transaction{})
+	eth2Uint64  = reflect.TypeOf(eth2.Uint64(0))
+	eth2Version = reflect.TypeOf(eth2.Version{})
+	eth2Pubkey  = reflect.TypeOf(eth2.PublicKey{})
+	eth2Sig     = reflect.TypeOf(eth2.Signature{})
+)
+
+// schemaBuilder derives schemas from Go types, collecting named structs as components.
+type schemaBuilder struct {
+	components map[string]*schema
+}
+
+// schemaFor returns the schema of values of type t. With strict set, objects reject
+// properties they do not declare.
+func (b *schemaBuilder) schemaFor(t reflect.Type, strict bool) *schema {
+	switch {
+	case t == bigIntType:
+		return &schema{Type: "integer", Minimum: new(int)}
+	case t == timeType:
+		return &schema{Type: "string", Format: "date-time"}
+	case t == rawJSONType:
+		return &schema{Description: "any JSON value"}
+	case t == hexBigType, t == hexU64Type:
+		return &schema{Type: "string", Format: "quantity", Pattern: formats["quantity"]}
+	case t == hexBytes:
+		return &schema{Type: "string", Format: "hex", Pattern: formats["hex"]}
+	case t == hashType:
+		return &schema{Type: "string", Format: "bytes32", Pattern: formats["bytes32"]}
+	case t == addrType:
+		return &schema{Type: "string", Format: "address", Pattern: formats["address"]}
+	case t == eth2Uint64:
+		return &schema{Type: "string", Format: "decimal", Pattern: formats["decimal"]}
+	case t == eth2Version, t == eth2Pubkey, t == eth2Sig:
+		return &schema{Type: "string", Format: "hex", Pattern: fmt.Sprintf("^0x[0-9a-fA-F]{%d}$", 2*t.Len())}
+	case t == txType:
+		return &schema{Type: "object", Description: "transaction object of the Ethereum JSON-RPC API"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := b.schemaFor(t.Elem(), strict)
+		if s.Ref != "" {
+			return &schema{AllOf: []*schema{s}, Nullable: true} // OpenAPI 3.0 ignores siblings of $ref
+		}
+		s.Nullable = true
+		return s
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schema{Type: "integer", Minimum: new(int)}
+	case reflect.Float32, reflect.Float64:
+		return &schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &schema{Type: "string", Format: "byte"} // base64, as encoding/json writes []byte
+		}
+		return &schema{Type: "array", Items: b.schemaFor(t.Elem(), strict)}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: b.schemaFor(t.Elem(), strict)}
+	case reflect.Interface:
+		return &schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t, strict)
+		}
+		if _, ok := b.components[t.Name()]; !ok {
+			b.components[t.Name()] = &schema{} // placeholder for recursive types
+			b.components[t.Name()] = b.structSchema(t, strict)
+		}
+		return &schema{Ref: "#/components/schemas/" + t.Name()}
+	}
+	return &schema{}
+}
+
+// structSchema returns the object schema of a struct type, flattening embedded structs as
+// encoding/json does.
+func (b *schemaBuilder) structSchema(t reflect.Type, strict bool) *schema {
+	s := &schema{Type: "object", Properties: map[string]*schema{}}
+	if strict {
+		s.AdditionalProperties = false
+	}
+	b.addFields(s, t, strict)
+	return s
+}
+
+func (b *schemaBuilder) addFields(s *schema, t reflect.Type, strict bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			b.addFields(s, f.Type, strict)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := b.schemaFor(f.Type, strict)
+		for _, opt := range strings.Split(f.Tag.Get("openapi"), ",") {
+			switch {
+			case opt == "":
+			case opt == "required":
+				s.Required = append(s.Required, name)
+			case formats[opt] != "":
+				prop.Format = opt
+				prop.Pattern = formats[opt]
+			default:
+				panic("handler: unknown openapi tag option " + opt + " on " + t.Name() + "." + f.Name)
+			}
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// validationError describes where a request body does not match its schema.
+type validationError struct {
+	field  string // path of the offending value, e.g. "chainId"; empty for the body itself
+	format string // format of the offending value, if it has one
+	msg    string
+}
+
+func (e *validationError) Error() string {
+	if e.field == "" {
+		return e.msg
+	}
+	return e.field + ": " + e.msg
+}
+
+var patterns sync.Map // pattern -> *regexp.Regexp
+
+// validate checks v, as decoded by a json.Decoder with UseNumber, against s.
+func (b *schemaBuilder) validate(v any, s *schema, field string) error {
+	if s.Ref != "" {
+		return b.validate(v, b.components[strings.TrimPrefix(s.Ref, "#/components/schemas/")], field)
+	}
+	if v == nil {
+		if s.Nullable || (s.Type == "" && len(s.AllOf) == 0) {
+			return nil
+		}
+		return &validationError{field: field, format: s.Format, msg: "must not be null"}
+	}
+	for _, sub := range s.AllOf {
+		if err := b.validate(v, sub, field); err != nil {
+			return err
+		}
+	}
+
+	fail := func(msg string) error {
+		return &validationError{field: field, format: s.Format, msg: msg}
+	}
+	switch s.Type {
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fail("must be a string")
+		}
+		if s.Pattern != "" && !compilePattern(s.Pattern).MatchString(str) {
+			return fail("must be " + formatDescription(s.Format))
+		}
+		switch s.Format {
+		case "byte":
+			if _, err := base64.StdEncoding.DecodeString(str); err != nil {
+				return fail("must be base64")
+			}
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return fail("must be an RFC 3339 date-time")
+			}
+		}
+	case "integer":
+		num, ok := v.(json.Number)
+		if !ok {
+			return fail("must be an integer")
+		}
+		n, ok := new(big.Int).SetString(num.String(), 10)
+		if !ok {
+			return fail("must be an integer")
+		}
+		if s.Minimum != nil && n.Cmp(big.NewInt(int64(*s.Minimum))) < 0 {
+			return fail(fmt.Sprintf("must be at least %d", *s.Minimum))
+		}
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			return fail("must be a number")
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fail("must be a boolean")
+		}
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return fail("must be an array")
+		}
+		for i, item := range items {
+			if err := b.validate(item, s.Items, fmt.Sprintf("%s[%d]", field, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fail("must be an object")
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return &validationError{field: joinField(field, name), format: s.Properties[name].Format, msg: "is required"}
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names) // report the same error for the same request
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				switch extra := s.AdditionalProperties.(type) {
+				case bool:
+					if !extra {
+						return &validationError{field: joinField(field, name), msg: "unknown field"}
+					}
+					continue
+				case *schema:
+					prop = extra
+				default:
+					continue
+				}
+			}
+			if err := b.validate(obj[name], prop, joinField(field, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func compilePattern(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(pattern)
+	patterns.Store(pattern, re)
+	return re
+}
+
+// formatDescription describes the values of a format in error messages.
+func formatDescription(format string) string {
+	switch format {
+	case "address":
+		return "a 0x-prefixed 20-byte hex address"
+	case "hex":
+		return "0x-prefixed hex bytes"
+	case "bytes32":
+		return "0x-prefixed 32-byte hex"
+	case "decimal":
+		return "a decimal integer"
+	case "quantity":
+		return "a 0x-prefixed hex quantity without leading zeros"
+	case "duration":
+		return `a duration such as "15m"`
+	default:
+		return "well-formed"
+	}
+}
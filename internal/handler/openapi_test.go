@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+)
+
+func TestValidateRequests(t *testing.T) {
+	var reached string // body seen by the handler, "" if not reached
+	h := ValidateRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reached = "reached:" + string(body)
+	}))
+
+	const from = `"from": "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"`
+	const tx = from + `, "to": "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", "nonce": "0x0", "gasLimit": "0x5208", "gasPrice": "0x1"`
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   string // expected error code; "" if the request is let through
+		field  string // expected in the error message
+	}{
+		{"valid message", http.MethodPost, "/v1/sign-message", `{` + from + `, "message": "hello"}`, "", ""},
+		{"valid transaction", http.MethodPost, "/v1/sign-transaction", `{` + tx + `, "chainId": "0x1"}`, "", ""},
+		{"empty optional body", http.MethodPost, "/v1/create-account", ``, "", ""},
+		{"unversioned route", http.MethodPost, "/sign-message", `{"from": 1}`, "", ""},
+		{"unknown route", http.MethodPost, "/v1/unknown", `{"from": 1}`, "", ""},
+		{"web3signer route", http.MethodPost, "/api/v1/eth1/sign/0x00", `{"data": 1}`, "", ""},
+		{"clef route", http.MethodPost, "/clef", `{"jsonrpc": 1}`, "", ""},
+		{"other method", http.MethodGet, "/v1/sign-message", ``, "", ""},
+
+		{"malformed JSON", http.MethodPost, "/v1/sign-message", `{` + from, apierror.CodeInvalidRequest, "malformed JSON"},
+		{"trailing data", http.MethodPost, "/v1/sign-message", `{` + from + `, "message": "hello"} {}`, apierror.CodeInvalidRequest, "unexpected data"},
+		{"empty required body", http.MethodPost, "/v1/sign-message", ``, apierror.CodeInvalidRequest, "malformed JSON"},
+		{"not an object", http.MethodPost, "/v1/sign-message", `[]`, apierror.CodeInvalidRequest, "must be an object"},
+		{"missing field", http.MethodPost, "/v1/sign-message", `{` + from + `}`, apierror.CodeInvalidRequest, "message: is required"},
+		{"unknown field", http.MethodPost, "/v1/sign-message", `{` + from + `, "message": "hello", "msg": "hi"}`, apierror.CodeInvalidRequest, "msg: unknown field"},
+		{"wrong type", http.MethodPost, "/v1/sign-message", `{` + from + `, "message": 1}`, apierror.CodeInvalidRequest, "message: must be a string"},
+		{"null field", http.MethodPost, "/v1/sign-message", `{` + from + `, "message": null}`, apierror.CodeInvalidRequest, "message: must not be null"},
+		{"malformed address", http.MethodPost, "/v1/sign-message", `{"from": "0x1234", "message": "hello"}`, apierror.CodeInvalidAddress, "from: must be a 0x-prefixed 20-byte hex address"},
+		{"missing address", http.MethodPost, "/v1/sign-message", `{"message": "hello"}`, apierror.CodeInvalidAddress, "from: is required"},
+		{"missing chain ID", http.MethodPost, "/v1/sign-transaction", `{` + tx + `}`, apierror.CodeInvalidChainID, "chainId: is required"},
+		{"decimal chain ID", http.MethodPost, "/v1/sign-transaction", `{` + tx + `, "chainId": 1}`, apierror.CodeInvalidChainID, "chainId: must be a string"},
+		{"leading zero quantity", http.MethodPost, "/v1/sign-transaction", `{` + tx + `, "chainId": "0x01"}`, apierror.CodeInvalidChainID, "chainId: must be a 0x-prefixed hex quantity"},
+		{"malformed hex data", http.MethodPost, "/v1/sign-transaction", `{` + tx + `, "chainId": "0x1", "data": "0x123"}`, apierror.CodeInvalidRequest, "data: must be 0x-prefixed hex bytes"},
+		{"nested field", http.MethodPost, "/v1/create-account", `{"tags": ["a", 1]}`, apierror.CodeInvalidRequest, "tags[1]: must be a string"},
+	}
+	for _, tt := range tests {
+		reached = ""
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+		if tt.code == "" {
+			if reached != "reached:"+tt.body {
+				t.Errorf("%s: handler saw %q, want the body %q; status %d: %s", tt.name, reached, tt.body, rec.Code, rec.Body)
+			}
+			continue
+		}
+		if reached != "" {
+			t.Errorf("%s: request reached the handler", tt.name)
+		}
+		var resp ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: %v: %s", tt.name, err, rec.Body)
+		}
+		if rec.Code != http.StatusBadRequest || resp.Code != tt.code || !strings.Contains(resp.Error, tt.field) {
+			t.Errorf("%s: status %d, %+v; want 400 %s mentioning %q", tt.name, rec.Code, resp, tt.code, tt.field)
+		}
+	}
+}
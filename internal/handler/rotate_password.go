@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+)
+
+// RotatePasswordHandler handles requests to re-encrypt the keystore with a new password.
+type RotatePasswordHandler struct {
+	signer *signer.Signer
+}
+
+// NewRotatePasswordHandler creates a new RotatePasswordHandler.
+func NewRotatePasswordHandler(s *signer.Signer) *RotatePasswordHandler {
+	return &RotatePasswordHandler{signer: s}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *RotatePasswordHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req RotatePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+	if req.NewPassword == "" {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "newPassword is required")
+		return
+	}
+
+	var scrypt *signer.ScryptParams
+	if req.ScryptN != 0 || req.ScryptP != 0 {
+		scrypt = &signer.ScryptParams{N: req.ScryptN, P: req.ScryptP}
+		if err := scrypt.Validate(); err != nil {
+			apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	rotated, err := h.signer.RotatePassword(r.Context(), req.CurrentPassword, req.NewPassword, scrypt)
+	if err != nil {
+		writeSignerError(w, r, "Failed to rotate password", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(RotatePasswordResponse{Rotated: rotated}); err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encode response")
+	}
+}
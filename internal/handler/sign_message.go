@@ -6,6 +6,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/xueqianLu/ethsigner/internal/apierror"
 	"github.com/xueqianLu/ethsigner/internal/signer"
 )
 
@@ -22,13 +23,13 @@ func NewSignMessageHandler(s *signer.Signer) *SignMessageHandler {
 // ServeHTTP implements the http.Handler interface.
 func (h *SignMessageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	var req SignMessageRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body")
 		return
 	}
 	defer r.Body.Close()
@@ -36,9 +37,9 @@ func (h *SignMessageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	from := common.HexToAddress(req.From)
 	message := []byte(req.Message)
 
-	signature, err := h.signer.SignMessage(from, message)
+	signature, err := h.signer.SignMessage(r.Context(), from, message)
 	if err != nil {
-		http.Error(w, "Failed to sign message: "+err.Error(), http.StatusInternalServerError)
+		writeSignerError(w, r, "Failed to sign message", err)
 		return
 	}
 
@@ -48,7 +49,7 @@ func (h *SignMessageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encode response")
 	}
 }
 
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+	"github.com/xueqianLu/ethsigner/internal/ethutil"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+)
+
+// SignRawTxHandler handles requests to sign a transaction built by the client.
+type SignRawTxHandler struct {
+	signer *signer.Signer
+}
+
+// NewSignRawTxHandler creates a new SignRawTxHandler.
+func NewSignRawTxHandler(s *signer.Signer) *SignRawTxHandler {
+	return &SignRawTxHandler{signer: s}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *SignRawTxHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req SignRawTxRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	fromAddr, err := ethutil.ParseAddress(req.From)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidAddress, "Invalid from: "+err.Error())
+		return
+	}
+
+	tx, err := decodeUnsignedTx(req.Tx)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid tx: "+err.Error())
+		return
+	}
+	// An unsigned legacy transaction has no standard encoding, and without a signature
+	// its RLP carries no chain ID, so only typed transactions are accepted.
+	if tx.Type() == types.LegacyTxType {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid tx: must be a typed (EIP-2718) transaction")
+		return
+	}
+	if v, rr, s := tx.RawSignatureValues(); v.Sign() != 0 || rr.Sign() != 0 || s.Sign() != 0 {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid tx: transaction is already signed")
+		return
+	}
+	chainID := tx.ChainId()
+	if chainID.Sign() == 0 {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidChainID, "Invalid tx: chain ID must not be zero")
+		return
+	}
+
+	signedTx, err := h.signer.SignTx(r.Context(), fromAddr, tx, chainID)
+	if err != nil {
+		writeSignerError(w, r, "Failed to sign transaction", err)
+		return
+	}
+
+	rawTx, err := signedTx.MarshalBinary()
+	if err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to marshal signed transaction: "+err.Error())
+		return
+	}
+
+	signed, err := describeSignedTx(signedTx, fromAddr)
+	if err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to verify signed transaction: "+err.Error())
+		return
+	}
+	resp := SignRawTxResponse{
+		RawTx:    rawTx,
+		SignedTx: signed,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encode response")
+	}
+}
+
+// unsignedTxFields is the number of fields of the standard unsigned encoding
+// type || rlp([chainId, nonce, ..., accessList]) of the transaction types accepted in it.
+// This is the payload a signature is computed over, as ethers' unsignedSerialized and
+// viem's serializeTransaction produce it. Blob transactions are not accepted in this
+// form, since signing one needs its sidecar anyway.
+var unsignedTxFields = map[byte]int{
+	types.AccessListTxType: 8,
+	types.DynamicFeeTxType: 9,
+	types.SetCodeTxType:    10, // with the authorization list
+}
+
+// decodeUnsignedTx decodes a typed transaction in either of its unsigned encodings: the
+// standard one without signature fields, or geth's, which is the signed form with zero
+// V, R and S.
+func decodeUnsignedTx(raw []byte) (*types.Transaction, error) {
+	if len(raw) > 1 {
+		if fields, ok := unsignedTxFields[raw[0]]; ok {
+			content, rest, err := rlp.SplitList(raw[1:])
+			if err == nil && len(rest) == 0 {
+				if n, err := rlp.CountValues(content); err == nil && n == fields {
+					// Append empty signature values to decode it as geth's form.
+					buf := rlp.NewEncoderBuffer(nil)
+					list := buf.List()
+					buf.Write(content)
+					buf.Write([]byte{0x80, 0x80, 0x80})
+					buf.ListEnd(list)
+					raw = append([]byte{raw[0]}, buf.ToBytes()...)
+				}
+			}
+		}
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+	"github.com/xueqianLu/ethsigner/internal/signer/signertest"
+)
+
+// Standard unsigned encodings type || rlp([chainId, nonce, ..., accessList]), as ethers'
+// unsignedSerialized and viem produce them, and the transactions they encode.
+var (
+	testTo          = common.HexToAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359")
+	unsignedTxTests = []struct {
+		name     string
+		unsigned string
+		tx       types.TxData
+	}{
+		{
+			name:     "access list",
+			unsigned: "0x01f86501078504a817c80082c35094fb6916095ca1df60bb79ce92ce3ea74c37c5d35987038d7ea4c6800082cafef838f7945aaeb6053f3e94c9b9a09f33669435e7ef1beaede1a00000000000000000000000000000000000000000000000000000000000000001",
+			tx: &types.AccessListTx{
+				ChainID: big.NewInt(1), Nonce: 7, GasPrice: big.NewInt(20_000_000_000), Gas: 50_000,
+				To: &testTo, Value: big.NewInt(1_000_000_000_000_000), Data: []byte{0xca, 0xfe},
+				AccessList: types.AccessList{{
+					Address:     common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"),
+					StorageKeys: []common.Hash{common.HexToHash("0x01")},
+				}},
+			},
+		},
+		{
+			name:     "dynamic fee",
+			unsigned: "0x02ef83aa36a72a8459682f008506fc23ac0082520894fb6916095ca1df60bb79ce92ce3ea74c37c5d35984075bcd1580c0",
+			tx: &types.DynamicFeeTx{
+				ChainID: big.NewInt(11155111), Nonce: 42, GasTipCap: big.NewInt(1_500_000_000), GasFeeCap: big.NewInt(30_000_000_000),
+				Gas: 21_000, To: &testTo, Value: big.NewInt(123_456_789),
+			},
+		},
+		{
+			name:     "contract creation",
+			unsigned: "0x02ce01800102830186a08080826000c0",
+			tx: &types.DynamicFeeTx{
+				ChainID: big.NewInt(1), GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 100_000,
+				Value: new(big.Int), Data: []byte{0x60, 0x00},
+			},
+		},
+		{
+			name:     "set code",
+			unsigned: "0x04f8490109843b9aca008509502f90008301388094fb6916095ca1df60bb79ce92ce3ea74c37c5d3598080c0dfde019463c0c19a282a1b52b07dd5a65b58948a07dae32b0801821234825678",
+			tx: &types.SetCodeTx{
+				ChainID: uint256.NewInt(1), Nonce: 9, GasTipCap: uint256.NewInt(1_000_000_000), GasFeeCap: uint256.NewInt(40_000_000_000),
+				Gas: 80_000, To: testTo, Value: new(uint256.Int),
+				AuthList: []types.SetCodeAuthorization{{
+					ChainID: *uint256.NewInt(1), Address: common.HexToAddress("0x63c0c19a282a1b52b07dd5a65b58948a07dae32b"),
+					Nonce: 8, V: 1, R: *uint256.NewInt(0x1234), S: *uint256.NewInt(0x5678),
+				}},
+			},
+		},
+	}
+)
+
+func signRawTx(t *testing.T, h http.Handler, from common.Address, raw []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(SignRawTxRequest{From: from.Hex(), Tx: raw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/sign-raw-transaction", strings.NewReader(string(body))))
+	return rec
+}
+
+func TestSignRawTxUnsignedEncodings(t *testing.T) {
+	km := signertest.NewKeyManager(t, 1)
+	from := km.GetAccounts(t.Context())[0]
+	h := NewSignRawTxHandler(signertest.NewSigner(t, km))
+
+	for _, tt := range unsignedTxTests {
+		want := types.NewTx(tt.tx)
+		txSigner := types.LatestSignerForChainID(want.ChainId())
+		unsigned := hexutil.MustDecode(tt.unsigned)
+		// The standard unsigned encoding is what the signature hash is computed over.
+		if hash := crypto.Keccak256Hash(unsigned); hash != txSigner.Hash(want) {
+			t.Fatalf("%s: test vector hashes to %s, want the signing hash %s", tt.name, hash, txSigner.Hash(want))
+		}
+		gethForm, err := want.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for form, raw := range map[string][]byte{"standard": unsigned, "geth": gethForm} {
+			rec := signRawTx(t, h, from, raw)
+			if rec.Code != http.StatusOK {
+				t.Errorf("%s, %s form: status %d: %s", tt.name, form, rec.Code, rec.Body)
+				continue
+			}
+			var resp SignRawTxResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			signed := new(types.Transaction)
+			if err := signed.UnmarshalBinary(resp.RawTx); err != nil {
+				t.Fatal(err)
+			}
+			if signed.Type() != want.Type() || txSigner.Hash(signed) != txSigner.Hash(want) {
+				t.Errorf("%s, %s form: signed a different transaction", tt.name, form)
+			}
+			if sender, err := types.Sender(txSigner, signed); err != nil || sender != from {
+				t.Errorf("%s, %s form: sender %s, %v; want %s", tt.name, form, sender, err, from)
+			}
+		}
+	}
+}
+
+func TestSignRawTxRejects(t *testing.T) {
+	km := signertest.NewKeyManager(t, 1)
+	from := km.GetAccounts(t.Context())[0]
+	h := NewSignRawTxHandler(signertest.NewSigner(t, km))
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID: big.NewInt(1), GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21_000, To: &testTo, Value: new(big.Int),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedRaw, err := signed.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"legacy", "0xe9078504a817c80082520894fb6916095ca1df60bb79ce92ce3ea74c37c5d3598080018080"},
+		{"already signed", hexutil.Encode(signedRaw)},
+		{"field missing", "0x02cd01800102830186a080808260"}, // contract creation without its access list
+		{"trailing data", unsignedTxTests[1].unsigned + "00"},
+		{"unknown type", "0x7fc0"},
+		{"empty", "0x"},
+		{"zero chain ID", "0x02ce80800102830186a08080826000c0"},
+	}
+	for _, tt := range tests {
+		if rec := signRawTx(t, h, from, hexutil.MustDecode(tt.raw)); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400: %s", tt.name, rec.Code, rec.Body)
+		}
+	}
+}
@@ -2,55 +2,79 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"math/big"
 	"net/http"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+	"github.com/xueqianLu/ethsigner/internal/ethutil"
 	"github.com/xueqianLu/ethsigner/internal/signer"
 )
 
 // SignTxHandler handles transaction signing requests.
 type SignTxHandler struct {
 	signer *signer.Signer
+	// legacyFormat also accepts legacySignTxRequest bodies on the unversioned route.
+	legacyFormat bool
 }
 
-// NewSignTxHandler creates a new SignTxHandler.
-func NewSignTxHandler(s *signer.Signer) *SignTxHandler {
-	return &SignTxHandler{signer: s}
+// NewSignTxHandler creates a new SignTxHandler. With legacyFormat set, the unversioned
+// route also accepts the former request format with JSON numbers and base64 data.
+func NewSignTxHandler(s *signer.Signer, legacyFormat bool) *SignTxHandler {
+	return &SignTxHandler{signer: s, legacyFormat: legacyFormat}
 }
 
 // ServeHTTP implements the http.Handler interface.
 func (h *SignTxHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	var req SignTxRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Failed to read request body")
 		return
 	}
+	var req SignTxRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		legacy, ok := h.decodeLegacy(r, body)
+		if !ok {
+			apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body: "+err.Error())
+			return
+		}
+		if legacy.ChainID != "" && legacy.chainID() == nil {
+			apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidChainID, "Invalid ChainID")
+			return
+		}
+		req = legacy.toSignTxRequest()
+	}
 
-	// Convert address strings to common.Address
-	fromAddr := common.HexToAddress(req.From)
+	fromAddr, err := ethutil.ParseAddress(req.From)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidAddress, "Invalid from: "+err.Error())
+		return
+	}
 	var toAddr *common.Address
 	if req.To != "" {
-		to := common.HexToAddress(req.To)
+		to, err := ethutil.ParseAddress(req.To)
+		if err != nil {
+			apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidAddress, "Invalid to: "+err.Error())
+			return
+		}
 		toAddr = &to
 	}
 
-	// Parse ChainID from the request
-	if req.ChainID == "" {
-		http.Error(w, "ChainID is required", http.StatusBadRequest)
-		return
-	}
-	chainID, ok := new(big.Int).SetString(req.ChainID, 10)
-	if !ok {
-		http.Error(w, "Invalid ChainID", http.StatusBadRequest)
+	if req.ChainID == nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidChainID, "ChainID is required")
 		return
 	}
+	chainID := req.ChainID.ToInt()
 
 	// Create the transaction object
 	var tx *types.Transaction
@@ -58,44 +82,119 @@ func (h *SignTxHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if req.GasFeeCap != nil && req.GasTipCap != nil {
 		tx = types.NewTx(&types.DynamicFeeTx{
 			ChainID:   chainID,
-			Nonce:     req.Nonce,
-			GasFeeCap: req.GasFeeCap,
-			GasTipCap: req.GasTipCap,
-			Gas:       req.GasLimit,
+			Nonce:     uint64(req.Nonce),
+			GasFeeCap: req.GasFeeCap.ToInt(),
+			GasTipCap: req.GasTipCap.ToInt(),
+			Gas:       uint64(req.GasLimit),
 			To:        toAddr,
-			Value:     req.Value,
+			Value:     req.Value.ToInt(),
 			Data:      req.Data,
 		})
 	} else { // Legacy
 		tx = types.NewTx(&types.LegacyTx{
-			Nonce:    req.Nonce,
-			GasPrice: req.GasPrice,
-			Gas:      req.GasLimit,
+			Nonce:    uint64(req.Nonce),
+			GasPrice: req.GasPrice.ToInt(),
+			Gas:      uint64(req.GasLimit),
 			To:       toAddr,
-			Value:    req.Value,
+			Value:    req.Value.ToInt(),
 			Data:     req.Data,
 		})
 	}
 
 	// Sign the transaction
-	signedTx, err := h.signer.SignTx(fromAddr, tx, chainID)
+	signedTx, err := h.signer.SignTx(r.Context(), fromAddr, tx, chainID)
 	if err != nil {
-		http.Error(w, "Failed to sign transaction: "+err.Error(), http.StatusInternalServerError)
+		writeSignerError(w, r, "Failed to sign transaction", err)
 		return
 	}
 
 	rawTx, err := signedTx.MarshalBinary()
 	if err != nil {
-		http.Error(w, "Failed to marshal signed transaction: "+err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to marshal signed transaction: "+err.Error())
 		return
 	}
 
+	signed, err := describeSignedTx(signedTx, fromAddr)
+	if err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to verify signed transaction: "+err.Error())
+		return
+	}
 	resp := SignTxResponse{
-		RawTx: common.Bytes2Hex(rawTx),
+		RawTx:    common.Bytes2Hex(rawTx),
+		SignedTx: signed,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encode response")
+	}
+}
+
+// describeSignedTx describes tx, checking that its signature recovers to from.
+func describeSignedTx(tx *types.Transaction, from common.Address) (SignedTx, error) {
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return SignedTx{}, err
+	}
+	if sender != from {
+		return SignedTx{}, fmt.Errorf("signature recovers to %s instead of %s", sender.Hex(), from.Hex())
+	}
+	v, r, s := tx.RawSignatureValues()
+	signed := SignedTx{
+		Hash: tx.Hash(),
+		From: sender,
+		R:    (*hexutil.Big)(r),
+		S:    (*hexutil.Big)(s),
+		V:    (*hexutil.Big)(v),
+		Tx:   tx,
+	}
+	if tx.Type() != types.LegacyTxType {
+		yParity := hexutil.Uint64(v.Uint64())
+		signed.YParity = &yParity
+	}
+	return signed, nil
+}
+
+// decodeLegacy decodes body in the former request format, if the route accepts it.
+func (h *SignTxHandler) decodeLegacy(r *http.Request, body []byte) (legacySignTxRequest, bool) {
+	var legacy legacySignTxRequest
+	if !h.legacyFormat || apierror.IsVersioned(r) {
+		return legacy, false
+	}
+	return legacy, json.Unmarshal(body, &legacy) == nil
+}
+
+// chainID parses the decimal chain ID, returning nil if it is malformed.
+func (req legacySignTxRequest) chainID() *big.Int {
+	chainID, ok := new(big.Int).SetString(req.ChainID, 10)
+	if !ok {
+		return nil
+	}
+	return chainID
+}
+
+// toSignTxRequest converts the request to the current format.
+func (req legacySignTxRequest) toSignTxRequest() SignTxRequest {
+	converted := SignTxRequest{
+		From:      req.From,
+		To:        req.To,
+		Nonce:     hexutil.Uint64(req.Nonce),
+		Value:     (*hexutil.Big)(req.Value),
+		Data:      req.Data,
+		GasLimit:  hexutil.Uint64(req.GasLimit),
+		GasPrice:  (*hexutil.Big)(req.GasPrice),
+		GasFeeCap: (*hexutil.Big)(req.GasFeeCap),
+		GasTipCap: (*hexutil.Big)(req.GasTipCap),
+	}
+	if req.ChainID != "" {
+		converted.ChainID = (*hexutil.Big)(req.chainID())
+	}
+	// The former format addressed accounts with or without 0x prefix.
+	if req.From != "" && !strings.HasPrefix(req.From, "0x") {
+		converted.From = "0x" + req.From
+	}
+	if req.To != "" && !strings.HasPrefix(req.To, "0x") {
+		converted.To = "0x" + req.To
 	}
+	return converted
 }
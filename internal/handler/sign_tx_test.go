@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+	"github.com/xueqianLu/ethsigner/internal/signer/signertest"
+)
+
+func postSignTx(t *testing.T, h http.Handler, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(string(encoded))))
+	return rec
+}
+
+func TestSignTxRequestParsing(t *testing.T) {
+	km := signertest.NewKeyManager(t, 1)
+	h := NewSignTxHandler(signertest.NewSigner(t, km), false)
+	from := km.GetAccounts(t.Context())[0].Hex()
+	valid := map[string]any{
+		"from":     from,
+		"to":       testTo.Hex(),
+		"nonce":    "0x7",
+		"value":    "0xde0b6b3a7640000",
+		"gasLimit": "0x5208",
+		"gasPrice": "0x4a817c800",
+		"chainId":  "0xaa36a7",
+	}
+
+	rec := postSignTx(t, h, "/v1/sign-transaction", valid)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("valid request: status %d: %s", rec.Code, rec.Body)
+	}
+	var resp SignTxResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if tx := resp.Tx; tx.Nonce() != 7 || tx.Gas() != 21000 || tx.ChainId().Cmp(big.NewInt(11155111)) != 0 ||
+		tx.Value().Cmp(big.NewInt(1e18)) != 0 || *tx.To() != testTo {
+		t.Fatalf("signed transaction does not match the request: %+v", tx)
+	}
+
+	tests := []struct {
+		name     string
+		field    string
+		value    any
+		wantCode string
+	}{
+		{"quantity without 0x", "nonce", "7", apierror.CodeInvalidRequest},
+		{"quantity with leading zeros", "gasLimit", "0x05208", apierror.CodeInvalidRequest},
+		{"empty quantity", "value", "0x", apierror.CodeInvalidRequest},
+		{"decimal number", "nonce", 7, apierror.CodeInvalidRequest},
+		{"non-hex digits", "gasPrice", "0xzz", apierror.CodeInvalidRequest},
+		{"missing chain ID", "chainId", nil, apierror.CodeInvalidChainID},
+		{"from without 0x", "from", from[2:], apierror.CodeInvalidAddress},
+		{"from with bad checksum", "from", "0x5aaeb6053F3E94C9b9A09f33669435E7Ef1BeAed", apierror.CodeInvalidAddress},
+		{"short to", "to", testTo.Hex()[:40], apierror.CodeInvalidAddress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := maps.Clone(valid)
+			if tt.value == nil {
+				delete(body, tt.field)
+			} else {
+				body[tt.field] = tt.value
+			}
+			rec := postSignTx(t, h, "/v1/sign-transaction", body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status %d, want 400: %s", rec.Code, rec.Body)
+			}
+			var resp apierror.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Code != tt.wantCode {
+				t.Fatalf("got %s, want code %s", rec.Body, tt.wantCode)
+			}
+
+			// The unversioned route rejects it too, with a plain text error.
+			rec = postSignTx(t, h, "/sign-transaction", body)
+			if rec.Code != http.StatusBadRequest || strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+				t.Fatalf("unversioned route: status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestSignTxLegacyFormat(t *testing.T) {
+	km := signertest.NewKeyManager(t, 1)
+	s := signertest.NewSigner(t, km)
+	from := km.GetAccounts(t.Context())[0]
+	// JSON numbers, a decimal chain ID and an address without 0x prefix.
+	legacy := map[string]any{
+		"from":     strings.ToLower(from.Hex()[2:]),
+		"to":       testTo.Hex(),
+		"nonce":    7,
+		"value":    1000,
+		"gasLimit": 21000,
+		"gasPrice": 20000000000,
+		"chainId":  "11155111",
+	}
+
+	h := NewSignTxHandler(s, true)
+	rec := postSignTx(t, h, "/sign-transaction", legacy)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("legacy request on the unversioned route: status %d: %s", rec.Code, rec.Body)
+	}
+	var resp SignTxResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.From != from || resp.Tx.Nonce() != 7 || resp.Tx.ChainId().Cmp(big.NewInt(11155111)) != 0 {
+		t.Fatalf("signed transaction does not match the request: %+v", resp.SignedTx)
+	}
+
+	// The versioned route only takes the current format.
+	rec = postSignTx(t, h, "/v1/sign-transaction", legacy)
+	var errResp apierror.ErrorResponse
+	if rec.Code != http.StatusBadRequest || json.Unmarshal(rec.Body.Bytes(), &errResp) != nil || errResp.Code != apierror.CodeInvalidRequest {
+		t.Fatalf("legacy request on /v1: status %d: %s", rec.Code, rec.Body)
+	}
+
+	// A hex chain ID is not a legacy decimal one.
+	hexChainID := maps.Clone(legacy)
+	hexChainID["chainId"] = "0xaa36a7"
+	rec = postSignTx(t, h, "/sign-transaction", hexChainID)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "Invalid ChainID") {
+		t.Fatalf("legacy request with a hex chain ID: status %d: %s", rec.Code, rec.Body)
+	}
+
+	// Without server.legacy_tx_format the unversioned route rejects it as well.
+	rec = postSignTx(t, NewSignTxHandler(s, false), "/sign-transaction", legacy)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("legacy request with the legacy format disabled: status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+// swappedKeyManager signs transactions with a key other than the requested account's.
+type swappedKeyManager struct {
+	*signertest.KeyManager
+	key *ecdsa.PrivateKey
+}
+
+func (km swappedKeyManager) SignTx(ctx context.Context, address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), km.key)
+}
+
+func TestSignTxResponse(t *testing.T) {
+	km := signertest.NewKeyManager(t, 1)
+	h := NewSignTxHandler(signertest.NewSigner(t, km), false)
+	from := km.GetAccounts(t.Context())[0]
+	chainID := big.NewInt(11155111)
+
+	tests := []struct {
+		name        string
+		fees        map[string]any
+		wantYParity bool
+	}{
+		{"legacy", map[string]any{"gasPrice": "0x4a817c800"}, false},
+		{"dynamic fee", map[string]any{"gasFeeCap": "0x6fc23ac00", "gasTipCap": "0x3b9aca00"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]any{
+				"from":     from.Hex(),
+				"to":       testTo.Hex(),
+				"nonce":    "0x1",
+				"gasLimit": "0x5208",
+				"chainId":  "0xaa36a7",
+			}
+			maps.Copy(body, tt.fees)
+			rec := postSignTx(t, h, "/v1/sign-transaction", body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", rec.Code, rec.Body)
+			}
+			var resp SignTxResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			// The details describe the transaction encoded in rawTx.
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalBinary(common.FromHex(resp.RawTx)); err != nil {
+				t.Fatalf("rawTx: %v", err)
+			}
+			if resp.Hash != tx.Hash() || resp.Tx.Hash() != tx.Hash() {
+				t.Fatalf("hash = %s, tx hash = %s, want %s", resp.Hash, resp.Tx.Hash(), tx.Hash())
+			}
+			if sender, err := types.Sender(types.LatestSignerForChainID(chainID), tx); err != nil || resp.From != from || sender != from {
+				t.Fatalf("from = %s, rawTx sender = %s (%v), want %s", resp.From, sender, err, from)
+			}
+			v, r, s := tx.RawSignatureValues()
+			if resp.R.ToInt().Cmp(r) != 0 || resp.S.ToInt().Cmp(s) != 0 || resp.V.ToInt().Cmp(v) != 0 {
+				t.Fatalf("r, s, v = %s, %s, %s, want %s, %s, %s", resp.R, resp.S, resp.V, r, s, v)
+			}
+
+			if !tt.wantYParity {
+				// EIP-155: v = chainId * 2 + 35 + recovery ID.
+				if recID := new(big.Int).Sub(v, new(big.Int).Add(new(big.Int).Mul(chainID, big.NewInt(2)), big.NewInt(35))); recID.Sign() < 0 || recID.Cmp(big.NewInt(1)) > 0 {
+					t.Fatalf("legacy v = %s is not EIP-155 encoded for chain %s", v, chainID)
+				}
+				if resp.YParity != nil || strings.Contains(rec.Body.String(), `"yParity"`) {
+					t.Fatalf("legacy transaction has yParity: %s", rec.Body)
+				}
+				return
+			}
+			if resp.YParity == nil || uint64(*resp.YParity) != v.Uint64() || v.Uint64() > 1 {
+				t.Fatalf("yParity = %v, want v = %s", resp.YParity, v)
+			}
+		})
+	}
+}
+
+func TestSignTxRejectsSignatureOfAnotherKey(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	km := signertest.NewKeyManager(t, 1)
+	h := NewSignTxHandler(signertest.NewSigner(t, swappedKeyManager{km, key}), false)
+	rec := postSignTx(t, h, "/v1/sign-transaction", map[string]any{
+		"from":     km.GetAccounts(t.Context())[0].Hex(),
+		"to":       testTo.Hex(),
+		"nonce":    "0x0",
+		"gasLimit": "0x5208",
+		"gasPrice": "0x1",
+		"chainId":  "0x1",
+	})
+	var resp apierror.ErrorResponse
+	if rec.Code != http.StatusInternalServerError || json.Unmarshal(rec.Body.Bytes(), &resp) != nil || resp.Code != apierror.CodeInternal {
+		t.Fatalf("status %d: %s, want an internal error", rec.Code, rec.Body)
+	}
+	if strings.Contains(rec.Body.String(), "rawTx") {
+		t.Fatalf("the transaction was returned: %s", rec.Body)
+	}
+}
@@ -1,30 +1,87 @@
 package handler
 
-import "math/big"
+import (
+	"encoding/json"
+	"math/big"
+	"time"
 
-// SignTxRequest represents the request to sign a transaction.
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+)
+
+// SignTxRequest represents the request to sign a transaction. As in the Ethereum JSON-RPC
+// API, quantities are 0x-prefixed hex without leading zeros and data is 0x-prefixed hex.
 type SignTxRequest struct {
+	From      string         `json:"from" openapi:"required,address"`
+	To        string         `json:"to,omitempty" openapi:"address"` // omit for contract creation
+	Nonce     hexutil.Uint64 `json:"nonce"`
+	Value     *hexutil.Big   `json:"value,omitempty"`
+	Data      hexutil.Bytes  `json:"data,omitempty"`
+	GasLimit  hexutil.Uint64 `json:"gasLimit"`
+	GasPrice  *hexutil.Big   `json:"gasPrice,omitempty"`  // Legacy
+	GasFeeCap *hexutil.Big   `json:"gasFeeCap,omitempty"` // EIP-1559
+	GasTipCap *hexutil.Big   `json:"gasTipCap,omitempty"` // EIP-1559
+	ChainID   *hexutil.Big   `json:"chainId" openapi:"required"`
+}
+
+// legacySignTxRequest is the former format of SignTxRequest, with JSON numbers, base64
+// data and a decimal chain ID. The unversioned route accepts it when
+// server.legacy_tx_format is set.
+type legacySignTxRequest struct {
 	From      string   `json:"from"`
 	To        string   `json:"to"`
 	Nonce     uint64   `json:"nonce"`
 	Value     *big.Int `json:"value"`
 	Data      []byte   `json:"data"`
 	GasLimit  uint64   `json:"gasLimit"`
-	GasPrice  *big.Int `json:"gasPrice,omitempty"`  // Legacy
-	GasFeeCap *big.Int `json:"gasFeeCap,omitempty"` // EIP-1559
-	GasTipCap *big.Int `json:"gasTipCap,omitempty"` // EIP-1559
+	GasPrice  *big.Int `json:"gasPrice,omitempty"`
+	GasFeeCap *big.Int `json:"gasFeeCap,omitempty"`
+	GasTipCap *big.Int `json:"gasTipCap,omitempty"`
 	ChainID   string   `json:"chainId"`
 }
 
 // SignTxResponse represents the response for a signed transaction.
 type SignTxResponse struct {
-	RawTx string `json:"rawTx"`
+	RawTx string `json:"rawTx"` // hex without 0x prefix
+	SignedTx
+}
+
+// SignedTx describes a signed transaction, so that callers can record and broadcast it
+// without decoding the raw transaction.
+type SignedTx struct {
+	Hash common.Hash    `json:"hash"`
+	From common.Address `json:"from"` // recovered from the signature
+	R    *hexutil.Big   `json:"r"`
+	S    *hexutil.Big   `json:"s"`
+	// V is the recovery ID of typed transactions, and 27/28 or EIP-155 encoded for
+	// legacy ones. YParity is only set for typed transactions, where it equals V.
+	V       *hexutil.Big       `json:"v"`
+	YParity *hexutil.Uint64    `json:"yParity,omitempty"`
+	Tx      *types.Transaction `json:"tx"` // as in the Ethereum JSON-RPC API
+}
+
+// SignRawTxRequest represents the request to sign a transaction built by the client:
+// an unsigned EIP-2718 typed transaction. Tx is either the standard unsigned payload
+// type || rlp([chainId, nonce, ..., accessList]), as ethers' unsignedSerialized or viem
+// encode it, or geth's form with zero signature values, as types.Transaction.MarshalBinary
+// encodes an unsigned transaction.
+type SignRawTxRequest struct {
+	From string        `json:"from" openapi:"required,address"`
+	Tx   hexutil.Bytes `json:"tx" openapi:"required"`
+}
+
+// SignRawTxResponse represents the response for a signed raw transaction.
+type SignRawTxResponse struct {
+	RawTx hexutil.Bytes `json:"rawTx"`
+	SignedTx
 }
 
 // SignMessageRequest represents the request to sign a message.
 type SignMessageRequest struct {
-	From    string `json:"from"`
-	Message string `json:"message"`
+	From    string `json:"from" openapi:"required,address"`
+	Message string `json:"message" openapi:"required"`
 }
 
 // SignMessageResponse represents the response for a signed message.
@@ -32,7 +89,131 @@ type SignMessageResponse struct {
 	Signature string `json:"signature"`
 }
 
-// ErrorResponse represents a standard error response.
-type ErrorResponse struct {
-	Error string `json:"error"`
+// ImportAccountRequest represents a request to import an existing private key.
+// Exactly one of Keystore (with its Password) or Envelope must be set.
+type ImportAccountRequest struct {
+	Keystore json.RawMessage `json:"keystore,omitempty"` // V3 keystore JSON
+	Password string          `json:"password,omitempty"` // password of Keystore
+	Envelope string          `json:"envelope,omitempty"` // base64 raw key sealed with the wrapping key
+	// AccountPassword encrypts the imported key; required when per-account passwords are enabled.
+	AccountPassword string `json:"accountPassword,omitempty"`
+	// Backend names the composite key manager backend receiving the key; empty selects the default.
+	Backend string `json:"backend,omitempty"`
+	// KeyName names the key in the backend, e.g. the Vault transit key; empty lets the backend choose.
+	KeyName string `json:"keyName,omitempty"`
+	AccountMetadata
+}
+
+// AccountMetadata represents the descriptive metadata of an account.
+type AccountMetadata struct {
+	Label   string   `json:"label,omitempty"`
+	Owner   string   `json:"owner,omitempty"` // owning team
+	Purpose string   `json:"purpose,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// CreateAccountRequest represents the optional body of a create-account request.
+type CreateAccountRequest struct {
+	// Password encrypts the new key; required when per-account passwords are enabled.
+	Password string `json:"password,omitempty"`
+	// Backend names the composite key manager backend receiving the key; empty selects the default.
+	Backend string `json:"backend,omitempty"`
+	// KeyName names the key in the backend, e.g. the Vault transit key; empty lets the backend choose.
+	KeyName string `json:"keyName,omitempty"`
+	AccountMetadata
+}
+
+// UpdateAccountMetadataRequest represents a request to replace the metadata of an account.
+type UpdateAccountMetadataRequest struct {
+	Address string `json:"address" openapi:"required,address"`
+	AccountMetadata
+}
+
+// AccountInfo represents an account in the /accounts listing.
+type AccountInfo struct {
+	Address string `json:"address"`
+	State   string `json:"state"`
+	AccountMetadata
+	Backend   string     `json:"backend,omitempty"`
+	KeyRef    string     `json:"keyRef,omitempty"` // keystore file or transit key path
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// WrappingKeyResponse represents the public key used to seal import envelopes.
+type WrappingKeyResponse struct {
+	PublicKey string `json:"publicKey"` // PEM-encoded RSA public key
+}
+
+// AccountStateRequest represents a request to disable, enable or archive an account.
+type AccountStateRequest struct {
+	Address string `json:"address" openapi:"required,address"`
+	// ConfirmationToken is only used when archiving; it must echo the token returned
+	// by the first, unconfirmed archive request.
+	ConfirmationToken string `json:"confirmationToken,omitempty"`
+}
+
+// AccountStateResponse represents the lifecycle state of an account after a change.
+type AccountStateResponse struct {
+	Address   string    `json:"address"`
+	State     string    `json:"state"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ArchiveConfirmationResponse is returned by an unconfirmed archive request.
+type ArchiveConfirmationResponse struct {
+	Address           string    `json:"address"`
+	ConfirmationToken string    `json:"confirmationToken"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+}
+
+// UnlockAccountRequest represents a request to unlock an account with its own password.
+type UnlockAccountRequest struct {
+	Address  string `json:"address" openapi:"required,address"`
+	Password string `json:"password" openapi:"required"`
+	// TTL is a Go duration such as "15m"; omitted uses the configured default.
+	TTL string `json:"ttl,omitempty" openapi:"duration"`
+}
+
+// UnlockAccountResponse reports until when an unlocked account stays unlocked.
+type UnlockAccountResponse struct {
+	Address       string    `json:"address"`
+	UnlockedUntil time.Time `json:"unlockedUntil"`
+}
+
+// LockAccountRequest represents a request to lock an unlocked account.
+type LockAccountRequest struct {
+	Address string `json:"address" openapi:"required,address"`
+}
+
+// RotatePasswordRequest represents a request to re-encrypt the local keystore with a new password.
+// ScryptN and ScryptP optionally upgrade the scrypt cost parameters; when zero each
+// keystore file keeps its current parameters.
+type RotatePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword" openapi:"required"`
+	ScryptN         int    `json:"scryptN,omitempty"`
+	ScryptP         int    `json:"scryptP,omitempty"`
+}
+
+// RotatePasswordResponse reports how many keystore files were re-encrypted.
+type RotatePasswordResponse struct {
+	Rotated int `json:"rotated"`
 }
+
+// ReadinessResponse represents the aggregated readiness of the key backend.
+type ReadinessResponse struct {
+	Status     string            `json:"status"` // "ok" or "fail"
+	Components []ComponentStatus `json:"components"`
+}
+
+// ComponentStatus represents the health of a single key backend component.
+type ComponentStatus struct {
+	Name    string                 `json:"name"`
+	Status  string                 `json:"status"` // "ok" or "fail"
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// ErrorResponse represents the error response of the versioned (/v1/) API.
+type ErrorResponse = apierror.ErrorResponse
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+	"github.com/xueqianLu/ethsigner/internal/ethutil"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+)
+
+// UnlockAccountHandler handles requests to unlock an account with its own password.
+type UnlockAccountHandler struct {
+	signer *signer.Signer
+}
+
+// NewUnlockAccountHandler creates a new UnlockAccountHandler.
+func NewUnlockAccountHandler(s *signer.Signer) *UnlockAccountHandler {
+	return &UnlockAccountHandler{signer: s}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *UnlockAccountHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req UnlockAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+	address, err := ethutil.ParseAddress(req.Address)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidAddress, "Invalid address: "+err.Error())
+		return
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid ttl")
+			return
+		}
+	}
+
+	until, err := h.signer.UnlockAccount(r.Context(), address, req.Password, ttl)
+	if err != nil {
+		writeSignerError(w, r, "Failed to unlock account", err)
+		return
+	}
+
+	resp := UnlockAccountResponse{
+		Address:       address.Hex(),
+		UnlockedUntil: until.UTC(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encode response")
+	}
+}
+
+// LockAccountHandler handles requests to lock an unlocked account before its TTL expires.
+type LockAccountHandler struct {
+	signer *signer.Signer
+}
+
+// NewLockAccountHandler creates a new LockAccountHandler.
+func NewLockAccountHandler(s *signer.Signer) *LockAccountHandler {
+	return &LockAccountHandler{signer: s}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *LockAccountHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, r, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req LockAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid request body")
+		return
+	}
+	address, err := ethutil.ParseAddress(req.Address)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidAddress, "Invalid address: "+err.Error())
+		return
+	}
+
+	if err := h.signer.LockAccount(r.Context(), address); err != nil {
+		writeSignerError(w, r, "Failed to lock account", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
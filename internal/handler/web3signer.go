@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+)
+
+// The handlers in this file implement the eth1 subset of the Consensys Web3Signer REST API,
+// so tools written against Web3Signer can use this signer unchanged. Web3Signer identifies
+// keys by their 64-byte uncompressed public key (X || Y) in hex; an account address is
+// accepted as well.
+
+// Eth1SignRequest represents the body of a Web3Signer eth1 sign request.
+type Eth1SignRequest struct {
+	Data string `json:"data" openapi:"required,hex"` // 0x-prefixed bytes; their Keccak-256 hash is signed
+}
+
+// Eth1PublicKeysHandler lists the public keys of the active accounts.
+type Eth1PublicKeysHandler struct {
+	signer *signer.Signer
+}
+
+// NewEth1PublicKeysHandler creates a new Eth1PublicKeysHandler.
+func NewEth1PublicKeysHandler(s *signer.Signer) *Eth1PublicKeysHandler {
+	return &Eth1PublicKeysHandler{signer: s}
+}
+
+// ServeHTTP implements the http.Handler interface. Locked accounts are left out, since
+// their public key is not known until they are unlocked.
+func (h *Eth1PublicKeysHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	publicKeys := []string{}
+	for _, address := range h.signer.GetAccounts(r.Context()) {
+		publicKey, err := h.signer.PublicKey(r.Context(), address)
+		if errors.Is(err, signer.ErrAccountLocked) || errors.Is(err, signer.ErrAccountNotFound) {
+			continue
+		}
+		if err != nil {
+			http.Error(w, "Failed to get public key: "+err.Error(), statusForError(err))
+			return
+		}
+		publicKeys = append(publicKeys, hexutil.Encode(crypto.FromECDSAPub(publicKey)[1:]))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(publicKeys); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// Eth1SignHandler signs the Keccak-256 hash of arbitrary data, as Web3Signer's
+// /api/v1/eth1/sign/{identifier} does. Unlike /sign-message, no EIP-191 prefix is added.
+type Eth1SignHandler struct {
+	signer *signer.Signer
+}
+
+// NewEth1SignHandler creates a new Eth1SignHandler. It must be registered on a pattern
+// with an {identifier} wildcard.
+func NewEth1SignHandler(s *signer.Signer) *Eth1SignHandler {
+	return &Eth1SignHandler{signer: s}
+}
+
+// ServeHTTP implements the http.Handler interface. The signature is returned as
+// 0x-prefixed hex text, [R || S || V] with V being 27 or 28.
+func (h *Eth1SignHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	address, err := parseEth1Identifier(r.PathValue("identifier"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req Eth1SignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+	data, err := hexutil.Decode(req.Data)
+	if err != nil {
+		http.Error(w, "Invalid data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	signature, err := h.signer.SignHash(r.Context(), address, crypto.Keccak256(data))
+	if err != nil {
+		http.Error(w, "Failed to sign data: "+err.Error(), statusForError(err))
+		return
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, hexutil.Encode(signature))
+}
+
+// parseEth1Identifier resolves a Web3Signer key identifier to the account address. It
+// accepts a 64-byte public key, optionally with the 0x04 uncompressed point prefix,
+// or a 20-byte address.
+func parseEth1Identifier(identifier string) (common.Address, error) {
+	raw, err := hexutil.Decode(identifier)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid identifier %q: %w", identifier, err)
+	}
+	switch len(raw) {
+	case common.AddressLength:
+		return common.BytesToAddress(raw), nil
+	case 64:
+		raw = append([]byte{4}, raw...)
+	case 65:
+	default:
+		return common.Address{}, fmt.Errorf("invalid identifier %q: expected a public key or an address", identifier)
+	}
+	publicKey, err := crypto.UnmarshalPubkey(raw)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid identifier %q: %w", identifier, err)
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// UpcheckHandler answers Web3Signer's /upcheck liveness probe.
+type UpcheckHandler struct{}
+
+// NewUpcheckHandler creates a new UpcheckHandler.
+func NewUpcheckHandler() *UpcheckHandler {
+	return &UpcheckHandler{}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *UpcheckHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("OK"))
+}
@@ -0,0 +1,301 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/xueqianLu/ethsigner/internal/eth2"
+)
+
+// The handlers in this file implement the eth2 subset of the Web3Signer REST API. Keys are
+// identified by their 48-byte compressed BLS public key in hex. The signing root is
+// computed from the message in the request, never taken on trust from the validator
+// client, so slashing protection checks exactly what gets signed.
+
+// Eth2SignRequest represents the body of a Web3Signer eth2 sign request. Exactly one
+// message field, the one named by Type, is expected.
+type Eth2SignRequest struct {
+	Type     string         `json:"type" openapi:"required"`
+	ForkInfo *eth2.ForkInfo `json:"fork_info,omitempty"`
+	// SigningRoot is optional; if set it must match the root computed from the message.
+	SigningRoot *common.Hash `json:"signingRoot,omitempty"`
+
+	// Block is the full phase0 block of a BLOCK request, which is not supported.
+	Block       json.RawMessage       `json:"block,omitempty"`
+	BeaconBlock *Eth2BeaconBlock      `json:"beacon_block,omitempty"`
+	Attestation *eth2.AttestationData `json:"attestation,omitempty"`
+	// AggregateAndProof is the message itself for AGGREGATE_AND_PROOF and
+	// {"version": ..., "data": ...} for AGGREGATE_AND_PROOF_V2.
+	AggregateAndProof           json.RawMessage                   `json:"aggregate_and_proof,omitempty"`
+	AggregationSlot             *eth2.AggregationSlot             `json:"aggregation_slot,omitempty"`
+	Deposit                     *eth2.DepositMessage              `json:"deposit,omitempty"`
+	RandaoReveal                *eth2.RandaoReveal                `json:"randao_reveal,omitempty"`
+	VoluntaryExit               *eth2.VoluntaryExit               `json:"voluntary_exit,omitempty"`
+	SyncCommitteeMessage        *eth2.SyncCommitteeMessage        `json:"sync_committee_message,omitempty"`
+	SyncAggregatorSelectionData *eth2.SyncAggregatorSelectionData `json:"sync_aggregator_selection_data,omitempty"`
+	ContributionAndProof        *eth2.ContributionAndProof        `json:"contribution_and_proof,omitempty"`
+	ValidatorRegistration       *eth2.ValidatorRegistration       `json:"validator_registration,omitempty"`
+}
+
+// Eth2BeaconBlock is the beacon_block of a BLOCK_V2 request. Only the header form is
+// supported: a block and its header have the same signing root, and the header does not
+// depend on the fork's block body layout.
+type Eth2BeaconBlock struct {
+	Version     string                  `json:"version"`
+	Block       json.RawMessage         `json:"block,omitempty"`
+	BlockHeader *eth2.BeaconBlockHeader `json:"block_header,omitempty"`
+}
+
+// Eth2SignResponse is returned when the client accepts application/json.
+type Eth2SignResponse struct {
+	Signature string `json:"signature"`
+}
+
+// Eth2PublicKeysHandler lists the public keys of the loaded validator keys.
+type Eth2PublicKeysHandler struct {
+	signer *eth2.Signer
+}
+
+// NewEth2PublicKeysHandler creates a new Eth2PublicKeysHandler.
+func NewEth2PublicKeysHandler(s *eth2.Signer) *Eth2PublicKeysHandler {
+	return &Eth2PublicKeysHandler{signer: s}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Eth2PublicKeysHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	publicKeys := []string{}
+	for _, pub := range h.signer.PublicKeys() {
+		publicKeys = append(publicKeys, pub.Hex())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(publicKeys); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// Eth2SignHandler signs a consensus-layer message, as Web3Signer's
+// /api/v1/eth2/sign/{identifier} does.
+type Eth2SignHandler struct {
+	signer *eth2.Signer
+}
+
+// NewEth2SignHandler creates a new Eth2SignHandler. It must be registered on a pattern
+// with an {identifier} wildcard.
+func NewEth2SignHandler(s *eth2.Signer) *Eth2SignHandler {
+	return &Eth2SignHandler{signer: s}
+}
+
+// ServeHTTP implements the http.Handler interface. The signature is returned as
+// 0x-prefixed hex text, or as JSON when the client accepts application/json. Requests
+// refused by slashing protection get 412 Precondition Failed, as in Web3Signer.
+func (h *Eth2SignHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pub, err := eth2.ParsePublicKey(r.PathValue("identifier"))
+	if err != nil {
+		http.Error(w, "Invalid identifier: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req Eth2SignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+	signingReq, err := req.toSigningRequest()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	signature, err := h.signer.Sign(r.Context(), pub, signingReq)
+	if err != nil {
+		http.Error(w, "Failed to sign: "+err.Error(), statusForEth2Error(err))
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(Eth2SignResponse{Signature: signature.Hex()}); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, signature.Hex())
+}
+
+// toSigningRequest validates the request and picks the message named by its type.
+func (req *Eth2SignRequest) toSigningRequest() (eth2.SigningRequest, error) {
+	out := eth2.SigningRequest{ForkInfo: req.ForkInfo}
+	if req.SigningRoot != nil {
+		root := [32]byte(*req.SigningRoot)
+		out.SigningRoot = &root
+	}
+
+	missing := func(field string) (eth2.SigningRequest, error) {
+		return out, fmt.Errorf("%s is required for type %s", field, req.Type)
+	}
+	switch req.Type {
+	case "":
+		return out, errors.New("type is required")
+	case eth2.TypeBlock:
+		return out, errors.New("signing full phase0 blocks is not supported; send a BLOCK_V2 request with beacon_block.block_header")
+	case eth2.TypeBlockV2:
+		if req.BeaconBlock == nil || req.BeaconBlock.BlockHeader == nil {
+			return out, errors.New("beacon_block.block_header is required; signing full blocks is not supported")
+		}
+		out.Message = req.BeaconBlock.BlockHeader
+	case eth2.TypeAttestation:
+		if req.Attestation == nil {
+			return missing("attestation")
+		}
+		out.Message = req.Attestation
+	case eth2.TypeAggregationSlot:
+		if req.AggregationSlot == nil {
+			return missing("aggregation_slot")
+		}
+		out.Message = req.AggregationSlot
+	case eth2.TypeAggregateAndProof, eth2.TypeAggregateAndProofV2:
+		if len(req.AggregateAndProof) == 0 {
+			return missing("aggregate_and_proof")
+		}
+		msg, err := req.aggregateAndProof()
+		if err != nil {
+			return out, fmt.Errorf("invalid aggregate_and_proof: %w", err)
+		}
+		out.Message = msg
+	case eth2.TypeDeposit:
+		if req.Deposit == nil {
+			return missing("deposit")
+		}
+		out.Message = req.Deposit
+	case eth2.TypeRandaoReveal:
+		if req.RandaoReveal == nil {
+			return missing("randao_reveal")
+		}
+		out.Message = req.RandaoReveal
+	case eth2.TypeVoluntaryExit:
+		if req.VoluntaryExit == nil {
+			return missing("voluntary_exit")
+		}
+		out.Message = req.VoluntaryExit
+	case eth2.TypeSyncCommitteeMessage:
+		if req.SyncCommitteeMessage == nil {
+			return missing("sync_committee_message")
+		}
+		out.Message = req.SyncCommitteeMessage
+	case eth2.TypeSyncCommitteeSelectionProof:
+		if req.SyncAggregatorSelectionData == nil {
+			return missing("sync_aggregator_selection_data")
+		}
+		out.Message = req.SyncAggregatorSelectionData
+	case eth2.TypeSyncCommitteeContributionAndProof:
+		if req.ContributionAndProof == nil {
+			return missing("contribution_and_proof")
+		}
+		out.Message = req.ContributionAndProof
+	case eth2.TypeValidatorRegistration:
+		if req.ValidatorRegistration == nil {
+			return missing("validator_registration")
+		}
+		out.Message = req.ValidatorRegistration
+	default:
+		return out, fmt.Errorf("%w: %q", eth2.ErrUnsupportedType, req.Type)
+	}
+	return out, nil
+}
+
+// aggregateAndProof decodes the aggregate_and_proof of either request version.
+func (req *Eth2SignRequest) aggregateAndProof() (*eth2.AggregateAndProof, error) {
+	msg := new(eth2.AggregateAndProof)
+	if req.Type == eth2.TypeAggregateAndProof {
+		if err := json.Unmarshal(req.AggregateAndProof, msg); err != nil {
+			return nil, err
+		}
+		return msg, msg.CheckFork("PHASE0")
+	}
+	var versioned struct {
+		Version string                  `json:"version"`
+		Data    *eth2.AggregateAndProof `json:"data"`
+	}
+	if err := json.Unmarshal(req.AggregateAndProof, &versioned); err != nil {
+		return nil, err
+	}
+	if versioned.Data == nil {
+		return nil, errors.New("data is required")
+	}
+	return versioned.Data, versioned.Data.CheckFork(strings.ToUpper(versioned.Version))
+}
+
+// SlashingProtectionHandler exports (GET) and imports (POST) the slashing protection
+// database as an EIP-3076 interchange file.
+type SlashingProtectionHandler struct {
+	signer *eth2.Signer
+}
+
+// NewSlashingProtectionHandler creates a new SlashingProtectionHandler.
+func NewSlashingProtectionHandler(s *eth2.Signer) *SlashingProtectionHandler {
+	return &SlashingProtectionHandler{signer: s}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *SlashingProtectionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		interchange, err := h.signer.SlashingProtection().Export()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(interchange); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+	case http.MethodPost:
+		var interchange eth2.Interchange
+		if err := json.NewDecoder(r.Body).Decode(&interchange); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		validators, err := h.signer.SlashingProtection().Import(&interchange)
+		if err != nil {
+			http.Error(w, err.Error(), statusForEth2Error(err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]int{"validators": validators})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// statusForEth2Error maps eth2 signing errors to HTTP status codes.
+func statusForEth2Error(err error) int {
+	switch {
+	case errors.Is(err, eth2.ErrSlashable):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, eth2.ErrKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, eth2.ErrUnsupportedType), errors.Is(err, eth2.ErrInvalidMessage):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xueqianLu/ethsigner/internal/eth2"
+)
+
+// The key of the EIP-2335 test keystore in internal/eth2/testdata.
+const testValidatorKey = "0x9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07"
+
+func newTestEth2Signer(t *testing.T) *eth2.Signer {
+	t.Helper()
+	dir := t.TempDir()
+	data, err := os.ReadFile("../eth2/testdata/pbkdf2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "keystore.json"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := eth2.NewKeyManager(dir, "", "𝔱𝔢𝔰𝔱𝔭𝔞𝔰𝔰𝔴𝔬𝔯𝔡🔑")
+	if err != nil {
+		t.Fatal(err)
+	}
+	protection, err := eth2.OpenSlashingProtection(filepath.Join(t.TempDir(), "slashing.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := eth2.NewSigner(keys, protection, nil)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+const testForkInfoJSON = `"fork_info": {
+	"fork": {"previous_version": "0x04000000", "current_version": "0x05000000", "epoch": "100"},
+	"genesis_validators_root": "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+}`
+
+func blockHeaderRequest(slot, bodyRoot, signingRoot string) string {
+	body := `{"type": "BLOCK_V2", ` + testForkInfoJSON + `, "beacon_block": {"version": "DENEB", "block_header": {
+		"slot": "` + slot + `", "proposer_index": "7",
+		"parent_root": "0x0101010101010101010101010101010101010101010101010101010101010101",
+		"state_root": "0x0202020202020202020202020202020202020202020202020202020202020202",
+		"body_root": "` + bodyRoot + `"}}`
+	if signingRoot != "" {
+		body += `, "signingRoot": "` + signingRoot + `"`
+	}
+	return body + "}"
+}
+
+func TestEth2SignHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/eth2/sign/{identifier}", NewEth2SignHandler(newTestEth2Signer(t)))
+	sign := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/eth2/sign/"+testValidatorKey, strings.NewReader(body)))
+		return rec
+	}
+
+	const bodyRoot = "0x0303030303030303030303030303030303030303030303030303030303030303"
+	rec := sign(blockHeaderRequest("3200", bodyRoot, ""))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), "0x") {
+		t.Fatalf("block header: status %d: %s", rec.Code, rec.Body)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"same block again", blockHeaderRequest("3200", bodyRoot, ""), http.StatusOK},
+		{"second block at the slot", blockHeaderRequest("3200", "0x0404040404040404040404040404040404040404040404040404040404040404", ""), http.StatusPreconditionFailed},
+		{"root of another message",
+			blockHeaderRequest("3201", bodyRoot, "0x1111111111111111111111111111111111111111111111111111111111111111"), http.StatusBadRequest},
+		{"full phase0 block", `{"type": "BLOCK", ` + testForkInfoJSON + `, "block": {"slot": "3300"}}`, http.StatusBadRequest},
+		{"full block", `{"type": "BLOCK_V2", ` + testForkInfoJSON + `, "beacon_block": {"version": "DENEB", "block": {"slot": "3300"}}}`, http.StatusBadRequest},
+		{"without fork_info", `{"type": "RANDAO_REVEAL", "randao_reveal": {"epoch": "3"}}`, http.StatusBadRequest},
+		{"without message", `{"type": "VOLUNTARY_EXIT", ` + testForkInfoJSON + `}`, http.StatusBadRequest},
+		{"unknown type", `{"type": "BLOB_SIDECAR", ` + testForkInfoJSON + `}`, http.StatusBadRequest},
+		{"aggregate of another fork", `{"type": "AGGREGATE_AND_PROOF_V2", ` + testForkInfoJSON + `, "aggregate_and_proof": {
+			"version": "ELECTRA", "data": {"aggregator_index": "1", "aggregate": {"aggregation_bits": "0x01"}}}}`, http.StatusBadRequest},
+		{"randao reveal", `{"type": "RANDAO_REVEAL", ` + testForkInfoJSON + `, "randao_reveal": {"epoch": "3"}}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if rec := sign(tt.body); rec.Code != tt.status {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, rec.Code, tt.status, rec.Body)
+		}
+	}
+}
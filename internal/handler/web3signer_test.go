@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/xueqianLu/ethsigner/internal/signer/signertest"
+)
+
+func TestEth1PublicKeysHandler(t *testing.T) {
+	km := signertest.NewKeyManager(t, 2)
+	s := signertest.NewSigner(t, km)
+	h := NewEth1PublicKeysHandler(s)
+	accounts := km.GetAccounts(t.Context())
+
+	publicKeys := func() []string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/eth1/publicKeys", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		var keys []string
+		if err := json.Unmarshal(rec.Body.Bytes(), &keys); err != nil {
+			t.Fatal(err)
+		}
+		return keys
+	}
+
+	keys := publicKeys()
+	if len(keys) != len(accounts) {
+		t.Fatalf("got %d public keys, want %d", len(keys), len(accounts))
+	}
+	for i, address := range accounts {
+		// 64 bytes, X || Y, without the 0x04 prefix.
+		want := hexutil.Encode(crypto.FromECDSAPub(&km.Key(address).PublicKey)[1:])
+		if keys[i] != want {
+			t.Fatalf("public key %d = %s, want %s", i, keys[i], want)
+		}
+	}
+
+	if _, err := s.DisableAccount(t.Context(), accounts[0]); err != nil {
+		t.Fatal(err)
+	}
+	if keys := publicKeys(); len(keys) != 1 || keys[0] != hexutil.Encode(crypto.FromECDSAPub(&km.Key(accounts[1]).PublicKey)[1:]) {
+		t.Fatalf("public keys with a disabled account = %v", keys)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/eth1/publicKeys", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST: status %d, want 405", rec.Code)
+	}
+}
+
+func TestEth1SignHandler(t *testing.T) {
+	km := signertest.NewKeyManager(t, 2)
+	s := signertest.NewSigner(t, km)
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/eth1/sign/{identifier}", NewEth1SignHandler(s))
+	accounts := km.GetAccounts(t.Context())
+	address := accounts[0]
+	publicKey := crypto.FromECDSAPub(&km.Key(address).PublicKey)
+
+	sign := func(identifier, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/eth1/sign/"+identifier, strings.NewReader(body)))
+		return rec
+	}
+
+	data := []byte("web3signer")
+	for _, identifier := range []string{
+		hexutil.Encode(publicKey[1:]),
+		hexutil.Encode(publicKey),
+		address.Hex(),
+		strings.ToLower(address.Hex()),
+	} {
+		rec := sign(identifier, `{"data": "`+hexutil.Encode(data)+`"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("identifier %s: status %d: %s", identifier, rec.Code, rec.Body)
+		}
+		signature, err := hexutil.Decode(rec.Body.String())
+		if err != nil {
+			t.Fatalf("identifier %s: %v", identifier, err)
+		}
+		if v := signature[crypto.RecoveryIDOffset]; v != 27 && v != 28 {
+			t.Fatalf("identifier %s: V = %d, want 27 or 28", identifier, v)
+		}
+		signature[crypto.RecoveryIDOffset] -= 27
+		// The plain Keccak-256 hash of the data is signed, without the EIP-191 prefix.
+		pub, err := crypto.SigToPub(crypto.Keccak256(data), signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := crypto.PubkeyToAddress(*pub); got != address {
+			t.Fatalf("identifier %s: signature recovers to %s, want %s", identifier, got, address)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		identifier string
+		body       string
+		wantStatus int
+	}{
+		{"short identifier", address.Hex()[:20], `{"data": "0x01"}`, http.StatusBadRequest},
+		{"identifier without 0x", hexutil.Encode(publicKey[1:])[2:], `{"data": "0x01"}`, http.StatusBadRequest},
+		{"point not on the curve", "0x" + strings.Repeat("11", 64), `{"data": "0x01"}`, http.StatusBadRequest},
+		{"data without 0x", address.Hex(), `{"data": "01"}`, http.StatusBadRequest},
+		{"malformed body", address.Hex(), `{"data":`, http.StatusBadRequest},
+		{"unknown account", testTo.Hex(), `{"data": "0x01"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := sign(tt.identifier, tt.body); rec.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+
+	if _, err := s.DisableAccount(t.Context(), accounts[1]); err != nil {
+		t.Fatal(err)
+	}
+	if rec := sign(accounts[1].Hex(), `{"data": "0x01"}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("disabled account: status %d, want 403: %s", rec.Code, rec.Body)
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"strconv"
 	"time"
+
+	"github.com/xueqianLu/ethsigner/internal/apierror"
 )
 
 const (
@@ -35,56 +38,18 @@ func NewAuthMiddleware(apiKey, apiSecret string) *AuthMiddleware {
 // Wrap wraps an http.Handler with authentication.
 func (m *AuthMiddleware) Wrap(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// 1. Check API Key
-		requestAPIKey := r.Header.Get(apiKeyHeader)
-		if requestAPIKey != m.apiKey {
-			http.Error(w, "Invalid API Key", http.StatusUnauthorized)
-			return
-		}
-
-		// 2. Check Timestamp
-		timestampStr := r.Header.Get(timestampHeader)
-		if timestampStr == "" {
-			http.Error(w, "Missing timestamp header", http.StatusUnauthorized)
-			return
-		}
-		timestamp, err := strconv.ParseInt(timestampStr, 10, 64)
-		if err != nil {
-			http.Error(w, "Invalid timestamp format", http.StatusUnauthorized)
-			return
-		}
-		if time.Now().Unix()-timestamp > maxTimeSkew {
-			http.Error(w, "Timestamp expired", http.StatusUnauthorized)
-			return
-		}
-
-		// 3. Check Signature
-		requestSignature := r.Header.Get(signatureHeader)
-		if requestSignature == "" {
-			http.Error(w, "Missing signature header", http.StatusUnauthorized)
-			return
-		}
-
 		// Read the body
 		body, err := ioutil.ReadAll(r.Body)
 		if err != nil {
-			http.Error(w, "Failed to read request body", http.StatusInternalServerError)
+			apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to read request body")
 			return
 		}
 		// Restore the body so the next handler can read it
 		r.Body = ioutil.NopCloser(bytes.NewBuffer(body))
 
-		// Create the payload to sign
-		payload := timestampStr + string(body)
-
-		// Calculate the expected signature
-		mac := hmac.New(sha256.New, []byte(m.apiSecret))
-		mac.Write([]byte(payload))
-		expectedSignature := hex.EncodeToString(mac.Sum(nil))
-
-		// Compare signatures
-		if !hmac.Equal([]byte(requestSignature), []byte(expectedSignature)) {
-			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		err = m.Verify(r.Header.Get(apiKeyHeader), r.Header.Get(timestampHeader), r.Header.Get(signatureHeader), RequestPayload(r.Method, r.URL.RequestURI(), body))
+		if err != nil {
+			apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, err.Error())
 			return
 		}
 
@@ -93,3 +58,57 @@ func (m *AuthMiddleware) Wrap(next http.Handler) http.Handler {
 	})
 }
 
+// RequestPayload returns the bytes authenticated for an HTTP request: the method, a space,
+// the request URI (path and query) and a newline, followed by the body. Binding the method
+// and path keeps a signed request from being replayed against another endpoint that takes
+// the same body, e.g. an enable-account request as disable-account.
+func RequestPayload(method, requestURI string, body []byte) []byte {
+	payload := make([]byte, 0, len(method)+len(requestURI)+2+len(body))
+	payload = append(payload, method...)
+	payload = append(payload, ' ')
+	payload = append(payload, requestURI...)
+	payload = append(payload, '\n')
+	return append(payload, body...)
+}
+
+// Verify checks an API key, a Unix timestamp and the hex HMAC-SHA256 signature of the
+// timestamp followed by payload. It is shared by the HTTP and gRPC front ends.
+func (m *AuthMiddleware) Verify(apiKey, timestampStr, signature string, payload []byte) error {
+	// 1. Check API Key
+	if apiKey != m.apiKey {
+		return errors.New("Invalid API Key")
+	}
+
+	// 2. Check Timestamp
+	if timestampStr == "" {
+		return errors.New("Missing timestamp header")
+	}
+	timestamp, err := strconv.ParseInt(timestampStr, 10, 64)
+	if err != nil {
+		return errors.New("Invalid timestamp format")
+	}
+	skew := time.Now().Unix() - timestamp
+	if skew > maxTimeSkew {
+		return errors.New("Timestamp expired")
+	}
+	if skew < -maxTimeSkew {
+		return errors.New("Timestamp in the future")
+	}
+
+	// 3. Check Signature
+	if signature == "" {
+		return errors.New("Missing signature header")
+	}
+
+	// Calculate the expected signature
+	mac := hmac.New(sha256.New, []byte(m.apiSecret))
+	mac.Write([]byte(timestampStr))
+	mac.Write(payload)
+	expectedSignature := hex.EncodeToString(mac.Sum(nil))
+
+	// Compare signatures
+	if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
+		return errors.New("Invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testAPIKey    = "admin"
+	testAPISecret = "secret"
+)
+
+func sign(timestamp, method, requestURI, body string) string {
+	mac := hmac.New(sha256.New, []byte(testAPISecret))
+	mac.Write([]byte(timestamp))
+	mac.Write(RequestPayload(method, requestURI, []byte(body)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	auth := NewAuthMiddleware(testAPIKey, testAPISecret)
+	h := auth.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	now := time.Now().Unix()
+	body := `{"address":"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"}`
+	enable := "/v1/admin/enable-account"
+	tests := []struct {
+		name      string
+		uri       string
+		timestamp int64
+		signedURI string
+		want      int
+	}{
+		{"valid", enable, now, enable, http.StatusOK},
+		{"small skew", enable, now + 30, enable, http.StatusOK},
+		{"replayed on another path", "/v1/admin/disable-account", now, enable, http.StatusUnauthorized},
+		{"replayed on the unversioned path", "/admin/enable-account", now, enable, http.StatusUnauthorized},
+		{"expired", enable, now - 2*maxTimeSkew, enable, http.StatusUnauthorized},
+		{"future", enable, now + 2*maxTimeSkew, enable, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timestamp := strconv.FormatInt(tt.timestamp, 10)
+			req := httptest.NewRequest(http.MethodPost, tt.uri, strings.NewReader(body))
+			req.Header.Set(apiKeyHeader, testAPIKey)
+			req.Header.Set(timestampHeader, timestamp)
+			req.Header.Set(signatureHeader, sign(timestamp, http.MethodPost, tt.signedURI, body))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	// The method is signed too.
+	timestamp := strconv.FormatInt(now, 10)
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/wrapping-key", nil)
+	req.Header.Set(apiKeyHeader, testAPIKey)
+	req.Header.Set(timestampHeader, timestamp)
+	req.Header.Set(signatureHeader, sign(timestamp, http.MethodPost, "/v1/admin/wrapping-key", ""))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("request signed for another method: got status %d, want 401", rec.Code)
+	}
+}
@@ -4,14 +4,17 @@ import (
 	"log"
 	"net/http"
 	"time"
+
+	"github.com/xueqianLu/ethsigner/internal/apierror"
 )
 
 // Logging is a middleware that logs details about each request.
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		log.Printf("Started %s %s", r.Method, r.URL.Path)
+		id := r.Header.Get(apierror.RequestIDHeader)
+		log.Printf("Started %s %s (request %s)", r.Method, r.URL.Path, id)
 		next.ServeHTTP(w, r)
-		log.Printf("Completed %s in %v", r.URL.Path, time.Since(start))
+		log.Printf("Completed %s in %v (request %s)", r.URL.Path, time.Since(start), id)
 	})
 }
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// RunGRPC serves srv on port until ctx is cancelled, then stops accepting new calls and
+// waits up to shutdownTimeout for in-flight calls to complete.
+func RunGRPC(ctx context.Context, srv *grpc.Server, port string, shutdownTimeout time.Duration) error {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(lis)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down gRPC server, draining in-flight calls (timeout %s)", shutdownTimeout)
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		// Drain timed out; cut the remaining connections.
+		log.Printf("Drain timeout of %s exceeded, stopping gRPC calls still in flight", shutdownTimeout)
+		srv.Stop()
+		<-stopped
+		return context.DeadlineExceeded
+	}
+	return <-errCh
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+// PeerCredentials identifies the process at the other end of a Unix socket connection.
+type PeerCredentials struct {
+	PID int
+	UID int
+	GID int
+}
+
+type peerKey struct{}
+
+// peerInfo is stored in the context of every request received over a Unix socket.
+type peerInfo struct {
+	cred PeerCredentials
+	err  error
+}
+
+// PeerCredentialsFromContext returns the peer credentials of the Unix socket connection
+// a request arrived on. It reports false for TCP requests and where peer credentials are
+// not supported.
+func PeerCredentialsFromContext(ctx context.Context) (PeerCredentials, bool) {
+	peer, ok := ctx.Value(peerKey{}).(peerInfo)
+	if !ok || peer.err != nil {
+		return PeerCredentials{}, false
+	}
+	return peer.cred, true
+}
+
+// withPeerCredentials is the http.Server ConnContext hook recording who is connected
+// to a Unix socket.
+func withPeerCredentials(ctx context.Context, c net.Conn) context.Context {
+	conn, ok := c.(*net.UnixConn)
+	if !ok {
+		return ctx
+	}
+	cred, err := peerCredentials(conn)
+	return context.WithValue(ctx, peerKey{}, peerInfo{cred: cred, err: err})
+}
+
+// lookupOwner resolves "user[:group]", given as names or numeric ids. Without a group the
+// user's primary group is used.
+func lookupOwner(owner string) (uid, gid int, err error) {
+	userName, groupName, hasGroup := strings.Cut(owner, ":")
+
+	u, err := user.Lookup(userName)
+	if err != nil {
+		if u, err = user.LookupId(userName); err != nil {
+			return 0, 0, fmt.Errorf("unknown socket owner %q", userName)
+		}
+	}
+	if uid, err = strconv.Atoi(u.Uid); err != nil {
+		return 0, 0, fmt.Errorf("socket owner %q has no numeric uid", userName)
+	}
+	gidStr := u.Gid
+	if hasGroup {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			if g, err = user.LookupGroupId(groupName); err != nil {
+				return 0, 0, fmt.Errorf("unknown socket group %q", groupName)
+			}
+		}
+		gidStr = g.Gid
+	}
+	if gid, err = strconv.Atoi(gidStr); err != nil {
+		return 0, 0, fmt.Errorf("socket group %q has no numeric gid", gidStr)
+	}
+	return uid, gid, nil
+}
@@ -1,17 +1,200 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
 	"net/http"
+	"os"
+	"slices"
+	"strings"
 	"time"
 )
 
+// Options configures where a Server listens.
+type Options struct {
+	// Port is the TCP port to listen on; empty disables the TCP listener.
+	Port string
+	// Socket configures an additional Unix domain socket listener.
+	Socket SocketOptions
+}
+
+// SocketOptions configures a Unix domain socket listener.
+type SocketOptions struct {
+	// Path of the socket; empty disables the socket listener.
+	Path string
+	// Mode is the permission of the socket file.
+	Mode os.FileMode
+	// Owner is "user[:group]", as names or numeric ids; empty keeps the process owner.
+	Owner string
+	// AllowedUIDs and AllowedGIDs restrict socket callers by their peer credentials: a
+	// caller is admitted if its uid or its gid is listed. When both are empty, every
+	// process that can open the socket is admitted. TCP requests are not affected.
+	AllowedUIDs []int
+	AllowedGIDs []int
+}
+
+// Server is an HTTP server listening on a TCP port, a Unix domain socket, or both.
+type Server struct {
+	srv  *http.Server
+	opts Options
+}
+
 // NewServer creates and configures an HTTP server.
-func NewServer(handler http.Handler, port string) *http.Server {
-	return &http.Server{
-		Addr:         ":" + port,
-		Handler:      handler,
+func NewServer(handler http.Handler, opts Options) *Server {
+	s := &Server{opts: opts}
+	s.srv = &http.Server{
+		Addr:         ":" + opts.Port,
+		Handler:      s.authorizePeer(handler),
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
+		ConnContext:  withPeerCredentials,
+	}
+	return s
+}
+
+// Describe returns a human-readable list of the addresses the server listens on.
+func (s *Server) Describe() string {
+	var addrs []string
+	if s.opts.Port != "" {
+		addrs = append(addrs, "port "+s.opts.Port)
+	}
+	if s.opts.Socket.Path != "" {
+		addrs = append(addrs, "socket "+s.opts.Socket.Path)
+	}
+	return strings.Join(addrs, " and ")
+}
+
+// Run serves srv until ctx is cancelled, then stops accepting new connections and
+// waits up to shutdownTimeout for in-flight requests to complete.
+func Run(ctx context.Context, srv *Server, shutdownTimeout time.Duration) error {
+	listeners, err := srv.listen()
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func() {
+			errCh <- srv.srv.Serve(l)
+		}()
+	}
+
+	select {
+	case err := <-errCh:
+		srv.srv.Close()
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down server, draining in-flight requests (timeout %s)", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.srv.Shutdown(shutdownCtx); err != nil {
+		// Drain timed out; cut the remaining connections. Their handlers may still be
+		// running; the signer waits for them before wiping keys.
+		log.Printf("Drain timeout of %s exceeded, closing connections with requests still in flight", shutdownTimeout)
+		srv.srv.Close()
+		return err
+	}
+	for range listeners {
+		if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	}
+	return nil
+}
+
+// listen opens the configured listeners.
+func (s *Server) listen() ([]net.Listener, error) {
+	var listeners []net.Listener
+	if s.opts.Port != "" {
+		l, err := net.Listen("tcp", s.srv.Addr)
+		if err != nil {
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+	if s.opts.Socket.Path != "" {
+		l, err := listenUnix(s.opts.Socket)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+	if len(listeners) == 0 {
+		return nil, errors.New("no listener configured: set a port or a socket path")
+	}
+	return listeners, nil
+}
+
+// listenUnix creates the socket file with the configured mode and owner. A socket left
+// behind by a previous run is replaced, unless a server still answers on it.
+func listenUnix(opts SocketOptions) (net.Listener, error) {
+	if fi, err := os.Lstat(opts.Path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", opts.Path)
+		}
+		if conn, err := net.Dial("unix", opts.Path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("socket %s is in use (is the signer running?)", opts.Path)
+		}
+		if err := os.Remove(opts.Path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	l, err := net.Listen("unix", opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(opts.Path, opts.Mode); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to set socket mode: %w", err)
+	}
+	if opts.Owner != "" {
+		uid, gid, err := lookupOwner(opts.Owner)
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+		if err := os.Chown(opts.Path, uid, gid); err != nil {
+			l.Close()
+			return nil, fmt.Errorf("failed to set socket owner: %w", err)
+		}
+	}
+	return l, nil
+}
+
+// authorizePeer rejects socket requests whose peer credentials are not allowed.
+func (s *Server) authorizePeer(next http.Handler) http.Handler {
+	allowedUIDs, allowedGIDs := s.opts.Socket.AllowedUIDs, s.opts.Socket.AllowedGIDs
+	if len(allowedUIDs) == 0 && len(allowedGIDs) == 0 {
+		return next
 	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer, ok := r.Context().Value(peerKey{}).(peerInfo)
+		if !ok {
+			next.ServeHTTP(w, r) // not a socket connection
+			return
+		}
+		if peer.err != nil {
+			log.Printf("Rejected socket request to %s: %v", r.URL.Path, peer.err)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if !slices.Contains(allowedUIDs, peer.cred.UID) && !slices.Contains(allowedGIDs, peer.cred.GID) {
+			log.Printf("Rejected socket request to %s from uid %d gid %d pid %d", r.URL.Path, peer.cred.UID, peer.cred.GID, peer.cred.PID)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
@@ -0,0 +1,94 @@
+//go:build linux
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// serveSocket runs a server on a Unix socket that answers with the caller's peer
+// credentials, and returns a client connected to it.
+func serveSocket(t *testing.T, opts SocketOptions) *http.Client {
+	t.Helper()
+	opts.Path = filepath.Join(t.TempDir(), "signer.sock")
+	opts.Mode = 0600
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cred, ok := PeerCredentialsFromContext(r.Context())
+		if !ok {
+			http.Error(w, "no peer credentials", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(cred)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, NewServer(handler, Options{Socket: opts}), time.Second)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	})
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		if _, err := os.Stat(opts.Path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("socket was not created")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", opts.Path)
+		},
+	}}
+}
+
+func TestSocketPeerCredentials(t *testing.T) {
+	uid, gid := os.Getuid(), os.Getgid()
+	tests := []struct {
+		name       string
+		opts       SocketOptions
+		wantStatus int
+	}{
+		{"unrestricted", SocketOptions{}, http.StatusOK},
+		{"allowed uid", SocketOptions{AllowedUIDs: []int{uid}}, http.StatusOK},
+		{"allowed gid", SocketOptions{AllowedUIDs: []int{uid + 1}, AllowedGIDs: []int{gid}}, http.StatusOK},
+		{"rejected", SocketOptions{AllowedUIDs: []int{uid + 1}, AllowedGIDs: []int{gid + 1}}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := serveSocket(t, tt.opts)
+			resp, err := client.Get("http://signer/accounts")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if resp.StatusCode != http.StatusOK {
+				return
+			}
+			var cred PeerCredentials
+			if err := json.NewDecoder(resp.Body).Decode(&cred); err != nil {
+				t.Fatal(err)
+			}
+			if want := (PeerCredentials{PID: os.Getpid(), UID: uid, GID: gid}); cred != want {
+				t.Fatalf("peer credentials = %+v, want %+v", cred, want)
+			}
+		})
+	}
+}
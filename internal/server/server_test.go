@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthorizePeer(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	socket := func(peer peerInfo) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/accounts", nil)
+		return r.WithContext(context.WithValue(r.Context(), peerKey{}, peer))
+	}
+	caller := peerInfo{cred: PeerCredentials{PID: 42, UID: 1000, GID: 100}}
+
+	tests := []struct {
+		name        string
+		allowedUIDs []int
+		allowedGIDs []int
+		req         *http.Request
+		wantStatus  int
+	}{
+		{"no restriction", nil, nil, socket(caller), http.StatusOK},
+		{"no restriction without credentials", nil, nil, socket(peerInfo{err: errors.New("unsupported")}), http.StatusOK},
+		{"allowed uid", []int{0, 1000}, nil, socket(caller), http.StatusOK},
+		{"allowed gid", []int{0}, []int{100}, socket(caller), http.StatusOK},
+		{"neither uid nor gid allowed", []int{0}, []int{0}, socket(caller), http.StatusForbidden},
+		{"only gids restricted", nil, []int{0}, socket(caller), http.StatusForbidden},
+		{"credentials unavailable", []int{1000}, nil, socket(peerInfo{err: errors.New("unsupported")}), http.StatusForbidden},
+		{"TCP request", []int{0}, []int{0}, httptest.NewRequest(http.MethodGet, "/accounts", nil), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(ok, Options{Socket: SocketOptions{AllowedUIDs: tt.allowedUIDs, AllowedGIDs: tt.allowedGIDs}})
+			rec := httptest.NewRecorder()
+			s.srv.Handler.ServeHTTP(rec, tt.req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPeerCredentialsFromContext(t *testing.T) {
+	if _, ok := PeerCredentialsFromContext(context.Background()); ok {
+		t.Fatal("credentials reported for a request without a socket connection")
+	}
+	ctx := context.WithValue(context.Background(), peerKey{}, peerInfo{err: errors.New("unsupported")})
+	if _, ok := PeerCredentialsFromContext(ctx); ok {
+		t.Fatal("credentials reported although reading them failed")
+	}
+	want := PeerCredentials{PID: 42, UID: 1000, GID: 100}
+	ctx = context.WithValue(context.Background(), peerKey{}, peerInfo{cred: want})
+	if got, ok := PeerCredentialsFromContext(ctx); !ok || got != want {
+		t.Fatalf("got %+v, %v, want %+v", got, ok, want)
+	}
+}
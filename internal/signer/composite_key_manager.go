@@ -0,0 +1,310 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrUnknownBackend is returned when a request names a backend that is not configured.
+var ErrUnknownBackend = errors.New("unknown key manager backend")
+
+// NamedKeyManager is a KeyManager registered under a name in a CompositeKeyManager.
+type NamedKeyManager struct {
+	Name       string
+	KeyManager KeyManager
+}
+
+// CompositeKeyManager combines several KeyManagers behind one. Accounts of all backends
+// are listed together and every operation on an account is routed to the backend that
+// holds its key. New keys go to the backend named in CreateKeyOptions, or the default one.
+type CompositeKeyManager struct {
+	backends       []NamedKeyManager
+	defaultBackend string
+}
+
+// NewCompositeKeyManager creates a CompositeKeyManager. An empty defaultBackend selects the
+// first backend. The CompositeKeyManager takes ownership of the backends and closes them in Close.
+func NewCompositeKeyManager(backends []NamedKeyManager, defaultBackend string) (*CompositeKeyManager, error) {
+	if len(backends) == 0 {
+		return nil, errors.New("composite key manager needs at least one backend")
+	}
+	seen := make(map[string]bool)
+	for _, b := range backends {
+		if b.Name == "" || seen[b.Name] {
+			return nil, fmt.Errorf("backend names must be unique and non-empty, got %q", b.Name)
+		}
+		seen[b.Name] = true
+	}
+	if defaultBackend == "" {
+		defaultBackend = backends[0].Name
+	}
+	if !seen[defaultBackend] {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBackend, defaultBackend)
+	}
+	return &CompositeKeyManager{backends: backends, defaultBackend: defaultBackend}, nil
+}
+
+// backend returns the backend called name, or the default backend if name is empty.
+func (km *CompositeKeyManager) backend(name string) (NamedKeyManager, error) {
+	if name == "" {
+		name = km.defaultBackend
+	}
+	for _, b := range km.backends {
+		if b.Name == name {
+			return b, nil
+		}
+	}
+	return NamedKeyManager{}, fmt.Errorf("%w: %s", ErrUnknownBackend, name)
+}
+
+// ownerOf returns the first backend holding a key for address. If no backend lists the
+// address, the backends implementing AccountFinder are asked to look it up, so a key
+// created in Vault by another replica is found before their next inventory refresh.
+func (km *CompositeKeyManager) ownerOf(ctx context.Context, address common.Address) (NamedKeyManager, error) {
+	for _, b := range km.backends {
+		if slices.Contains(b.KeyManager.GetAccounts(ctx), address) {
+			return b, nil
+		}
+	}
+	for _, b := range km.backends {
+		if finder, ok := b.KeyManager.(AccountFinder); ok && finder.HasAccount(ctx, address) {
+			return b, nil
+		}
+	}
+	return NamedKeyManager{}, fmt.Errorf("%w: %s", ErrAccountNotFound, address.Hex())
+}
+
+// HasAccount implements AccountFinder by looking address up in every backend.
+func (km *CompositeKeyManager) HasAccount(ctx context.Context, address common.Address) bool {
+	_, err := km.ownerOf(ctx, address)
+	return err == nil
+}
+
+// GetAccounts returns the accounts of all backends. An address held by several
+// backends is listed once and served by the first of them.
+func (km *CompositeKeyManager) GetAccounts(ctx context.Context) []common.Address {
+	seen := make(map[common.Address]bool)
+	var addresses []common.Address
+	for _, b := range km.backends {
+		for _, addr := range b.KeyManager.GetAccounts(ctx) {
+			if !seen[addr] {
+				seen[addr] = true
+				addresses = append(addresses, addr)
+			}
+		}
+	}
+	return addresses
+}
+
+// CreateKey creates a key in the backend named by opts.Backend, or the default backend.
+func (km *CompositeKeyManager) CreateKey(ctx context.Context, opts CreateKeyOptions) (common.Address, error) {
+	b, err := km.backend(opts.Backend)
+	if err != nil {
+		return common.Address{}, err
+	}
+	opts.Backend = ""
+	return b.KeyManager.CreateKey(ctx, opts)
+}
+
+// ImportKey imports a key into the backend named by opts.Backend, or the default backend.
+// Importing an address that any backend already holds fails.
+func (km *CompositeKeyManager) ImportKey(ctx context.Context, privateKey *ecdsa.PrivateKey, opts CreateKeyOptions) (common.Address, error) {
+	b, err := km.backend(opts.Backend)
+	if err != nil {
+		return common.Address{}, err
+	}
+	importer, ok := b.KeyManager.(Importer)
+	if !ok {
+		return common.Address{}, fmt.Errorf("import key into %s: %w", b.Name, ErrNotSupported)
+	}
+	if owner, err := km.ownerOf(ctx, crypto.PubkeyToAddress(privateKey.PublicKey)); err == nil {
+		return common.Address{}, fmt.Errorf("%w in backend %s: %s", ErrAccountExists, owner.Name, crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+	}
+	opts.Backend = ""
+	return importer.ImportKey(ctx, privateKey, opts)
+}
+
+// SignTx signs with the backend holding the key for address.
+func (km *CompositeKeyManager) SignTx(ctx context.Context, address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	b, err := km.ownerOf(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	return b.KeyManager.SignTx(ctx, address, tx, chainID)
+}
+
+// SignMessage signs with the backend holding the key for address.
+func (km *CompositeKeyManager) SignMessage(ctx context.Context, address common.Address, message []byte) ([]byte, error) {
+	b, err := km.ownerOf(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	return b.KeyManager.SignMessage(ctx, address, message)
+}
+
+// SignHash signs with the backend holding the key for address, if that backend supports it.
+func (km *CompositeKeyManager) SignHash(ctx context.Context, address common.Address, hash []byte) ([]byte, error) {
+	b, err := km.ownerOf(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	hashSigner, ok := b.KeyManager.(HashSigner)
+	if !ok {
+		return nil, fmt.Errorf("sign hash with %s: %w", b.Name, ErrNotSupported)
+	}
+	return hashSigner.SignHash(ctx, address, hash)
+}
+
+// PublicKey returns the public key from the backend holding the key for address.
+func (km *CompositeKeyManager) PublicKey(ctx context.Context, address common.Address) (*ecdsa.PublicKey, error) {
+	b, err := km.ownerOf(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := b.KeyManager.(PublicKeyProvider)
+	if !ok {
+		return nil, fmt.Errorf("public key from %s: %w", b.Name, ErrNotSupported)
+	}
+	return provider.PublicKey(ctx, address)
+}
+
+// ExportKey exports the key from the backend holding it, if that backend supports export.
+func (km *CompositeKeyManager) ExportKey(ctx context.Context, address common.Address, password string) ([]byte, error) {
+	b, err := km.ownerOf(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	exporter, ok := b.KeyManager.(Exporter)
+	if !ok {
+		return nil, fmt.Errorf("export key from %s: %w", b.Name, ErrNotSupported)
+	}
+	return exporter.ExportKey(ctx, address, password)
+}
+
+// DeleteKey deletes the key from the backend holding it, if that backend supports deletion.
+func (km *CompositeKeyManager) DeleteKey(ctx context.Context, address common.Address) error {
+	b, err := km.ownerOf(ctx, address)
+	if err != nil {
+		return err
+	}
+	deleter, ok := b.KeyManager.(Deleter)
+	if !ok {
+		return fmt.Errorf("delete key from %s: %w", b.Name, ErrNotSupported)
+	}
+	return deleter.DeleteKey(ctx, address)
+}
+
+// ArchiveKey archives the key in the backend holding it, if that backend supports archiving.
+func (km *CompositeKeyManager) ArchiveKey(ctx context.Context, address common.Address) error {
+	b, err := km.ownerOf(ctx, address)
+	if err != nil {
+		return err
+	}
+	archiver, ok := b.KeyManager.(Archiver)
+	if !ok {
+		return fmt.Errorf("archive key in %s: %w", b.Name, ErrNotSupported)
+	}
+	return archiver.ArchiveKey(ctx, address)
+}
+
+// Unlock unlocks the key in the backend holding it, if that backend locks keys.
+func (km *CompositeKeyManager) Unlock(ctx context.Context, address common.Address, password string, ttl time.Duration) (time.Time, error) {
+	b, err := km.ownerOf(ctx, address)
+	if err != nil {
+		return time.Time{}, err
+	}
+	unlocker, ok := b.KeyManager.(Unlocker)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unlock key in %s: %w", b.Name, ErrNotSupported)
+	}
+	return unlocker.Unlock(ctx, address, password, ttl)
+}
+
+// Lock locks the key in the backend holding it, if that backend locks keys.
+func (km *CompositeKeyManager) Lock(ctx context.Context, address common.Address) error {
+	b, err := km.ownerOf(ctx, address)
+	if err != nil {
+		return err
+	}
+	unlocker, ok := b.KeyManager.(Unlocker)
+	if !ok {
+		return fmt.Errorf("lock key in %s: %w", b.Name, ErrNotSupported)
+	}
+	return unlocker.Lock(ctx, address)
+}
+
+// RotatePassword rotates the password of the only backend that supports it. With several
+// such backends the request is ambiguous and refused.
+func (km *CompositeKeyManager) RotatePassword(ctx context.Context, current, next string, scrypt *ScryptParams) (int, error) {
+	var rotators []PasswordRotator
+	for _, b := range km.backends {
+		if r, ok := b.KeyManager.(PasswordRotator); ok {
+			rotators = append(rotators, r)
+		}
+	}
+	if len(rotators) != 1 {
+		return 0, fmt.Errorf("rotate password: %d backends hold password-encrypted keys: %w", len(rotators), ErrNotSupported)
+	}
+	return rotators[0].RotatePassword(ctx, current, next, scrypt)
+}
+
+// KeyReference returns the name of the backend holding the key for address, together
+// with that backend's reference for the key.
+func (km *CompositeKeyManager) KeyReference(address common.Address) (string, string, error) {
+	b, err := km.ownerOf(context.Background(), address)
+	if err != nil {
+		return "", "", err
+	}
+	ref := ""
+	if r, ok := b.KeyManager.(KeyReferencer); ok {
+		if _, ref, err = r.KeyReference(address); err != nil {
+			return "", "", err
+		}
+	}
+	return b.Name, ref, nil
+}
+
+// Reload reloads every backend that supports it.
+func (km *CompositeKeyManager) Reload(ctx context.Context) error {
+	var errs []error
+	for _, b := range km.backends {
+		if r, ok := b.KeyManager.(Reloader); ok {
+			if err := r.Reload(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HealthCheck reports the components of every backend, prefixed with the backend name.
+func (km *CompositeKeyManager) HealthCheck(ctx context.Context) []ComponentHealth {
+	var components []ComponentHealth
+	for _, b := range km.backends {
+		for _, c := range b.KeyManager.HealthCheck(ctx) {
+			c.Name = b.Name + "/" + c.Name
+			components = append(components, c)
+		}
+	}
+	return components
+}
+
+// Close closes every backend.
+func (km *CompositeKeyManager) Close() error {
+	var errs []error
+	for _, b := range km.backends {
+		if err := b.KeyManager.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,226 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/xueqianLu/ethsigner/internal/store"
+)
+
+func openTestStore(t *testing.T) *store.Store {
+	t.Helper()
+	st, err := store.Open(filepath.Join(t.TempDir(), "accounts.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+func TestCompositeFindsKeyAddedToVault(t *testing.T) {
+	f := newFakeVault(t)
+	vault := newTestVaultKeyManager(t, f)
+	km, err := NewCompositeKeyManager([]NamedKeyManager{
+		{Name: "local", KeyManager: newTestLocalKeyManager(t)},
+		{Name: "vault", KeyManager: vault},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another replica creates a key after this one read the Vault inventory.
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	f.update(func(f *fakeVault) { f.keys["other-replica"] = &key.PublicKey })
+	// Let the lookup of an unknown address refresh the inventory right away.
+	vault.refreshMu.Lock()
+	vault.lastRefresh = time.Now().Add(-vaultMissRefreshInterval)
+	vault.refreshMu.Unlock()
+
+	backend, ref, err := km.KeyReference(address)
+	if err != nil {
+		t.Fatalf("KeyReference of a key added to Vault: %v", err)
+	}
+	if backend != "vault" || ref != "transit/keys/other-replica" {
+		t.Errorf("KeyReference = %s, %s; want vault, transit/keys/other-replica", backend, ref)
+	}
+
+	unknown, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := km.KeyReference(crypto.PubkeyToAddress(unknown.PublicKey)); !errors.Is(err, ErrAccountNotFound) {
+		t.Errorf("KeyReference of an unknown address: got %v, want ErrAccountNotFound", err)
+	}
+}
+
+func TestSignerDisablesKeyAddedToVault(t *testing.T) {
+	f := newFakeVault(t)
+	vault := newTestVaultKeyManager(t, f)
+	composite, err := NewCompositeKeyManager([]NamedKeyManager{
+		{Name: "local", KeyManager: newTestLocalKeyManager(t)},
+		{Name: "vault", KeyManager: vault},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, km := range map[string]KeyManager{"vault": vault, "composite": composite} {
+		t.Run(name, func(t *testing.T) {
+			// Another replica creates a key after this one read the Vault inventory.
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			address := crypto.PubkeyToAddress(key.PublicKey)
+			f.update(func(f *fakeVault) { f.keys["other-replica-"+name] = &key.PublicKey })
+			vault.refreshMu.Lock()
+			vault.lastRefresh = time.Now().Add(-vaultMissRefreshInterval)
+			vault.refreshMu.Unlock()
+
+			s := NewSigner(km, openTestStore(t), Timeouts{})
+			acc, err := s.DisableAccount(t.Context(), address)
+			if err != nil {
+				t.Fatalf("DisableAccount of a key added to Vault: %v", err)
+			}
+			if acc.State != store.StateDisabled {
+				t.Fatalf("state = %s, want disabled", acc.State)
+			}
+		})
+	}
+}
+
+func TestCompositeRouting(t *testing.T) {
+	first, second := newTestLocalKeyManager(t), newTestLocalKeyManager(t)
+	km, err := NewCompositeKeyManager([]NamedKeyManager{
+		{Name: "first", KeyManager: first},
+		{Name: "second", KeyManager: second},
+	}, "second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := t.Context()
+
+	// New keys go to the default backend unless another one is named.
+	inSecond, err := km.CreateKey(ctx, CreateKeyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inFirst, err := km.CreateKey(ctx, CreateKeyOptions{Backend: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(first.GetAccounts(ctx), []common.Address{inFirst}) || !slices.Equal(second.GetAccounts(ctx), []common.Address{inSecond}) {
+		t.Fatalf("backends hold %v and %v, want %s and %s", first.GetAccounts(ctx), second.GetAccounts(ctx), inFirst, inSecond)
+	}
+	if _, err := km.CreateKey(ctx, CreateKeyOptions{Backend: "third"}); !errors.Is(err, ErrUnknownBackend) {
+		t.Fatalf("CreateKey in an unknown backend: got %v, want ErrUnknownBackend", err)
+	}
+
+	// Operations on an account go to the backend holding its key.
+	for address, want := range map[common.Address]string{inFirst: "first", inSecond: "second"} {
+		if backend, _, err := km.KeyReference(address); err != nil || backend != want {
+			t.Errorf("KeyReference(%s) = %s, %v; want %s", address, backend, err, want)
+		}
+		if _, err := km.SignMessage(ctx, address, []byte("hello")); err != nil {
+			t.Errorf("SignMessage with the key in %s: %v", want, err)
+		}
+	}
+	unknown, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := km.SignMessage(ctx, crypto.PubkeyToAddress(unknown.PublicKey), []byte("hello")); !errors.Is(err, ErrAccountNotFound) {
+		t.Fatalf("SignMessage with an unknown address: got %v, want ErrAccountNotFound", err)
+	}
+
+	// An address held by another backend cannot be imported again.
+	keyJson, err := km.ExportKey(ctx, inFirst, "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := keystore.DecryptKey(keyJson, "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := km.ImportKey(ctx, key.PrivateKey, CreateKeyOptions{Backend: "second"}); !errors.Is(err, ErrAccountExists) {
+		t.Fatalf("ImportKey of an address held by another backend: got %v, want ErrAccountExists", err)
+	}
+	// Held by both backends anyway, it is listed once and served by the first.
+	if _, err := second.ImportKey(ctx, key.PrivateKey, CreateKeyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if accounts := km.GetAccounts(ctx); len(accounts) != 2 {
+		t.Fatalf("GetAccounts = %v, want each address once", accounts)
+	}
+	if backend, _, _ := km.KeyReference(inFirst); backend != "first" {
+		t.Fatalf("address held by both backends is served by %s, want first", backend)
+	}
+
+	if err := km.DeleteKey(ctx, inSecond); err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(second.GetAccounts(ctx), inSecond) {
+		t.Fatal("DeleteKey left the key in its backend")
+	}
+
+	for _, c := range km.HealthCheck(ctx) {
+		if !strings.HasPrefix(c.Name, "first/") && !strings.HasPrefix(c.Name, "second/") {
+			t.Errorf("component %q is not prefixed with its backend", c.Name)
+		}
+	}
+}
+
+func TestCompositeRotatePassword(t *testing.T) {
+	light := &ScryptParams{N: keystore.LightScryptN, P: keystore.LightScryptP}
+
+	// With two local backends it is unclear whose password changes.
+	ambiguous, err := NewCompositeKeyManager([]NamedKeyManager{
+		{Name: "first", KeyManager: newTestLocalKeyManager(t)},
+		{Name: "second", KeyManager: newTestLocalKeyManager(t)},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ambiguous.RotatePassword(t.Context(), "password", "new-password", light); !errors.Is(err, ErrNotSupported) {
+		t.Fatalf("RotatePassword with two local backends: got %v, want ErrNotSupported", err)
+	}
+
+	local := newTestLocalKeyManager(t)
+	address, err := local.CreateKey(t.Context(), CreateKeyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	km, err := NewCompositeKeyManager([]NamedKeyManager{
+		{Name: "vault", KeyManager: newTestVaultKeyManager(t, newFakeVault(t))},
+		{Name: "local", KeyManager: local},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := km.RotatePassword(t.Context(), "password", "new-password", light)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated != 1 {
+		t.Fatalf("rotated %d files, want 1", rotated)
+	}
+	if _, err := local.ExportKey(t.Context(), address, "export"); err != nil {
+		t.Fatalf("local key unusable after rotation: %v", err)
+	}
+	if _, err := local.RotatePassword(t.Context(), "password", "other", light); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("the local backend's password was not changed: got %v", err)
+	}
+}
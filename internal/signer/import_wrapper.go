@@ -0,0 +1,71 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/xueqianLu/ethsigner/pkg/keywrap"
+)
+
+// importWrappingKeyBits matches the size of Vault's transit wrapping key.
+const importWrappingKeyBits = 4096
+
+// ImportWrapper holds the RSA key clients use to seal raw private keys for import,
+// so the key material is never sent in plaintext. The key is generated on first use
+// and only lives in memory; envelopes must be sealed against the current process.
+type ImportWrapper struct {
+	once sync.Once
+	key  *rsa.PrivateKey
+	err  error
+}
+
+// NewImportWrapper creates a new ImportWrapper.
+func NewImportWrapper() *ImportWrapper {
+	return &ImportWrapper{}
+}
+
+func (w *ImportWrapper) privateKey() (*rsa.PrivateKey, error) {
+	w.once.Do(func() {
+		w.key, w.err = rsa.GenerateKey(rand.Reader, importWrappingKeyBits)
+	})
+	return w.key, w.err
+}
+
+// PublicKeyPEM returns the PEM-encoded public wrapping key.
+func (w *ImportWrapper) PublicKeyPEM() (string, error) {
+	key, err := w.privateKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate wrapping key: %w", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode wrapping key: %w", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// Open unseals an envelope produced by keywrap.Seal against the public wrapping key.
+// The sealed material must be a raw 32-byte secp256k1 private key.
+func (w *ImportWrapper) Open(envelope []byte) (*ecdsa.PrivateKey, error) {
+	key, err := w.privateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate wrapping key: %w", err)
+	}
+	raw, err := keywrap.Open(key, envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open key envelope: %w", err)
+	}
+	defer clear(raw)
+
+	privateKey, err := crypto.ToECDSA(raw)
+	if err != nil {
+		return nil, fmt.Errorf("envelope does not contain a valid private key: %w", err)
+	}
+	return privateKey, nil
+}
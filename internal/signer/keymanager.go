@@ -1,25 +1,150 @@
 package signer
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"math/big"
 )
 
+// ErrNotSupported is returned when the configured KeyManager does not implement an optional capability.
+var ErrNotSupported = errors.New("operation not supported by key manager")
+
+// ErrAccountNotFound is returned when an address is not managed by the KeyManager.
+var ErrAccountNotFound = errors.New("account not found")
+
+// ErrAccountExists is returned when importing a key whose address is already managed.
+var ErrAccountExists = errors.New("account already exists")
+
+// ErrAccountLocked is returned when signing with an account that must be unlocked first.
+var ErrAccountLocked = errors.New("account locked")
+
+// CreateKeyOptions carries the optional parameters of CreateKey and ImportKey.
+// Backends return ErrNotSupported for options they cannot honour.
+type CreateKeyOptions struct {
+	// Password encrypts the new key when the backend uses per-account passwords.
+	Password string
+	// Backend names the backend of a CompositeKeyManager that receives the key.
+	// Empty selects the default backend.
+	Backend string
+	// Name is the backend's name for the key, e.g. the Vault transit key name.
+	// Empty lets the backend choose one.
+	Name string
+}
+
 // KeyManager defines the interface for managing cryptographic keys and performing signing operations.
 // It abstracts the underlying key storage, which can be a local keystore or a remote service like Vault.
+//
+// Every method takes a context.Context. Implementations backed by a remote service must abort
+// outstanding requests once the context is cancelled or its deadline expires.
 type KeyManager interface {
 	// GetAccounts returns a list of all Ethereum addresses managed by the KeyManager.
-	GetAccounts() []common.Address
+	GetAccounts(ctx context.Context) []common.Address
 
 	// CreateKey generates a new key pair and returns the corresponding Ethereum address.
 	// The key is stored in the underlying storage backend.
-	CreateKey() (common.Address, error)
+	CreateKey(ctx context.Context, opts CreateKeyOptions) (common.Address, error)
 
 	// SignTx signs a given Ethereum transaction with the key corresponding to the specified address.
 	// It requires the chain ID for EIP-155 replay protection.
-	SignTx(address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	SignTx(ctx context.Context, address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
 
 	// SignMessage signs an arbitrary message with the key for the given address, following the EIP-191 standard.
-	SignMessage(address common.Address, message []byte) ([]byte, error)
+	SignMessage(ctx context.Context, address common.Address, message []byte) ([]byte, error)
+
+	// HealthCheck probes the backend and reports the status of each of its components,
+	// e.g. whether Vault is unsealed or how many local keys could be decrypted.
+	HealthCheck(ctx context.Context) []ComponentHealth
+
+	// Close releases any resources held by the KeyManager, wiping in-memory key material.
+	// The KeyManager must not be used after Close returns.
+	Close() error
+}
+
+// ComponentHealth describes the health of a single component backing a KeyManager.
+type ComponentHealth struct {
+	// Name identifies the component, e.g. "vault_seal" or "local_keystore".
+	Name string
+	// Healthy reports whether the component can currently serve signing requests.
+	Healthy bool
+	// Message explains a failure; it is empty for healthy components.
+	Message string
+	// Details carries component-specific facts such as key counts or token TTLs.
+	Details map[string]interface{}
+}
+
+// Reloader is implemented by KeyManagers that can refresh their key inventory at runtime,
+// e.g. after keystore files were added to or removed from disk.
+type Reloader interface {
+	// Reload re-reads the backing storage and atomically replaces the set of managed keys.
+	Reload(ctx context.Context) error
+}
+
+// AccountFinder is implemented by KeyManagers whose GetAccounts may lag behind the
+// backing storage, e.g. because another replica created a key since it was last read.
+type AccountFinder interface {
+	// HasAccount reports whether the backend holds a key for address, looking beyond its
+	// cached inventory if needed.
+	HasAccount(ctx context.Context, address common.Address) bool
+}
+
+// Importer is implemented by KeyManagers that can take over an existing private key.
+type Importer interface {
+	// ImportKey stores the given private key in the backend and returns its address.
+	// It fails if the address is already managed.
+	ImportKey(ctx context.Context, privateKey *ecdsa.PrivateKey, opts CreateKeyOptions) (common.Address, error)
+}
+
+// Exporter is implemented by KeyManagers whose key material may leave the backend.
+type Exporter interface {
+	// ExportKey returns the key for address as a V3 keystore JSON encrypted with password.
+	ExportKey(ctx context.Context, address common.Address, password string) ([]byte, error)
+}
+
+// Deleter is implemented by KeyManagers that can permanently remove a key.
+type Deleter interface {
+	// DeleteKey destroys the key material for address. This cannot be undone.
+	DeleteKey(ctx context.Context, address common.Address) error
+}
+
+// Archiver is implemented by KeyManagers that can retire key material without destroying it.
+type Archiver interface {
+	// ArchiveKey stops the backend from using the key for address and moves it out of the
+	// active key set, e.g. into an archive directory or by allowing its deletion in Vault.
+	ArchiveKey(ctx context.Context, address common.Address) error
+}
+
+// KeyReferencer is implemented by KeyManagers that can tell where a key is stored.
+type KeyReferencer interface {
+	// KeyReference returns the backend type and a backend-specific reference for the key
+	// of address, e.g. ("local", "keys/0xAbC....json") or ("vault", "transit/keys/eth-key-1").
+	KeyReference(address common.Address) (backend, ref string, err error)
+}
+
+// Unlocker is implemented by KeyManagers that keep keys locked until they are unlocked
+// with a per-account password.
+type Unlocker interface {
+	// Unlock decrypts the key for address with password and keeps it usable for ttl,
+	// or a backend default when ttl is zero. It returns when the key will be locked again.
+	Unlock(ctx context.Context, address common.Address, password string, ttl time.Duration) (time.Time, error)
+	// Lock wipes the decrypted key for address from memory immediately.
+	Lock(ctx context.Context, address common.Address) error
+}
+
+// HashSigner is implemented by KeyManagers that can sign a precomputed 32-byte hash.
+type HashSigner interface {
+	// SignHash signs hash as is, without the EIP-191 prefix, and returns the 65-byte
+	// [R || S || V] signature with V being 0 or 1.
+	SignHash(ctx context.Context, address common.Address, hash []byte) ([]byte, error)
+}
+
+// PublicKeyProvider is implemented by KeyManagers that can return the public key of an account.
+type PublicKeyProvider interface {
+	// PublicKey returns the secp256k1 public key of address. Backends that only learn the
+	// public key by decrypting the key return ErrAccountLocked for locked accounts.
+	PublicKey(ctx context.Context, address common.Address) (*ecdsa.PublicKey, error)
 }
@@ -0,0 +1,147 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/xueqianLu/ethsigner/internal/store"
+)
+
+var (
+	// ErrAccountDisabled is returned when signing with an account that has been disabled.
+	ErrAccountDisabled = errors.New("account is disabled")
+	// ErrAccountArchived is returned when using an account whose key material has been archived.
+	ErrAccountArchived = errors.New("account is archived")
+)
+
+// accountStates returns the recorded lifecycle state of every account with a record.
+// Accounts without a record are active.
+func (s *Signer) accountStates() (map[common.Address]string, error) {
+	records, err := s.accounts.ListAccounts()
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[common.Address]string, len(records))
+	for _, acc := range records {
+		states[acc.Address] = acc.State
+	}
+	return states, nil
+}
+
+// checkActive returns an error unless address may be used for signing.
+func (s *Signer) checkActive(address common.Address) error {
+	acc, found, err := s.accounts.GetAccount(address)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	switch acc.State {
+	case store.StateDisabled:
+		return fmt.Errorf("%w: %s", ErrAccountDisabled, address.Hex())
+	case store.StateArchived:
+		return fmt.Errorf("%w: %s", ErrAccountArchived, address.Hex())
+	}
+	return nil
+}
+
+// recordNewAccount stores an active record with metadata for a newly created or imported account.
+// A missing record also means active, so a failure here only costs the metadata.
+func (s *Signer) recordNewAccount(address common.Address, meta store.Metadata) {
+	_, err := s.accounts.UpdateAccount(address, func(acc *store.Account) error {
+		acc.State = store.StateActive
+		acc.Metadata = normalizeMetadata(meta)
+		acc.CreatedAt = time.Now().UTC()
+		acc.Backend, acc.KeyRef = s.keyReference(address)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Warning: failed to record account %s: %v", address.Hex(), err)
+	}
+}
+
+// isManaged reports whether the KeyManager holds a key for address. KeyManagers
+// implementing AccountFinder are asked to look up an address missing from GetAccounts,
+// so a key another replica created just now can be managed right away.
+func (s *Signer) isManaged(ctx context.Context, address common.Address) bool {
+	if slices.Contains(s.keyManager.GetAccounts(ctx), address) {
+		return true
+	}
+	finder, ok := s.keyManager.(AccountFinder)
+	return ok && finder.HasAccount(ctx, address)
+}
+
+// DisableAccount stops address from signing until it is enabled again. The key material is kept.
+func (s *Signer) DisableAccount(ctx context.Context, address common.Address) (store.Account, error) {
+	return s.setState(ctx, address, store.StateDisabled)
+}
+
+// EnableAccount allows a disabled account to sign again.
+func (s *Signer) EnableAccount(ctx context.Context, address common.Address) (store.Account, error) {
+	return s.setState(ctx, address, store.StateActive)
+}
+
+func (s *Signer) setState(ctx context.Context, address common.Address, state string) (store.Account, error) {
+	if err := s.begin(); err != nil {
+		return store.Account{}, err
+	}
+	defer s.end()
+	// Archived keys may no longer be held by the backend; report the archive, not a missing key.
+	if err := s.checkActive(address); errors.Is(err, ErrAccountArchived) {
+		return store.Account{}, err
+	}
+	if !s.isManaged(ctx, address) {
+		return store.Account{}, fmt.Errorf("%w: %s", ErrAccountNotFound, address.Hex())
+	}
+	acc, err := s.accounts.UpdateAccount(address, func(acc *store.Account) error {
+		if acc.State == store.StateArchived {
+			return fmt.Errorf("%w: %s", ErrAccountArchived, address.Hex())
+		}
+		acc.State = state
+		return nil
+	})
+	if err != nil {
+		return store.Account{}, err
+	}
+	log.Printf("Account %s is now %s", address.Hex(), state)
+	return acc, nil
+}
+
+// ArchiveAccount permanently retires address: the account is disabled first so it stops
+// signing immediately, then the backend archives the key material, and only then is the
+// account marked archived. If archiving fails the account stays disabled and can be retried.
+func (s *Signer) ArchiveAccount(ctx context.Context, address common.Address) (store.Account, error) {
+	if err := s.begin(); err != nil {
+		return store.Account{}, err
+	}
+	defer s.end()
+	archiver, ok := s.keyManager.(Archiver)
+	if !ok {
+		return store.Account{}, fmt.Errorf("archive key: %w", ErrNotSupported)
+	}
+	if _, err := s.DisableAccount(ctx, address); err != nil {
+		return store.Account{}, err
+	}
+
+	ctx, cancel := withTimeout(ctx, s.timeouts.Load().Create)
+	defer cancel()
+	if err := archiver.ArchiveKey(ctx, address); err != nil {
+		return store.Account{}, fmt.Errorf("failed to archive key material: %w", err)
+	}
+
+	acc, err := s.accounts.UpdateAccount(address, func(acc *store.Account) error {
+		acc.State = store.StateArchived
+		return nil
+	})
+	if err != nil {
+		return store.Account{}, err
+	}
+	log.Printf("Account %s archived", address.Hex())
+	return acc, nil
+}
@@ -1,143 +1,580 @@
 package signer
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"fmt"
 	"log"
 	"math/big"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
 )
 
 // LocalKeyManager manages keys stored locally on disk.
+//
+// By default every keystore file is encrypted with the shared signer password and all keys
+// are decrypted when loaded. With an UnlockPolicy each account has its own password: keys
+// are loaded locked and only held in memory between Unlock and the end of the unlock TTL.
 type LocalKeyManager struct {
-	keyDir   string
-	password string
-	keys     map[common.Address]*ecdsa.PrivateKey
-	mu       sync.RWMutex
+	keyDir     string
+	archiveDir string
+	password   string                               // shared password; unused with per-account passwords
+	unlock     *UnlockPolicy                        // nil unless per-account passwords are enabled
+	scrypt     ScryptParams                         // cost parameters for newly written keystore files
+	keys       map[common.Address]*ecdsa.PrivateKey // decrypted keys usable for signing
+	unlocked   map[common.Address]*time.Timer       // re-lock timers of unlocked keys
+	files      map[string]keyFile                   // keystore file name -> what was loaded from it
+	failed     int                                  // keystore files that could not be read or decrypted in the last scan
+	mu         sync.RWMutex
+
+	// writeMu serializes operations that modify the key directory or the set of decrypted
+	// keys, and rescans of the directory, so a reload never races a key being written,
+	// unlocked or locked. It is taken before mu.
+	writeMu sync.Mutex
+}
+
+// keyFile records the state of a keystore file at the time it was decrypted,
+// so rescans can skip files that have not changed.
+type keyFile struct {
+	address common.Address
+	size    int64
+	modTime time.Time
+}
+
+// UnlockPolicy enables per-account passwords for LocalKeyManager and bounds how long an
+// unlocked key stays in memory.
+type UnlockPolicy struct {
+	DefaultTTL time.Duration // used when Unlock is called without a TTL
+	MaxTTL     time.Duration // longer TTLs are shortened to this; zero means no limit
 }
 
 // NewLocalKeyManager creates a new LocalKeyManager and loads existing keys from disk.
-func NewLocalKeyManager(keyDir, password string) (*LocalKeyManager, error) {
+// Archived keystore files are moved to archiveDir, which defaults to an "archive"
+// directory inside keyDir. A non-nil unlock enables per-account passwords, in which
+// case password is ignored.
+func NewLocalKeyManager(keyDir, archiveDir, password string, unlock *UnlockPolicy) (*LocalKeyManager, error) {
 	if err := os.MkdirAll(keyDir, 0700); err != nil {
 		return nil, fmt.Errorf("failed to create key directory: %w", err)
 	}
+	if archiveDir == "" {
+		archiveDir = filepath.Join(keyDir, "archive")
+	}
 
 	km := &LocalKeyManager{
-		keyDir:   keyDir,
-		password: password,
-		keys:     make(map[common.Address]*ecdsa.PrivateKey),
+		keyDir:     keyDir,
+		archiveDir: archiveDir,
+		password:   password,
+		unlock:     unlock,
+		scrypt:     ScryptParams{N: keystore.StandardScryptN, P: keystore.StandardScryptP},
+		keys:       make(map[common.Address]*ecdsa.PrivateKey),
+		unlocked:   make(map[common.Address]*time.Timer),
+		files:      make(map[string]keyFile),
 	}
 
-	files, err := os.ReadDir(keyDir)
+	if err := km.Reload(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return km, nil
+}
+
+// Reload rescans the key directory, loading new keystore files and dropping keys whose
+// files were removed. Unchanged files are not decrypted again. The new key set replaces
+// the old one atomically, so in-flight signing requests are unaffected. With per-account
+// passwords files are only parsed for their address; unlocked keys stay unlocked.
+func (km *LocalKeyManager) Reload(ctx context.Context) error {
+	km.writeMu.Lock()
+	defer km.writeMu.Unlock()
+
+	if km.unlock == nil {
+		km.recoverRotation()
+	}
+	entries, err := os.ReadDir(km.keyDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read key directory: %w", err)
+		return fmt.Errorf("failed to read key directory: %w", err)
 	}
 
-	for _, file := range files {
-		if !file.IsDir() {
-			filePath := filepath.Join(keyDir, file.Name())
-			keyJson, err := os.ReadFile(filePath)
+	km.mu.RLock()
+	oldKeys := km.keys
+	oldFiles := km.files
+	km.mu.RUnlock()
+	known := addressesOf(oldFiles)
+
+	keys := make(map[common.Address]*ecdsa.PrivateKey)
+	files := make(map[string]keyFile)
+	failed := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if isRotationFile(entry.Name()) {
+			log.Printf("Warning: ignoring %s left over from an interrupted password rotation", entry.Name())
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			log.Printf("Warning: failed to stat key file %s: %v", entry.Name(), err)
+			continue
+		}
+		if prev, ok := oldFiles[entry.Name()]; ok && prev.size == info.Size() && prev.modTime.Equal(info.ModTime()) {
+			if key, ok := oldKeys[prev.address]; ok || km.unlock != nil {
+				if ok {
+					keys[prev.address] = key
+				}
+				files[entry.Name()] = prev
+				continue
+			}
+		}
+
+		keyJson, err := os.ReadFile(filepath.Join(km.keyDir, entry.Name()))
+		if err != nil {
+			log.Printf("Warning: failed to read key file %s: %v", entry.Name(), err)
+			failed++
+			continue
+		}
+		var address common.Address
+		if km.unlock != nil {
+			address, err = keystoreAddress(keyJson)
 			if err != nil {
-				log.Printf("Warning: failed to read key file %s: %v", file.Name(), err)
+				log.Printf("Warning: failed to parse key file %s: %v", entry.Name(), err)
+				failed++
 				continue
 			}
-			key, err := keystore.DecryptKey(keyJson, password)
+			if key, ok := oldKeys[address]; ok {
+				keys[address] = key
+			}
+		} else {
+			key, err := keystore.DecryptKey(keyJson, km.password)
 			if err != nil {
-				log.Printf("Warning: failed to decrypt key file %s: %v", file.Name(), err)
+				log.Printf("Warning: failed to decrypt key file %s: %v", entry.Name(), err)
+				failed++
 				continue
 			}
-			address := key.Address
-			km.keys[address] = key.PrivateKey
+			address = key.Address
+			keys[address] = key.PrivateKey
+		}
+		files[entry.Name()] = keyFile{address: address, size: info.Size(), modTime: info.ModTime()}
+		if !known[address] {
 			log.Printf("Loaded local key for address %s", address.Hex())
 		}
 	}
 
-	return km, nil
+	current := addressesOf(files)
+	for addr := range known {
+		if !current[addr] {
+			log.Printf("Unloaded local key for address %s", addr.Hex())
+		}
+	}
+
+	km.mu.Lock()
+	// Wipe keys that were dropped or replaced by a re-decrypted copy. Signing holds the
+	// read lock while using a key, so none of them is in use.
+	for addr, key := range oldKeys {
+		if keys[addr] == key {
+			continue
+		}
+		if _, kept := keys[addr]; !kept {
+			km.stopRelock(addr)
+		}
+		zeroKey(key)
+	}
+	km.keys = keys
+	km.files = files
+	km.failed = failed
+	km.mu.Unlock()
+
+	return nil
 }
 
-// CreateKey generates a new key pair and saves it to disk (encrypted).
-func (km *LocalKeyManager) CreateKey() (common.Address, error) {
+// CreateKey generates a new key pair and saves it to disk (encrypted). With per-account
+// passwords the key is encrypted with opts.Password and stored locked.
+func (km *LocalKeyManager) CreateKey(ctx context.Context, opts CreateKeyOptions) (common.Address, error) {
+	if err := ctx.Err(); err != nil {
+		return common.Address{}, err
+	}
+	if err := km.checkCreateOptions(opts); err != nil {
+		return common.Address{}, err
+	}
+
 	privateKey, err := crypto.GenerateKey()
 	if err != nil {
 		return common.Address{}, fmt.Errorf("failed to generate private key: %w", err)
 	}
+	address, err := km.storeKey(ctx, privateKey, opts.Password)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if km.unlock != nil {
+		zeroKey(privateKey)
+	}
+
+	log.Printf("Created and saved encrypted local key for address %s", address.Hex())
+	return address, nil
+}
+
+// ImportKey encrypts an existing private key with the signer password, or opts.Password
+// with per-account passwords, and saves it to disk.
+func (km *LocalKeyManager) ImportKey(ctx context.Context, privateKey *ecdsa.PrivateKey, opts CreateKeyOptions) (common.Address, error) {
+	if err := ctx.Err(); err != nil {
+		return common.Address{}, err
+	}
+	if err := km.checkCreateOptions(opts); err != nil {
+		return common.Address{}, err
+	}
+
+	// Fail fast before encrypting; storeKey checks again while holding writeMu.
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	if len(km.filesFor(address)) > 0 {
+		return common.Address{}, fmt.Errorf("%w: %s", ErrAccountExists, address.Hex())
+	}
+
+	if _, err := km.storeKey(ctx, privateKey, opts.Password); err != nil {
+		return common.Address{}, err
+	}
+
+	log.Printf("Imported local key for address %s", address.Hex())
+	return address, nil
+}
+
+// checkCreateOptions checks that a per-account password was given if and only if
+// per-account passwords are enabled. Keystore files are named after the address, so
+// caller-chosen key names are refused.
+func (km *LocalKeyManager) checkCreateOptions(opts CreateKeyOptions) error {
+	if opts.Name != "" {
+		return fmt.Errorf("key names: %w", ErrNotSupported)
+	}
+	if km.unlock != nil && opts.Password == "" {
+		return ErrPasswordRequired
+	}
+	if km.unlock == nil && opts.Password != "" {
+		return fmt.Errorf("per-account passwords are not enabled: %w", ErrNotSupported)
+	}
+	return nil
+}
+
+// storeKey encrypts privateKey, writes it to the key directory and records it. With the
+// shared password the key becomes available for signing; with per-account passwords it is
+// encrypted with accountPassword and stays locked. It fails with ErrAccountExists if a
+// keystore file already holds the key.
+func (km *LocalKeyManager) storeKey(ctx context.Context, privateKey *ecdsa.PrivateKey, accountPassword string) (common.Address, error) {
 	address := crypto.PubkeyToAddress(privateKey.PublicKey)
 
 	keyStruct := &keystore.Key{
 		Address:    address,
 		PrivateKey: privateKey,
 	}
-	keyJson, err := keystore.EncryptKey(keyStruct, km.password, keystore.StandardScryptN, keystore.StandardScryptP)
+	km.mu.RLock()
+	password, scrypt := km.password, km.scrypt
+	km.mu.RUnlock()
+	if km.unlock != nil {
+		password = accountPassword
+	}
+	keyJson, err := keystore.EncryptKey(keyStruct, password, scrypt.N, scrypt.P)
 	if err != nil {
 		return common.Address{}, fmt.Errorf("failed to encrypt private key: %w", err)
 	}
-	filePath := filepath.Join(km.keyDir, address.Hex()+".json")
+	// Encryption is slow; don't persist a key nobody is waiting for anymore.
+	if err := ctx.Err(); err != nil {
+		return common.Address{}, err
+	}
+
+	km.writeMu.Lock()
+	defer km.writeMu.Unlock()
+
+	// Checked under writeMu, so that two imports of the same key cannot both write it.
+	if len(km.filesFor(address)) > 0 {
+		return common.Address{}, fmt.Errorf("%w: %s", ErrAccountExists, address.Hex())
+	}
+
+	// The shared password may have been rotated while encrypting outside the lock.
+	if km.unlock == nil && km.password != password {
+		keyJson, err = keystore.EncryptKey(keyStruct, km.password, km.scrypt.N, km.scrypt.P)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("failed to encrypt private key: %w", err)
+		}
+	}
+
+	fileName := address.Hex() + ".json"
+	filePath := filepath.Join(km.keyDir, fileName)
 	if err := os.WriteFile(filePath, keyJson, 0600); err != nil {
 		return common.Address{}, fmt.Errorf("failed to save encrypted key: %w", err)
 	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to stat saved key: %w", err)
+	}
 
 	km.mu.Lock()
 	defer km.mu.Unlock()
-	km.keys[address] = privateKey
+	if km.unlock == nil {
+		km.keys[address] = privateKey
+	}
+	km.files[fileName] = keyFile{address: address, size: info.Size(), modTime: info.ModTime()}
 
-	log.Printf("Created and saved encrypted local key for address %s", address.Hex())
 	return address, nil
 }
 
+// ExportKey re-encrypts the key for address with password and returns the keystore JSON.
+func (km *LocalKeyManager) ExportKey(ctx context.Context, address common.Address, password string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var keyJson []byte
+	err := km.withKey(address, func(privateKey *ecdsa.PrivateKey) error {
+		keyStruct := &keystore.Key{
+			Id:         uuid.New(),
+			Address:    address,
+			PrivateKey: privateKey,
+		}
+		var err error
+		keyJson, err = keystore.EncryptKey(keyStruct, password, keystore.StandardScryptN, keystore.StandardScryptP)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt private key: %w", err)
+		}
+		return nil
+	})
+	return keyJson, err
+}
+
+// DeleteKey removes every keystore file holding the key for address and forgets the key.
+func (km *LocalKeyManager) DeleteKey(ctx context.Context, address common.Address) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	km.writeMu.Lock()
+	defer km.writeMu.Unlock()
+
+	fileNames := km.filesFor(address)
+	if len(fileNames) == 0 {
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, address.Hex())
+	}
+
+	for _, name := range fileNames {
+		if err := os.Remove(filepath.Join(km.keyDir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove key file %s: %w", name, err)
+		}
+	}
+
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	km.forgetLocked(address)
+	for _, name := range fileNames {
+		delete(km.files, name)
+	}
+
+	log.Printf("Deleted local key for address %s", address.Hex())
+	return nil
+}
+
+// ArchiveKey moves every keystore file holding the key for address into the archive
+// directory and forgets the key. The files stay encrypted and can be restored by moving
+// them back into the key directory.
+func (km *LocalKeyManager) ArchiveKey(ctx context.Context, address common.Address) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	km.writeMu.Lock()
+	defer km.writeMu.Unlock()
+
+	fileNames := km.filesFor(address)
+	if len(fileNames) == 0 {
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, address.Hex())
+	}
+	if err := os.MkdirAll(km.archiveDir, 0700); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	for _, name := range fileNames {
+		dest := filepath.Join(km.archiveDir, name)
+		if _, err := os.Stat(dest); err == nil {
+			dest = fmt.Sprintf("%s.%d", dest, time.Now().Unix())
+		}
+		if err := os.Rename(filepath.Join(km.keyDir, name), dest); err != nil {
+			return fmt.Errorf("failed to archive key file %s: %w", name, err)
+		}
+	}
+
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	km.forgetLocked(address)
+	for _, name := range fileNames {
+		delete(km.files, name)
+	}
+
+	log.Printf("Archived local key for address %s to %s", address.Hex(), km.archiveDir)
+	return nil
+}
+
+// KeyReference returns the keystore file holding the key for address.
+func (km *LocalKeyManager) KeyReference(address common.Address) (string, string, error) {
+	fileNames := km.filesFor(address)
+	if len(fileNames) == 0 {
+		return "", "", fmt.Errorf("%w: %s", ErrAccountNotFound, address.Hex())
+	}
+	slices.Sort(fileNames)
+	return "local", filepath.Join(km.keyDir, fileNames[0]), nil
+}
+
+// filesFor returns the names of the keystore files holding the key for address.
+func (km *LocalKeyManager) filesFor(address common.Address) []string {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	return km.filesForLocked(address)
+}
+
+// filesForLocked is filesFor for callers already holding km.mu.
+func (km *LocalKeyManager) filesForLocked(address common.Address) []string {
+	var fileNames []string
+	for name, f := range km.files {
+		if f.address == address {
+			fileNames = append(fileNames, name)
+		}
+	}
+	return fileNames
+}
+
+// withKey runs fn with the decrypted key for address while holding the read lock, so the
+// key cannot be wiped by a re-lock, deletion or reload while it is in use.
+func (km *LocalKeyManager) withKey(address common.Address, fn func(*ecdsa.PrivateKey) error) error {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+
+	privateKey, ok := km.keys[address]
+	if !ok {
+		if km.unlock != nil && len(km.filesForLocked(address)) > 0 {
+			return fmt.Errorf("%w: %s", ErrAccountLocked, address.Hex())
+		}
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, address.Hex())
+	}
+	return fn(privateKey)
+}
+
+// forgetLocked wipes and drops the decrypted key for address, if any.
+// The caller must hold km.mu for writing.
+func (km *LocalKeyManager) forgetLocked(address common.Address) {
+	km.stopRelock(address)
+	if key, ok := km.keys[address]; ok {
+		zeroKey(key)
+		delete(km.keys, address)
+	}
+}
+
+// addressesOf returns the set of addresses held by the given keystore files.
+func addressesOf(files map[string]keyFile) map[common.Address]bool {
+	addresses := make(map[common.Address]bool, len(files))
+	for _, f := range files {
+		addresses[f.address] = true
+	}
+	return addresses
+}
+
 // GetAccounts returns all managed account addresses.
-func (km *LocalKeyManager) GetAccounts() []common.Address {
+func (km *LocalKeyManager) GetAccounts(ctx context.Context) []common.Address {
 	km.mu.RLock()
 	defer km.mu.RUnlock()
 
 	var addresses []common.Address
-	for addr := range km.keys {
+	for addr := range addressesOf(km.files) {
 		addresses = append(addresses, addr)
 	}
 	return addresses
 }
 
 // SignTx signs a transaction using a locally stored private key.
-func (km *LocalKeyManager) SignTx(address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
-	km.mu.RLock()
-	privateKey, ok := km.keys[address]
-	km.mu.RUnlock()
-
-	if !ok {
-		return nil, fmt.Errorf("account not found: %s", address.Hex())
+func (km *LocalKeyManager) SignTx(ctx context.Context, address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	signedTx, err := types.SignTx(tx, types.NewPragueSigner(chainID), privateKey)
+	var signedTx *types.Transaction
+	err := km.withKey(address, func(privateKey *ecdsa.PrivateKey) error {
+		var err error
+		signedTx, err = types.SignTx(tx, types.NewPragueSigner(chainID), privateKey)
+		if err != nil {
+			return fmt.Errorf("failed to sign transaction: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+		return nil, err
 	}
 
 	return signedTx, nil
 }
 
-// SignMessage signs a message using a locally stored private key.
-func (km *LocalKeyManager) SignMessage(address common.Address, message []byte) ([]byte, error) {
-	km.mu.RLock()
-	privateKey, ok := km.keys[address]
-	km.mu.RUnlock()
+// SignHash signs a precomputed hash with the key for address.
+func (km *LocalKeyManager) SignHash(ctx context.Context, address common.Address, hash []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	if !ok {
-		return nil, fmt.Errorf("account not found: %s", address.Hex())
+	var signature []byte
+	err := km.withKey(address, func(privateKey *ecdsa.PrivateKey) error {
+		var err error
+		signature, err = crypto.Sign(hash, privateKey)
+		if err != nil {
+			return fmt.Errorf("failed to sign hash: %w", err)
+		}
+		return nil
+	})
+	return signature, err
+}
+
+// PublicKey returns the public key for address. With per-account passwords the public
+// key is only known while the account is unlocked, since keystore files store just the address.
+func (km *LocalKeyManager) PublicKey(ctx context.Context, address common.Address) (*ecdsa.PublicKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var publicKey ecdsa.PublicKey
+	err := km.withKey(address, func(privateKey *ecdsa.PrivateKey) error {
+		publicKey = privateKey.PublicKey
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &publicKey, nil
+}
+
+// SignMessage signs a message using a locally stored private key.
+func (km *LocalKeyManager) SignMessage(ctx context.Context, address common.Address, message []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	// EIP-191: Signed Data Standard
 	prefixedMessage := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
 	messageHash := crypto.Keccak256Hash([]byte(prefixedMessage))
 
-	signature, err := crypto.Sign(messageHash.Bytes(), privateKey)
+	var signature []byte
+	err := km.withKey(address, func(privateKey *ecdsa.PrivateKey) error {
+		var err error
+		signature, err = crypto.Sign(messageHash.Bytes(), privateKey)
+		if err != nil {
+			return fmt.Errorf("failed to sign message: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign message: %w", err)
+		return nil, err
 	}
 
 	// Adjust the V value of the signature
@@ -148,3 +585,61 @@ func (km *LocalKeyManager) SignMessage(address common.Address, message []byte) (
 
 	return signature, nil
 }
+
+// HealthCheck reports how many keystore files were loaded. The keystore is unhealthy when
+// key files exist but none of them could be decrypted, which usually means a wrong password.
+func (km *LocalKeyManager) HealthCheck(ctx context.Context) []ComponentHealth {
+	km.mu.RLock()
+	loaded, unlocked, failed := len(addressesOf(km.files)), len(km.keys), km.failed
+	km.mu.RUnlock()
+
+	status := ComponentHealth{
+		Name:    "local_keystore",
+		Healthy: true,
+		Details: map[string]interface{}{
+			"keyDir": km.keyDir,
+			"loaded": loaded,
+			"failed": failed,
+		},
+	}
+	if km.unlock != nil {
+		status.Details["unlocked"] = unlocked
+	}
+	if loaded == 0 && failed > 0 {
+		status.Healthy = false
+		status.Message = "no keystore file could be decrypted"
+	}
+	return []ComponentHealth{status}
+}
+
+// Close wipes all decrypted private keys from memory.
+func (km *LocalKeyManager) Close() error {
+	km.writeMu.Lock()
+	defer km.writeMu.Unlock()
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	for addr, timer := range km.unlocked {
+		timer.Stop()
+		delete(km.unlocked, addr)
+	}
+	for addr, key := range km.keys {
+		zeroKey(key)
+		delete(km.keys, addr)
+	}
+	km.files = make(map[string]keyFile)
+	log.Println("Local key manager closed, in-memory keys wiped")
+	return nil
+}
+
+// zeroKey overwrites the private scalar of key in place.
+func zeroKey(key *ecdsa.PrivateKey) {
+	if key == nil || key.D == nil {
+		return
+	}
+	words := key.D.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	key.D.SetInt64(0)
+}
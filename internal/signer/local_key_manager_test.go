@@ -0,0 +1,67 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// newTestLocalKeyManager returns a LocalKeyManager on an empty directory that writes
+// keystore files with cheap scrypt parameters.
+func newTestLocalKeyManager(t *testing.T) *LocalKeyManager {
+	t.Helper()
+	km, err := NewLocalKeyManager(t.TempDir(), "", "password", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	km.scrypt = ScryptParams{N: keystore.LightScryptN, P: keystore.LightScryptP}
+	t.Cleanup(func() { km.Close() })
+	return km
+}
+
+func TestLocalImportKeyConcurrent(t *testing.T) {
+	km := newTestLocalKeyManager(t)
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const imports = 8
+	errs := make(chan error, imports)
+	var wg sync.WaitGroup
+	for range imports {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := km.ImportKey(context.Background(), privateKey, CreateKeyOptions{})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrAccountExists):
+			t.Errorf("ImportKey: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("%d imports succeeded, want 1", succeeded)
+	}
+	entries, err := os.ReadDir(km.keyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("key directory holds %d files, want 1", len(entries))
+	}
+}
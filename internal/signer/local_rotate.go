@@ -0,0 +1,310 @@
+package signer
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+)
+
+// Suffixes of the files written while rotating the keystore password. Reload recovers
+// from them with the shared password and otherwise ignores them, so an interrupted
+// rotation never loads a key twice.
+const (
+	rotateNewSuffix    = ".rotate-new"
+	rotateBackupSuffix = ".rotate-bak"
+)
+
+// ErrWrongPassword is returned when a supplied password does not match the current one.
+var ErrWrongPassword = errors.New("wrong password")
+
+// ScryptParams are the scrypt cost parameters used to encrypt keystore files.
+type ScryptParams struct {
+	N int
+	P int
+}
+
+// Validate checks that the parameters are usable by the keystore encryption.
+func (p ScryptParams) Validate() error {
+	if p.N <= 1 || p.N&(p.N-1) != 0 {
+		return fmt.Errorf("scrypt N must be a power of two greater than 1, got %d", p.N)
+	}
+	if p.P < 1 {
+		return fmt.Errorf("scrypt P must be at least 1, got %d", p.P)
+	}
+	return nil
+}
+
+// PasswordRotator is implemented by KeyManagers whose key material is encrypted with a
+// password the signer holds.
+type PasswordRotator interface {
+	// RotatePassword re-encrypts every key with next. When scrypt is nil each keystore file
+	// keeps its current scrypt parameters. It returns the number of files re-encrypted.
+	RotatePassword(ctx context.Context, current, next string, scrypt *ScryptParams) (int, error)
+}
+
+// rotation tracks one keystore file through a password rotation.
+type rotation struct {
+	name       string
+	backedUp   bool
+	replaced   bool
+	newWritten bool
+}
+
+// RotatePassword re-encrypts every keystore file in the key directory with next.
+//
+// All new files are written next to the originals first; only when every file has been
+// re-encrypted are the originals swapped out by rename. If any step fails, the originals
+// are put back and the key directory is left as it was. Keys in memory are not touched,
+// so signing continues throughout. The configured password must be updated before the
+// next restart, otherwise no key can be decrypted.
+func (km *LocalKeyManager) RotatePassword(ctx context.Context, current, next string, scrypt *ScryptParams) (int, error) {
+	if km.unlock != nil {
+		return 0, fmt.Errorf("keystore files are encrypted with per-account passwords: %w", ErrNotSupported)
+	}
+	if next == "" {
+		return 0, errors.New("new password must not be empty")
+	}
+	if scrypt != nil {
+		if err := scrypt.Validate(); err != nil {
+			return 0, err
+		}
+	}
+
+	km.writeMu.Lock()
+	defer km.writeMu.Unlock()
+
+	// The password only changes under writeMu, so it is safe to read here.
+	if subtle.ConstantTimeCompare([]byte(current), []byte(km.password)) != 1 {
+		return 0, ErrWrongPassword
+	}
+
+	km.mu.RLock()
+	failed := km.failed
+	names := make([]string, 0, len(km.files))
+	for name := range km.files {
+		names = append(names, name)
+	}
+	km.mu.RUnlock()
+	// Files the current password cannot open would be left behind with it, unreadable
+	// after the next restart.
+	if failed > 0 {
+		return 0, fmt.Errorf("%d keystore files in %s cannot be decrypted with the current password; remove them before rotating", failed, km.keyDir)
+	}
+	slices.Sort(names)
+
+	rotations := make([]*rotation, 0, len(names))
+	rollback := func() {
+		for i := len(rotations) - 1; i >= 0; i-- {
+			r := rotations[i]
+			path := filepath.Join(km.keyDir, r.name)
+			if r.backedUp {
+				if err := os.Rename(path+rotateBackupSuffix, path); err != nil {
+					log.Printf("Error: failed to restore %s from %s: %v", r.name, r.name+rotateBackupSuffix, err)
+				}
+			}
+			if r.newWritten && !r.replaced {
+				os.Remove(path + rotateNewSuffix)
+			}
+		}
+	}
+
+	// Phase 1: write every re-encrypted file next to its original.
+	for _, name := range names {
+		r := &rotation{name: name}
+		rotations = append(rotations, r)
+		if err := ctx.Err(); err != nil {
+			rollback()
+			return 0, err
+		}
+		if err := km.reencrypt(name, next, scrypt); err != nil {
+			rollback()
+			return 0, err
+		}
+		r.newWritten = true
+	}
+
+	// Phase 2: swap the new files in, keeping the originals until all swaps succeeded.
+	for _, r := range rotations {
+		path := filepath.Join(km.keyDir, r.name)
+		if err := os.Rename(path, path+rotateBackupSuffix); err != nil {
+			rollback()
+			return 0, fmt.Errorf("failed to back up key file %s: %w", r.name, err)
+		}
+		r.backedUp = true
+		if err := os.Rename(path+rotateNewSuffix, path); err != nil {
+			rollback()
+			return 0, fmt.Errorf("failed to replace key file %s: %w", r.name, err)
+		}
+		r.replaced = true
+	}
+
+	files := make(map[string]keyFile, len(rotations))
+	km.mu.RLock()
+	for _, r := range rotations {
+		f := km.files[r.name]
+		if info, err := os.Stat(filepath.Join(km.keyDir, r.name)); err == nil {
+			f.size, f.modTime = info.Size(), info.ModTime()
+		}
+		files[r.name] = f
+	}
+	km.mu.RUnlock()
+
+	km.mu.Lock()
+	km.files = files
+	km.password = next
+	if scrypt != nil {
+		km.scrypt = *scrypt
+	}
+	km.mu.Unlock()
+
+	for _, r := range rotations {
+		if err := os.Remove(filepath.Join(km.keyDir, r.name+rotateBackupSuffix)); err != nil {
+			log.Printf("Warning: failed to remove backup of key file %s: %v", r.name, err)
+		}
+	}
+
+	log.Printf("Re-encrypted %d keystore files in %s with a new password; update local.password before the next restart", len(rotations), km.keyDir)
+	return len(rotations), nil
+}
+
+// reencrypt decrypts the keystore file name with the current password and writes it,
+// encrypted with password, to name+rotateNewSuffix.
+func (km *LocalKeyManager) reencrypt(name, password string, scrypt *ScryptParams) error {
+	path := filepath.Join(km.keyDir, name)
+	keyJson, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read key file %s: %w", name, err)
+	}
+	key, err := keystore.DecryptKey(keyJson, km.password)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt key file %s: %w", name, err)
+	}
+
+	params := scryptParamsOf(keyJson)
+	if scrypt != nil {
+		params = *scrypt
+	}
+	newJson, err := keystore.EncryptKey(key, password, params.N, params.P)
+	zeroKey(key.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt key file %s: %w", name, err)
+	}
+
+	return writeFileSync(path+rotateNewSuffix, newJson, 0600)
+}
+
+// scryptParamsOf returns the scrypt parameters a keystore file was encrypted with,
+// falling back to the standard parameters for other key derivation functions.
+func scryptParamsOf(keyJson []byte) ScryptParams {
+	var file struct {
+		Crypto keystore.CryptoJSON `json:"crypto"`
+	}
+	standard := ScryptParams{N: keystore.StandardScryptN, P: keystore.StandardScryptP}
+	// Field matching is case-insensitive, so this also reads version 3 files written as "Crypto".
+	if err := json.Unmarshal(keyJson, &file); err != nil || file.Crypto.KDF != "scrypt" {
+		return standard
+	}
+	n, nok := file.Crypto.KDFParams["n"].(float64)
+	p, pok := file.Crypto.KDFParams["p"].(float64)
+	if !nok || !pok {
+		return standard
+	}
+	return ScryptParams{N: int(n), P: int(p)}
+}
+
+// writeFileSync writes data to a new file and flushes it to disk before returning.
+func writeFileSync(path string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// recoverRotation cleans up after a password rotation that was interrupted, e.g. by a
+// crash, before the key directory is scanned. Each key file ends up as whichever of its
+// rotated version and its backup the configured password opens, and re-encrypted files
+// that were never swapped in are removed. The caller must hold writeMu.
+func (km *LocalKeyManager) recoverRotation() {
+	entries, err := os.ReadDir(km.keyDir)
+	if err != nil {
+		return // Reload reports it
+	}
+	// Backups first: a file whose original was moved aside but not yet replaced gets its
+	// original back before its re-encrypted version is looked at.
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), rotateBackupSuffix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(km.keyDir, name)
+		switch {
+		case km.opens(path):
+			if err := os.Remove(path + rotateBackupSuffix); err != nil {
+				log.Printf("Warning: failed to remove %s left over from a password rotation: %v", entry.Name(), err)
+				continue
+			}
+			log.Printf("Removed %s left over from a completed password rotation", entry.Name())
+		case km.opens(path + rotateBackupSuffix):
+			if err := os.Rename(path+rotateBackupSuffix, path); err != nil {
+				log.Printf("Error: failed to restore %s from %s: %v", name, entry.Name(), err)
+				continue
+			}
+			log.Printf("Restored %s from %s after an interrupted password rotation", name, entry.Name())
+		default:
+			log.Printf("Warning: neither %s nor %s can be decrypted with the configured password; leaving both", name, entry.Name())
+		}
+	}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), rotateNewSuffix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(km.keyDir, name)
+		if _, err := os.Stat(path); err != nil {
+			log.Printf("Warning: %s has no original key file; leaving it", entry.Name())
+			continue
+		}
+		if err := os.Remove(path + rotateNewSuffix); err != nil {
+			log.Printf("Warning: failed to remove %s left over from a password rotation: %v", entry.Name(), err)
+			continue
+		}
+		log.Printf("Removed %s left over from an interrupted password rotation", entry.Name())
+	}
+}
+
+// opens reports whether the keystore file at path decrypts with the configured password.
+func (km *LocalKeyManager) opens(path string) bool {
+	keyJson, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	key, err := keystore.DecryptKey(keyJson, km.password)
+	if err != nil {
+		return false
+	}
+	zeroKey(key.PrivateKey)
+	return true
+}
+
+// isRotationFile reports whether name is a leftover of an interrupted password rotation.
+func isRotationFile(name string) bool {
+	return strings.HasSuffix(name, rotateNewSuffix) || strings.HasSuffix(name, rotateBackupSuffix)
+}
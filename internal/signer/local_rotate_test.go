@@ -0,0 +1,210 @@
+package signer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// keyFiles returns the names of the files in dir, sorted.
+func keyFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	slices.Sort(names)
+	return names
+}
+
+// checkPassword fails the test unless every keystore file in names opens with password.
+func checkPassword(t *testing.T, dir string, names []string, password string) {
+	t.Helper()
+	for _, name := range names {
+		keyJson, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := keystore.DecryptKey(keyJson, password); err != nil {
+			t.Errorf("%s does not open with %q: %v", name, password, err)
+		}
+	}
+}
+
+// newRotationKeyManager returns a LocalKeyManager with two keys encrypted with "password".
+func newRotationKeyManager(t *testing.T) (*LocalKeyManager, []string) {
+	t.Helper()
+	km := newTestLocalKeyManager(t)
+	for i := 0; i < 2; i++ {
+		if _, err := km.CreateKey(t.Context(), CreateKeyOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names := keyFiles(t, km.keyDir)
+	if len(names) != 2 {
+		t.Fatalf("key directory holds %v, want two keystore files", names)
+	}
+	return km, names
+}
+
+func TestLocalRotatePassword(t *testing.T) {
+	km, names := newRotationKeyManager(t)
+	light := &ScryptParams{N: keystore.LightScryptN, P: keystore.LightScryptP}
+
+	if _, err := km.RotatePassword(t.Context(), "wrong", "new-password", light); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("RotatePassword with a wrong current password: got %v, want ErrWrongPassword", err)
+	}
+	checkPassword(t, km.keyDir, names, "password")
+
+	rotated, err := km.RotatePassword(t.Context(), "password", "new-password", light)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated != len(names) {
+		t.Errorf("rotated %d files, want %d", rotated, len(names))
+	}
+	if got := keyFiles(t, km.keyDir); !slices.Equal(got, names) {
+		t.Errorf("key directory holds %v after rotation, want %v", got, names)
+	}
+	checkPassword(t, km.keyDir, names, "new-password")
+
+	// Signing goes on with the keys in memory, and the new password is the current one.
+	for _, address := range km.GetAccounts(t.Context()) {
+		if _, err := km.SignMessage(t.Context(), address, []byte("hello")); err != nil {
+			t.Errorf("SignMessage after rotation: %v", err)
+		}
+	}
+	if _, err := km.RotatePassword(t.Context(), "password", "other", light); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("RotatePassword with the old password: got %v, want ErrWrongPassword", err)
+	}
+	// A rescan finds the files unchanged since they were swapped in.
+	if err := km.Reload(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(km.GetAccounts(t.Context())); n != len(names) {
+		t.Errorf("%d accounts after reload, want %d", n, len(names))
+	}
+}
+
+func TestLocalRotatePasswordRollsBack(t *testing.T) {
+	km, names := newRotationKeyManager(t)
+	light := &ScryptParams{N: keystore.LightScryptN, P: keystore.LightScryptP}
+
+	// Backing up the second file fails once the first has been swapped.
+	blocker := filepath.Join(km.keyDir, names[1]+rotateBackupSuffix)
+	if err := os.MkdirAll(filepath.Join(blocker, "in-the-way"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := km.RotatePassword(t.Context(), "password", "new-password", light); err == nil {
+		t.Fatal("RotatePassword succeeded although a rename failed")
+	}
+	want := append(slices.Clone(names), filepath.Base(blocker))
+	slices.Sort(want)
+	if got := keyFiles(t, km.keyDir); !slices.Equal(got, want) {
+		t.Fatalf("key directory holds %v after rollback, want %v", got, want)
+	}
+	checkPassword(t, km.keyDir, names, "password")
+
+	// The password is unchanged, so the rotation can be retried.
+	if err := os.RemoveAll(blocker); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := km.RotatePassword(t.Context(), "password", "new-password", light); err != nil {
+		t.Fatalf("RotatePassword after rollback: %v", err)
+	}
+	checkPassword(t, km.keyDir, names, "new-password")
+}
+
+func TestLocalRecoversInterruptedRotation(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, password string) {
+		t.Helper()
+		privateKey, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := &keystore.Key{Address: crypto.PubkeyToAddress(privateKey.PublicKey), PrivateKey: privateKey}
+		keyJson, err := keystore.EncryptKey(key, password, keystore.LightScryptN, keystore.LightScryptP)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), keyJson, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reencrypt := func(from, to, password string) {
+		t.Helper()
+		keyJson, err := os.ReadFile(filepath.Join(dir, from))
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := keystore.DecryptKey(keyJson, "password")
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyJson, err = keystore.EncryptKey(key, password, keystore.LightScryptN, keystore.LightScryptP)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, to), keyJson, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Interrupted while writing new files: the original is still in place.
+	write("a", "password")
+	reencrypt("a", "a"+rotateNewSuffix, "new-password")
+	// Interrupted between backing up the original and swapping in the new file.
+	write("b", "password")
+	reencrypt("b", "b"+rotateNewSuffix, "new-password")
+	if err := os.Rename(filepath.Join(dir, "b"), filepath.Join(dir, "b"+rotateBackupSuffix)); err != nil {
+		t.Fatal(err)
+	}
+	// Swapped, but the rotation did not complete, so the password was not changed.
+	write("c", "password")
+	if err := os.Rename(filepath.Join(dir, "c"), filepath.Join(dir, "c"+rotateBackupSuffix)); err != nil {
+		t.Fatal(err)
+	}
+	reencrypt("c"+rotateBackupSuffix, "c", "new-password")
+	// Swapped under a completed rotation whose backups were not removed yet.
+	write("d", "new-password")
+	write("d"+rotateBackupSuffix, "other-password")
+
+	// Starting with the old password keeps the originals of a, b and c; d opens with
+	// neither password and is left alone.
+	km, err := NewLocalKeyManager(dir, "", "password", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer km.Close()
+	want := []string{"a", "b", "c", "d", "d" + rotateBackupSuffix}
+	if got := keyFiles(t, dir); !slices.Equal(got, want) {
+		t.Fatalf("key directory holds %v after recovery, want %v", got, want)
+	}
+	checkPassword(t, dir, []string{"a", "b", "c"}, "password")
+	if n := len(km.GetAccounts(t.Context())); n != 3 {
+		t.Errorf("%d accounts loaded, want 3", n)
+	}
+	km.Close()
+
+	// With the new password, the completed rotation of d is kept and its backup removed.
+	km, err = NewLocalKeyManager(dir, "", "new-password", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer km.Close()
+	want = []string{"a", "b", "c", "d"}
+	if got := keyFiles(t, dir); !slices.Equal(got, want) {
+		t.Fatalf("key directory holds %v after recovery, want %v", got, want)
+	}
+	checkPassword(t, dir, []string{"d"}, "new-password")
+}
@@ -0,0 +1,147 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrPasswordRequired is returned when creating or importing a key without the per-account
+// password it must be encrypted with.
+var ErrPasswordRequired = errors.New("a password for the account is required")
+
+// Unlock decrypts the key for address with its own password and keeps it in memory for
+// ttl. Unlocking an unlocked account checks the password again and restarts the TTL.
+// TTLs above the policy maximum are shortened to it.
+func (km *LocalKeyManager) Unlock(ctx context.Context, address common.Address, password string, ttl time.Duration) (time.Time, error) {
+	if km.unlock == nil {
+		return time.Time{}, fmt.Errorf("per-account passwords are not enabled: %w", ErrNotSupported)
+	}
+	if ttl <= 0 {
+		ttl = km.unlock.DefaultTTL
+	}
+	if km.unlock.MaxTTL > 0 && ttl > km.unlock.MaxTTL {
+		ttl = km.unlock.MaxTTL
+	}
+
+	fileNames := km.filesFor(address)
+	if len(fileNames) == 0 {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrAccountNotFound, address.Hex())
+	}
+	slices.Sort(fileNames)
+	keyJson, err := os.ReadFile(filepath.Join(km.keyDir, fileNames[0]))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read key file %s: %w", fileNames[0], err)
+	}
+	// Decryption is slow; don't bother if the caller is gone.
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, err
+	}
+	key, err := keystore.DecryptKey(keyJson, password)
+	if errors.Is(err, keystore.ErrDecrypt) {
+		return time.Time{}, fmt.Errorf("%w for %s", ErrWrongPassword, address.Hex())
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to decrypt key file %s: %w", fileNames[0], err)
+	}
+	if key.Address != address {
+		zeroKey(key.PrivateKey)
+		return time.Time{}, fmt.Errorf("key file %s holds %s, not %s", fileNames[0], key.Address.Hex(), address.Hex())
+	}
+
+	// A reload replaces km.keys with a map built from a snapshot, so the key must not be
+	// added while one is running.
+	km.writeMu.Lock()
+	defer km.writeMu.Unlock()
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	if len(km.filesForLocked(address)) == 0 {
+		// Deleted or archived while decrypting.
+		zeroKey(key.PrivateKey)
+		return time.Time{}, fmt.Errorf("%w: %s", ErrAccountNotFound, address.Hex())
+	}
+	if _, ok := km.keys[address]; ok {
+		zeroKey(key.PrivateKey)
+	} else {
+		km.keys[address] = key.PrivateKey
+	}
+	km.stopRelock(address)
+	var timer *time.Timer
+	// relock reads timer only once it holds the lock held here, so it cannot see timer
+	// before it is assigned.
+	timer = time.AfterFunc(ttl, func() { km.relock(address, &timer) })
+	km.unlocked[address] = timer
+
+	expiresAt := time.Now().Add(ttl)
+	log.Printf("Unlocked local key for address %s until %s", address.Hex(), expiresAt.Format(time.RFC3339))
+	return expiresAt, nil
+}
+
+// Lock wipes the decrypted key for address immediately. Locking a locked account is not an error.
+func (km *LocalKeyManager) Lock(ctx context.Context, address common.Address) error {
+	if km.unlock == nil {
+		return fmt.Errorf("per-account passwords are not enabled: %w", ErrNotSupported)
+	}
+
+	km.writeMu.Lock()
+	defer km.writeMu.Unlock()
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	if len(km.filesForLocked(address)) == 0 {
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, address.Hex())
+	}
+	if _, ok := km.keys[address]; ok {
+		km.forgetLocked(address)
+		log.Printf("Locked local key for address %s", address.Hex())
+	}
+	return nil
+}
+
+// relock is called when the unlock TTL of address expires. timer points at the variable
+// holding the expired timer; it is read under the lock. A timer that was replaced by
+// a later Unlock does nothing.
+func (km *LocalKeyManager) relock(address common.Address, timer **time.Timer) {
+	km.writeMu.Lock()
+	defer km.writeMu.Unlock()
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	if km.unlocked[address] != *timer {
+		return
+	}
+	km.forgetLocked(address)
+	log.Printf("Unlock of local key for address %s expired, key locked", address.Hex())
+}
+
+// stopRelock cancels the pending re-lock of address. The caller must hold km.mu for writing.
+func (km *LocalKeyManager) stopRelock(address common.Address) {
+	if timer, ok := km.unlocked[address]; ok {
+		timer.Stop()
+		delete(km.unlocked, address)
+	}
+}
+
+// keystoreAddress reads the address recorded in a keystore file without decrypting it.
+func keystoreAddress(keyJson []byte) (common.Address, error) {
+	var file struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(keyJson, &file); err != nil {
+		return common.Address{}, err
+	}
+	if !common.IsHexAddress(file.Address) {
+		return common.Address{}, fmt.Errorf("invalid address %q", file.Address)
+	}
+	return common.HexToAddress(file.Address), nil
+}
@@ -0,0 +1,160 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// newTestUnlockKeyManager returns a LocalKeyManager with per-account passwords holding one
+// locked key encrypted with "account-password".
+func newTestUnlockKeyManager(t *testing.T, policy UnlockPolicy) (*LocalKeyManager, common.Address) {
+	t.Helper()
+	km, err := NewLocalKeyManager(t.TempDir(), "", "", &policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	km.scrypt = ScryptParams{N: keystore.LightScryptN, P: keystore.LightScryptP}
+	t.Cleanup(func() { km.Close() })
+	address, err := km.CreateKey(t.Context(), CreateKeyOptions{Password: "account-password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return km, address
+}
+
+// unlockedKey returns the decrypted key held for address, or nil if it is locked.
+func unlockedKey(km *LocalKeyManager, address common.Address) *ecdsa.PrivateKey {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	return km.keys[address]
+}
+
+func isZeroed(key *ecdsa.PrivateKey) bool {
+	return key.D.Sign() == 0
+}
+
+func TestLocalUnlockAndLock(t *testing.T) {
+	km, address := newTestUnlockKeyManager(t, UnlockPolicy{DefaultTTL: time.Hour})
+	ctx := t.Context()
+
+	if _, err := km.SignMessage(ctx, address, []byte("hello")); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("SignMessage before unlock: got %v, want ErrAccountLocked", err)
+	}
+	if _, err := km.Unlock(ctx, address, "wrong", 0); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("Unlock with a wrong password: got %v, want ErrWrongPassword", err)
+	}
+	if _, err := km.Unlock(ctx, common.Address{1}, "account-password", 0); !errors.Is(err, ErrAccountNotFound) {
+		t.Fatalf("Unlock of an unknown account: got %v, want ErrAccountNotFound", err)
+	}
+
+	expiresAt, err := km.Unlock(ctx, address, "account-password", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(expiresAt); d < 59*time.Minute || d > time.Hour {
+		t.Fatalf("Unlock without a TTL expires in %s, want the default of 1h", d)
+	}
+	if _, err := km.SignMessage(ctx, address, []byte("hello")); err != nil {
+		t.Fatalf("SignMessage after unlock: %v", err)
+	}
+
+	key := unlockedKey(km, address)
+	if err := km.Lock(ctx, address); err != nil {
+		t.Fatal(err)
+	}
+	if !isZeroed(key) {
+		t.Fatal("Lock left the decrypted key in memory")
+	}
+	if _, err := km.SignMessage(ctx, address, []byte("hello")); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("SignMessage after lock: got %v, want ErrAccountLocked", err)
+	}
+	if err := km.Lock(ctx, address); err != nil {
+		t.Fatalf("Lock of a locked account: %v", err)
+	}
+}
+
+func TestLocalUnlockTTLExpiry(t *testing.T) {
+	km, address := newTestUnlockKeyManager(t, UnlockPolicy{DefaultTTL: time.Hour, MaxTTL: 100 * time.Millisecond})
+	ctx := t.Context()
+
+	// The requested TTL is shortened to the policy maximum.
+	expiresAt, err := km.Unlock(ctx, address, "account-password", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Until(expiresAt); d > 100*time.Millisecond {
+		t.Fatalf("Unlock expires in %s, want at most the 100ms maximum", d)
+	}
+	key := unlockedKey(km, address)
+	if key == nil {
+		t.Fatal("key not unlocked")
+	}
+
+	waitFor(t, 5*time.Second, "the unlock to expire", func() bool { return unlockedKey(km, address) == nil })
+	if !isZeroed(key) {
+		t.Fatal("expired unlock left the decrypted key in memory")
+	}
+	if _, err := km.SignMessage(ctx, address, []byte("hello")); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("SignMessage after expiry: got %v, want ErrAccountLocked", err)
+	}
+}
+
+// TestLocalUnlockDuringReload unlocks while the key directory is rescanned. Every
+// successful unlock must survive the reloads and still be re-locked when its TTL expires.
+func TestLocalUnlockDuringReload(t *testing.T) {
+	km, address := newTestUnlockKeyManager(t, UnlockPolicy{DefaultTTL: time.Hour})
+	for i := 0; i < 10; i++ {
+		if _, err := km.CreateKey(t.Context(), CreateKeyOptions{Password: "other-password"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+
+	for i := 0; i < 20; i++ {
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err := km.Reload(ctx); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+		_, err := km.Unlock(ctx, address, "account-password", 50*time.Millisecond)
+		close(stop)
+		wg.Wait()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		key := unlockedKey(km, address)
+		if key == nil {
+			t.Fatalf("iteration %d: reload dropped a key unlocked while it ran", i)
+		}
+		waitFor(t, 5*time.Second, "the unlock to expire", func() bool { return unlockedKey(km, address) == nil })
+		if !isZeroed(key) {
+			t.Fatalf("iteration %d: expired unlock left the decrypted key in memory", i)
+		}
+		km.mu.RLock()
+		pending := len(km.unlocked)
+		km.mu.RUnlock()
+		if pending != 0 {
+			t.Fatalf("iteration %d: %d re-lock timers left after expiry", i, pending)
+		}
+	}
+}
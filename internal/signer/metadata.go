@@ -0,0 +1,135 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/xueqianLu/ethsigner/internal/store"
+)
+
+// AccountFilter selects accounts in ListAccounts.
+type AccountFilter struct {
+	// States lists the lifecycle states to include. Empty means active accounts only.
+	States []string
+	// Tags lists tags an account must all carry.
+	Tags []string
+	// Owner, if set, must match the account owner exactly.
+	Owner string
+}
+
+func (f AccountFilter) matches(acc store.Account) bool {
+	states := f.States
+	if len(states) == 0 {
+		states = []string{store.StateActive}
+	}
+	if !slices.Contains(states, acc.State) {
+		return false
+	}
+	if f.Owner != "" && acc.Owner != f.Owner {
+		return false
+	}
+	return acc.HasTags(f.Tags)
+}
+
+// ListAccounts returns the account records matching filter, sorted by address.
+// Keys held by the KeyManager without a record, e.g. keystore files copied into
+// key_dir by hand, are listed as active accounts without metadata. Archived
+// accounts are listed from their records since the backend may no longer hold them.
+func (s *Signer) ListAccounts(ctx context.Context, filter AccountFilter) ([]store.Account, error) {
+	if err := s.begin(); err != nil {
+		return nil, err
+	}
+	defer s.end()
+	ctx, cancel := withTimeout(ctx, s.timeouts.Load().List)
+	defer cancel()
+
+	records, err := s.accounts.ListAccounts()
+	if err != nil {
+		return nil, err
+	}
+	byAddress := make(map[common.Address]store.Account, len(records))
+	for _, acc := range records {
+		byAddress[acc.Address] = acc
+	}
+
+	var result []store.Account
+	seen := make(map[common.Address]bool)
+	for _, addr := range s.keyManager.GetAccounts(ctx) {
+		seen[addr] = true
+		acc, ok := byAddress[addr]
+		if !ok {
+			acc = store.Account{Address: addr, State: store.StateActive}
+		}
+		if acc.Backend == "" {
+			acc.Backend, acc.KeyRef = s.keyReference(addr)
+		}
+		if filter.matches(acc) {
+			result = append(result, acc)
+		}
+	}
+	for _, acc := range records {
+		if !seen[acc.Address] && acc.State == store.StateArchived && filter.matches(acc) {
+			result = append(result, acc)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b store.Account) int {
+		return bytes.Compare(a.Address[:], b.Address[:])
+	})
+	return result, nil
+}
+
+// UpdateMetadata replaces the metadata of a managed account.
+func (s *Signer) UpdateMetadata(ctx context.Context, address common.Address, meta store.Metadata) (store.Account, error) {
+	if err := s.begin(); err != nil {
+		return store.Account{}, err
+	}
+	defer s.end()
+	if err := s.checkActive(address); errors.Is(err, ErrAccountArchived) {
+		return store.Account{}, err
+	}
+	if !s.isManaged(ctx, address) {
+		return store.Account{}, fmt.Errorf("%w: %s", ErrAccountNotFound, address.Hex())
+	}
+	return s.accounts.UpdateAccount(address, func(acc *store.Account) error {
+		acc.Metadata = normalizeMetadata(meta)
+		if acc.Backend == "" {
+			acc.Backend, acc.KeyRef = s.keyReference(address)
+		}
+		return nil
+	})
+}
+
+// keyReference asks the KeyManager where the key for address lives, if it can tell.
+func (s *Signer) keyReference(address common.Address) (string, string) {
+	ref, ok := s.keyManager.(KeyReferencer)
+	if !ok {
+		return "", ""
+	}
+	backend, keyRef, err := ref.KeyReference(address)
+	if err != nil {
+		return "", ""
+	}
+	return backend, keyRef
+}
+
+// normalizeMetadata trims whitespace and drops empty and duplicate tags.
+func normalizeMetadata(meta store.Metadata) store.Metadata {
+	meta.Label = strings.TrimSpace(meta.Label)
+	meta.Owner = strings.TrimSpace(meta.Owner)
+	meta.Purpose = strings.TrimSpace(meta.Purpose)
+	var tags []string
+	for _, tag := range meta.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	meta.Tags = tags
+	return meta
+}
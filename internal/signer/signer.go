@@ -1,39 +1,361 @@
 package signer
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"math/big"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/xueqianLu/ethsigner/internal/store"
 )
 
+// ErrInvalidTypedData is returned when EIP-712 typed data cannot be encoded for signing.
+var ErrInvalidTypedData = errors.New("invalid typed data")
+
+// ErrClosed is returned by operations started after the Signer was closed.
+var ErrClosed = errors.New("signer is closed")
+
+// Timeouts bounds how long a single KeyManager operation may take.
+// A zero value disables the corresponding timeout.
+type Timeouts struct {
+	List   time.Duration
+	Create time.Duration
+	Sign   time.Duration
+}
+
 // Signer provides transaction and message signing functionality.
+// It enforces account lifecycle states recorded in the account store on top of the KeyManager.
 type Signer struct {
 	keyManager KeyManager
+	accounts   *store.Store
+	timeouts   atomic.Pointer[Timeouts]
+
+	// Operations in flight, so that Close only wipes keys once none of them can still be
+	// using one. closed is guarded by mu, which also orders inflight.Add before Wait.
+	mu       sync.Mutex
+	closed   bool
+	inflight sync.WaitGroup
+	active   atomic.Int64
 }
 
-// NewSigner creates a new Signer with a given KeyManager.
-func NewSigner(keyManager KeyManager) *Signer {
-	return &Signer{
+// NewSigner creates a new Signer with a given KeyManager, account store and per-operation timeouts.
+// The Signer takes ownership of both and closes them in Close.
+func NewSigner(keyManager KeyManager, accounts *store.Store, timeouts Timeouts) *Signer {
+	s := &Signer{
 		keyManager: keyManager,
+		accounts:   accounts,
+	}
+	s.SetTimeouts(timeouts)
+	return s
+}
+
+// SetTimeouts replaces the per-operation timeouts. Requests already in flight keep
+// the deadline they started with.
+func (s *Signer) SetTimeouts(timeouts Timeouts) {
+	s.timeouts.Store(&timeouts)
+}
+
+// Reload refreshes the key inventory if the underlying KeyManager supports it.
+func (s *Signer) Reload(ctx context.Context) error {
+	if err := s.begin(); err != nil {
+		return err
+	}
+	defer s.end()
+	if r, ok := s.keyManager.(Reloader); ok {
+		return r.Reload(ctx)
+	}
+	return nil
+}
+
+// begin registers an operation using the KeyManager or the account store. It fails once
+// Close was called. Every successful begin must be paired with end.
+func (s *Signer) begin() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	s.inflight.Add(1)
+	s.active.Add(1)
+	return nil
+}
+
+// end marks an operation registered with begin as finished.
+func (s *Signer) end() {
+	s.active.Add(-1)
+	s.inflight.Done()
+}
+
+// withTimeout derives a context bounded by the given timeout, if any.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// GetAccounts returns the active accounts managed by the underlying KeyManager.
+// Disabled and archived accounts are omitted.
+func (s *Signer) GetAccounts(ctx context.Context) []common.Address {
+	if s.begin() != nil {
+		return nil
+	}
+	defer s.end()
+	ctx, cancel := withTimeout(ctx, s.timeouts.Load().List)
+	defer cancel()
+
+	addresses := s.keyManager.GetAccounts(ctx)
+	states, err := s.accountStates()
+	if err != nil {
+		log.Printf("Warning: failed to read account states, listing no accounts: %v", err)
+		return nil
+	}
+
+	var active []common.Address
+	for _, addr := range addresses {
+		if state, ok := states[addr]; !ok || state == store.StateActive {
+			active = append(active, addr)
+		}
+	}
+	return active
+}
+
+// CreateKey creates a new account in the KeyManager, records meta for it and returns its address.
+func (s *Signer) CreateKey(ctx context.Context, opts CreateKeyOptions, meta store.Metadata) (common.Address, error) {
+	if err := s.begin(); err != nil {
+		return common.Address{}, err
+	}
+	defer s.end()
+	ctx, cancel := withTimeout(ctx, s.timeouts.Load().Create)
+	defer cancel()
+
+	address, err := s.keyManager.CreateKey(ctx, opts)
+	if err != nil {
+		return common.Address{}, err
+	}
+	s.recordNewAccount(address, meta)
+	return address, nil
+}
+
+// ImportKey imports an existing private key into the KeyManager and records meta for it.
+func (s *Signer) ImportKey(ctx context.Context, privateKey *ecdsa.PrivateKey, opts CreateKeyOptions, meta store.Metadata) (common.Address, error) {
+	if err := s.begin(); err != nil {
+		return common.Address{}, err
+	}
+	defer s.end()
+	importer, ok := s.keyManager.(Importer)
+	if !ok {
+		return common.Address{}, fmt.Errorf("import key: %w", ErrNotSupported)
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Load().Create)
+	defer cancel()
+
+	address, err := importer.ImportKey(ctx, privateKey, opts)
+	if err != nil {
+		return common.Address{}, err
+	}
+	s.recordNewAccount(address, meta)
+	return address, nil
+}
+
+// ExportKey exports the key for address as a keystore JSON encrypted with password.
+func (s *Signer) ExportKey(ctx context.Context, address common.Address, password string) ([]byte, error) {
+	if err := s.begin(); err != nil {
+		return nil, err
 	}
+	defer s.end()
+	exporter, ok := s.keyManager.(Exporter)
+	if !ok {
+		return nil, fmt.Errorf("export key: %w", ErrNotSupported)
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Load().Create)
+	defer cancel()
+	return exporter.ExportKey(ctx, address, password)
+}
+
+// UnlockAccount makes a locked account usable for signing for ttl and returns when it
+// will be locked again. A zero ttl uses the backend default.
+func (s *Signer) UnlockAccount(ctx context.Context, address common.Address, password string, ttl time.Duration) (time.Time, error) {
+	if err := s.begin(); err != nil {
+		return time.Time{}, err
+	}
+	defer s.end()
+	unlocker, ok := s.keyManager.(Unlocker)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unlock account: %w", ErrNotSupported)
+	}
+	if err := s.checkActive(address); errors.Is(err, ErrAccountArchived) {
+		return time.Time{}, err
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Load().Sign)
+	defer cancel()
+	return unlocker.Unlock(ctx, address, password, ttl)
 }
 
-// GetAccounts returns the list of accounts managed by the underlying KeyManager.
-func (s *Signer) GetAccounts() []common.Address {
-	return s.keyManager.GetAccounts()
+// LockAccount wipes the decrypted key of an unlocked account from memory.
+func (s *Signer) LockAccount(ctx context.Context, address common.Address) error {
+	if err := s.begin(); err != nil {
+		return err
+	}
+	defer s.end()
+	unlocker, ok := s.keyManager.(Unlocker)
+	if !ok {
+		return fmt.Errorf("lock account: %w", ErrNotSupported)
+	}
+	return unlocker.Lock(ctx, address)
+}
+
+// RotatePassword re-encrypts the key material held by the KeyManager with a new password.
+// Rotation is not bounded by the per-operation timeouts since it re-encrypts every key.
+func (s *Signer) RotatePassword(ctx context.Context, current, next string, scrypt *ScryptParams) (int, error) {
+	if err := s.begin(); err != nil {
+		return 0, err
+	}
+	defer s.end()
+	rotator, ok := s.keyManager.(PasswordRotator)
+	if !ok {
+		return 0, fmt.Errorf("rotate password: %w", ErrNotSupported)
+	}
+	return rotator.RotatePassword(ctx, current, next, scrypt)
 }
 
-// CreateKey creates a new account in the KeyManager and returns its address.
-func (s *Signer) CreateKey() (common.Address, error) {
-	return s.keyManager.CreateKey()
+// DeleteKey permanently removes the key for address from the KeyManager.
+func (s *Signer) DeleteKey(ctx context.Context, address common.Address) error {
+	if err := s.begin(); err != nil {
+		return err
+	}
+	defer s.end()
+	deleter, ok := s.keyManager.(Deleter)
+	if !ok {
+		return fmt.Errorf("delete key: %w", ErrNotSupported)
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Load().Create)
+	defer cancel()
+
+	if err := deleter.DeleteKey(ctx, address); err != nil {
+		return err
+	}
+	return s.accounts.DeleteAccount(address)
 }
 
 // SignTx signs a transaction with the specified account.
-func (s *Signer) SignTx(address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
-	return s.keyManager.SignTx(address, tx, chainID)
+func (s *Signer) SignTx(ctx context.Context, address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if err := s.begin(); err != nil {
+		return nil, err
+	}
+	defer s.end()
+	if err := s.checkActive(address); err != nil {
+		return nil, err
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Load().Sign)
+	defer cancel()
+	return s.keyManager.SignTx(ctx, address, tx, chainID)
 }
 
 // SignMessage signs a message with the specified account.
-func (s *Signer) SignMessage(address common.Address, message []byte) ([]byte, error) {
-	return s.keyManager.SignMessage(address, message)
+func (s *Signer) SignMessage(ctx context.Context, address common.Address, message []byte) ([]byte, error) {
+	if err := s.begin(); err != nil {
+		return nil, err
+	}
+	defer s.end()
+	if err := s.checkActive(address); err != nil {
+		return nil, err
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Load().Sign)
+	defer cancel()
+	return s.keyManager.SignMessage(ctx, address, message)
+}
+
+// SignHash signs a precomputed 32-byte hash with the specified account.
+func (s *Signer) SignHash(ctx context.Context, address common.Address, hash []byte) ([]byte, error) {
+	if err := s.begin(); err != nil {
+		return nil, err
+	}
+	defer s.end()
+	hashSigner, ok := s.keyManager.(HashSigner)
+	if !ok {
+		return nil, fmt.Errorf("sign hash: %w", ErrNotSupported)
+	}
+	if len(hash) != common.HashLength {
+		return nil, fmt.Errorf("sign hash: hash must be %d bytes, got %d", common.HashLength, len(hash))
+	}
+	if err := s.checkActive(address); err != nil {
+		return nil, err
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Load().Sign)
+	defer cancel()
+	return hashSigner.SignHash(ctx, address, hash)
+}
+
+// SignTypedData signs EIP-712 typed data with the specified account. It returns the
+// signature, with V being 27 or 28 as eth_signTypedData_v4 does, and the signed hash.
+func (s *Signer) SignTypedData(ctx context.Context, address common.Address, typedData apitypes.TypedData) ([]byte, []byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidTypedData, err)
+	}
+	signature, err := s.SignHash(ctx, address, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, hash, nil
+}
+
+// PublicKey returns the public key of the specified account.
+func (s *Signer) PublicKey(ctx context.Context, address common.Address) (*ecdsa.PublicKey, error) {
+	if err := s.begin(); err != nil {
+		return nil, err
+	}
+	defer s.end()
+	provider, ok := s.keyManager.(PublicKeyProvider)
+	if !ok {
+		return nil, fmt.Errorf("public key: %w", ErrNotSupported)
+	}
+	ctx, cancel := withTimeout(ctx, s.timeouts.Load().List)
+	defer cancel()
+	return provider.PublicKey(ctx, address)
+}
+
+// HealthCheck reports the health of the underlying KeyManager's components. A closed
+// Signer reports itself unhealthy, so that readiness fails while the server drains.
+func (s *Signer) HealthCheck(ctx context.Context) []ComponentHealth {
+	if err := s.begin(); err != nil {
+		return []ComponentHealth{{Name: "signer", Message: err.Error()}}
+	}
+	defer s.end()
+	ctx, cancel := withTimeout(ctx, s.timeouts.Load().List)
+	defer cancel()
+	return s.keyManager.HealthCheck(ctx)
+}
+
+// Close releases the resources held by the underlying KeyManager and the account store.
+// New operations fail with ErrClosed; Close waits for the ones in flight to finish, even
+// past a drain timeout, so that no key is wiped while a request is still using it.
+func (s *Signer) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	if n := s.active.Load(); n > 0 {
+		log.Printf("Waiting for %d in-flight key operations to finish before wiping keys", n)
+	}
+	s.inflight.Wait()
+
+	kmErr := s.keyManager.Close()
+	storeErr := s.accounts.Close()
+	return errors.Join(kmErr, storeErr)
 }
@@ -0,0 +1,82 @@
+package signer_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/signer/signertest"
+)
+
+// blockingKeyManager signs messages only once release is closed and records when it was
+// closed.
+type blockingKeyManager struct {
+	*signertest.KeyManager
+	started chan struct{}
+	release chan struct{}
+	closed  chan struct{}
+}
+
+func (km *blockingKeyManager) SignMessage(ctx context.Context, address common.Address, message []byte) ([]byte, error) {
+	close(km.started)
+	<-km.release
+	select {
+	case <-km.closed:
+		return nil, errors.New("key wiped while in use")
+	default:
+		return km.KeyManager.SignMessage(ctx, address, message)
+	}
+}
+
+func (km *blockingKeyManager) Close() error {
+	close(km.closed)
+	return nil
+}
+
+func TestCloseWaitsForInFlightOperations(t *testing.T) {
+	km := &blockingKeyManager{
+		KeyManager: signertest.NewKeyManager(t, 1),
+		started:    make(chan struct{}),
+		release:    make(chan struct{}),
+		closed:     make(chan struct{}),
+	}
+	address := km.GetAccounts(t.Context())[0]
+	s := signertest.NewSigner(t, km)
+
+	signErr := make(chan error, 1)
+	go func() {
+		_, err := s.SignMessage(context.Background(), address, []byte("hello"))
+		signErr <- err
+	}()
+	<-km.started
+
+	closeErr := make(chan error, 1)
+	go func() { closeErr <- s.Close() }()
+
+	select {
+	case <-km.closed:
+		t.Fatal("key manager closed while a signing operation was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := s.SignTx(context.Background(), address, types.NewTx(&types.LegacyTx{}), big.NewInt(1)); !errors.Is(err, signer.ErrClosed) {
+		t.Fatalf("SignTx after Close: got %v, want ErrClosed", err)
+	}
+
+	close(km.release)
+	if err := <-signErr; err != nil {
+		t.Fatalf("in-flight SignMessage: %v", err)
+	}
+	if err := <-closeErr; err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	select {
+	case <-km.closed:
+	default:
+		t.Fatal("key manager not closed")
+	}
+}
@@ -0,0 +1,137 @@
+// Package signertest provides an in-memory signer.KeyManager and signer.Signer for the
+// tests of the packages built on top of them.
+package signertest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/xueqianLu/ethsigner/internal/signer"
+	"github.com/xueqianLu/ethsigner/internal/store"
+)
+
+// KeyManager keeps its keys in memory. It is safe for concurrent use.
+type KeyManager struct {
+	mu   sync.RWMutex
+	keys map[common.Address]*ecdsa.PrivateKey
+}
+
+var (
+	_ signer.KeyManager        = (*KeyManager)(nil)
+	_ signer.HashSigner        = (*KeyManager)(nil)
+	_ signer.PublicKeyProvider = (*KeyManager)(nil)
+)
+
+// NewKeyManager returns a KeyManager holding n new keys.
+func NewKeyManager(t testing.TB, n int) *KeyManager {
+	t.Helper()
+	km := &KeyManager{keys: make(map[common.Address]*ecdsa.PrivateKey)}
+	for range n {
+		if _, err := km.CreateKey(context.Background(), signer.CreateKeyOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return km
+}
+
+// NewSigner returns a Signer over km with an empty account store. It is closed when the
+// test ends.
+func NewSigner(t testing.TB, km signer.KeyManager) *signer.Signer {
+	t.Helper()
+	accounts, err := store.Open(filepath.Join(t.TempDir(), "accounts.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := signer.NewSigner(km, accounts, signer.Timeouts{})
+	t.Cleanup(func() {
+		if err := s.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return s
+}
+
+// Key returns the private key of address, or nil if km does not hold it.
+func (km *KeyManager) Key(address common.Address) *ecdsa.PrivateKey {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	return km.keys[address]
+}
+
+// GetAccounts returns the addresses of the keys, sorted.
+func (km *KeyManager) GetAccounts(ctx context.Context) []common.Address {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	addresses := make([]common.Address, 0, len(km.keys))
+	for address := range km.keys {
+		addresses = append(addresses, address)
+	}
+	slices.SortFunc(addresses, func(a, b common.Address) int { return a.Cmp(b) })
+	return addresses
+}
+
+// CreateKey generates a new key.
+func (km *KeyManager) CreateKey(ctx context.Context, opts signer.CreateKeyOptions) (common.Address, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return common.Address{}, err
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	km.keys[address] = key
+	return address, nil
+}
+
+// SignTx signs tx with the key of address.
+func (km *KeyManager) SignTx(ctx context.Context, address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	key := km.Key(address)
+	if key == nil {
+		return nil, signer.ErrAccountNotFound
+	}
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+}
+
+// SignMessage signs the EIP-191 hash of message with the key of address.
+func (km *KeyManager) SignMessage(ctx context.Context, address common.Address, message []byte) ([]byte, error) {
+	key := km.Key(address)
+	if key == nil {
+		return nil, signer.ErrAccountNotFound
+	}
+	return crypto.Sign(accounts.TextHash(message), key)
+}
+
+// SignHash signs hash as is with the key of address.
+func (km *KeyManager) SignHash(ctx context.Context, address common.Address, hash []byte) ([]byte, error) {
+	key := km.Key(address)
+	if key == nil {
+		return nil, signer.ErrAccountNotFound
+	}
+	return crypto.Sign(hash, key)
+}
+
+// PublicKey returns the public key of address.
+func (km *KeyManager) PublicKey(ctx context.Context, address common.Address) (*ecdsa.PublicKey, error) {
+	key := km.Key(address)
+	if key == nil {
+		return nil, signer.ErrAccountNotFound
+	}
+	return &key.PublicKey, nil
+}
+
+// HealthCheck reports a single healthy component.
+func (km *KeyManager) HealthCheck(ctx context.Context) []signer.ComponentHealth {
+	return []signer.ComponentHealth{{Name: "memory", Healthy: true}}
+}
+
+// Close implements signer.KeyManager.
+func (km *KeyManager) Close() error { return nil }
@@ -0,0 +1,307 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+const (
+	// vaultLoginTimeout bounds a single login request.
+	vaultLoginTimeout = 30 * time.Second
+	// Re-authentication after a failed login backs off exponentially between these bounds.
+	vaultLoginMinBackoff = time.Second
+	vaultLoginMaxBackoff = time.Minute
+	// A token that ends within vaultLoginMinInterval of its login, e.g. because Vault
+	// grants very short TTLs or rejects every renewal, delays the next login by the same
+	// backoff, so it cannot turn into a login loop.
+	vaultLoginMinInterval = 10 * time.Second
+
+	// DefaultKubernetesJWTPath is where Kubernetes mounts the service account token.
+	DefaultKubernetesJWTPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// VaultLoginMethod logs in to Vault and returns the resulting auth secret.
+type VaultLoginMethod interface {
+	// Name identifies the method in logs and health output.
+	Name() string
+	Login(ctx context.Context, client *api.Client) (*api.Secret, error)
+}
+
+// AppRoleLogin authenticates with Vault's AppRole auth method.
+type AppRoleLogin struct {
+	MountPath string // defaults to "approle"
+	RoleID    string
+	SecretID  string
+}
+
+// Name implements VaultLoginMethod.
+func (a AppRoleLogin) Name() string { return "approle" }
+
+// Login implements VaultLoginMethod.
+func (a AppRoleLogin) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	return vaultLogin(ctx, client, a.MountPath, "approle", map[string]interface{}{
+		"role_id":   a.RoleID,
+		"secret_id": a.SecretID,
+	})
+}
+
+// KubernetesLogin authenticates with Vault's Kubernetes auth method using the pod's
+// service account token. The token is read on every login, so rotated projected
+// tokens are picked up.
+type KubernetesLogin struct {
+	MountPath string // defaults to "kubernetes"
+	Role      string
+	JWTPath   string // defaults to DefaultKubernetesJWTPath
+}
+
+// Name implements VaultLoginMethod.
+func (k KubernetesLogin) Name() string { return "kubernetes" }
+
+// Login implements VaultLoginMethod.
+func (k KubernetesLogin) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	jwtPath := k.JWTPath
+	if jwtPath == "" {
+		jwtPath = DefaultKubernetesJWTPath
+	}
+	jwt, err := os.ReadFile(jwtPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account token: %w", err)
+	}
+	return vaultLogin(ctx, client, k.MountPath, "kubernetes", map[string]interface{}{
+		"role": k.Role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+}
+
+// vaultLogin posts data to auth/<mountPath>/login and checks that a token came back.
+func vaultLogin(ctx context.Context, client *api.Client, mountPath, defaultMount string, data map[string]interface{}) (*api.Secret, error) {
+	if mountPath == "" {
+		mountPath = defaultMount
+	}
+	mountPath = strings.Trim(mountPath, "/")
+	secret, err := client.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", mountPath), data)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return nil, errors.New("login response contained no token")
+	}
+	return secret, nil
+}
+
+// VaultAuthenticator keeps a Vault client logged in: it logs in with a VaultLoginMethod,
+// renews the token in the background for as long as Vault allows, and logs in again
+// when renewal fails or the token reaches its maximum TTL.
+type VaultAuthenticator struct {
+	client *api.Client
+	method VaultLoginMethod
+
+	mu        sync.Mutex
+	expiresAt time.Time
+	lastLogin time.Time
+	lastRenew time.Time
+	lastError error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewVaultAuthenticator creates a VaultAuthenticator for client. Call Start to log in.
+func NewVaultAuthenticator(client *api.Client, method VaultLoginMethod) *VaultAuthenticator {
+	return &VaultAuthenticator{
+		client: client,
+		method: method,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start logs in and starts renewing the token in the background. It fails if the
+// initial login fails, so a misconfigured signer does not start.
+func (a *VaultAuthenticator) Start(ctx context.Context) error {
+	secret, err := a.login(ctx)
+	if err != nil {
+		close(a.done) // nothing to wait for in Stop
+		return fmt.Errorf("vault %s login failed: %w", a.method.Name(), err)
+	}
+	go a.run(secret)
+	return nil
+}
+
+// Stop ends background renewal. It does not revoke the token.
+func (a *VaultAuthenticator) Stop() {
+	select {
+	case <-a.stop:
+		return
+	default:
+		close(a.stop)
+	}
+	<-a.done
+}
+
+// login performs one login and installs the new token on the client.
+func (a *VaultAuthenticator) login(ctx context.Context) (*api.Secret, error) {
+	ctx, cancel := context.WithTimeout(ctx, vaultLoginTimeout)
+	defer cancel()
+
+	secret, err := a.method.Login(ctx, a.client)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err != nil {
+		a.lastError = err
+		return nil, err
+	}
+	a.client.SetToken(secret.Auth.ClientToken)
+	now := time.Now()
+	a.lastLogin = now
+	a.lastError = nil
+	a.expiresAt = leaseExpiry(now, secret.Auth.LeaseDuration)
+	log.Printf("Logged in to Vault with %s auth, token valid for %ds (renewable: %t)",
+		a.method.Name(), secret.Auth.LeaseDuration, secret.Auth.Renewable)
+	return secret, nil
+}
+
+// leaseExpiry returns when a lease of the given seconds granted at start ends. A zero
+// lease never expires, like that of a root token, and yields the zero time.
+func leaseExpiry(start time.Time, leaseSeconds int) time.Time {
+	if leaseSeconds == 0 {
+		return time.Time{}
+	}
+	return start.Add(time.Duration(leaseSeconds) * time.Second)
+}
+
+// run renews the token until renewal stops working, then logs in again, until Stop.
+func (a *VaultAuthenticator) run(secret *api.Secret) {
+	defer close(a.done)
+
+	backoff := vaultLoginMinBackoff
+	for {
+		issued := time.Now()
+		if err := a.watch(secret); err != nil {
+			log.Printf("Warning: Vault token renewal ended: %v; logging in again", err)
+		} else {
+			select {
+			case <-a.stop:
+				return
+			default:
+				log.Println("Vault token reached its maximum TTL; logging in again")
+			}
+		}
+
+		var delay time.Duration
+		if time.Since(issued) < vaultLoginMinInterval {
+			delay, backoff = backoff, min(backoff*2, vaultLoginMaxBackoff)
+		} else {
+			backoff = vaultLoginMinBackoff
+		}
+		var ok bool
+		if secret, ok = a.relogin(delay); !ok {
+			return
+		}
+	}
+}
+
+// watch renews the token of secret until the lifetime watcher gives up or Stop is
+// called. It returns the watcher's error, if any. A token without a lease never expires
+// and is kept until Stop.
+func (a *VaultAuthenticator) watch(secret *api.Secret) error {
+	if secret.Auth.LeaseDuration == 0 {
+		<-a.stop
+		return nil
+	}
+	watcher, err := a.client.NewLifetimeWatcher(&api.LifetimeWatcherInput{Secret: secret})
+	if err != nil {
+		return err
+	}
+	go watcher.Start()
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-a.stop:
+			return nil
+		case err := <-watcher.DoneCh():
+			if err != nil {
+				a.mu.Lock()
+				a.lastError = err
+				a.mu.Unlock()
+			}
+			return err
+		case renewal := <-watcher.RenewCh():
+			a.mu.Lock()
+			a.lastRenew = renewal.RenewedAt
+			a.lastError = nil
+			if renewal.Secret != nil && renewal.Secret.Auth != nil {
+				a.expiresAt = leaseExpiry(renewal.RenewedAt, renewal.Secret.Auth.LeaseDuration)
+			}
+			a.mu.Unlock()
+		}
+	}
+}
+
+// relogin logs in again after delay, backing off between failures. It returns false if
+// Stop was called before a login succeeded.
+func (a *VaultAuthenticator) relogin(delay time.Duration) (*api.Secret, bool) {
+	select {
+	case <-a.stop:
+		return nil, false
+	case <-time.After(delay):
+	}
+
+	backoff := vaultLoginMinBackoff
+	for {
+		secret, err := a.login(context.Background())
+		if err == nil {
+			return secret, true
+		}
+		log.Printf("Warning: Vault %s login failed, retrying in %s: %v", a.method.Name(), backoff, err)
+
+		select {
+		case <-a.stop:
+			return nil, false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, vaultLoginMaxBackoff)
+	}
+}
+
+// HealthCheck reports the authentication state. It is unhealthy once the token has
+// expired, which only happens when both renewal and re-authentication keep failing.
+func (a *VaultAuthenticator) HealthCheck() ComponentHealth {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	status := ComponentHealth{
+		Name:    "vault_auth",
+		Healthy: true,
+		Details: map[string]interface{}{
+			"method":    a.method.Name(),
+			"lastLogin": a.lastLogin.UTC().Format(time.RFC3339),
+		},
+	}
+	if !a.expiresAt.IsZero() {
+		status.Details["expiresAt"] = a.expiresAt.UTC().Format(time.RFC3339)
+	}
+	if !a.lastRenew.IsZero() {
+		status.Details["lastRenewal"] = a.lastRenew.UTC().Format(time.RFC3339)
+	}
+	if a.lastError != nil {
+		status.Message = a.lastError.Error()
+	}
+	if !a.expiresAt.IsZero() && time.Now().After(a.expiresAt) {
+		status.Healthy = false
+		if status.Message == "" {
+			status.Message = "vault token expired"
+		}
+	}
+	return status
+}
@@ -0,0 +1,276 @@
+package signer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds, failing the test after timeout.
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// startAuthenticator starts a VaultAuthenticator against f and stops it when the test ends.
+func startAuthenticator(t *testing.T, f *fakeVault, method VaultLoginMethod) *VaultAuthenticator {
+	t.Helper()
+	auth := NewVaultAuthenticator(f.client(""), method)
+	if err := auth.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(auth.Stop)
+	return auth
+}
+
+func TestVaultAuthenticatorAppRoleRenews(t *testing.T) {
+	f := newFakeVault(t)
+	f.update(func(f *fakeVault) { f.renewable = true })
+	auth := startAuthenticator(t, f, AppRoleLogin{RoleID: "role", SecretID: "secret"})
+
+	logins := f.loginAttempts()
+	if len(logins) != 1 || logins[0].mount != "approle" {
+		t.Fatalf("logins = %+v, want one approle login", logins)
+	}
+	if logins[0].data["role_id"] != "role" || logins[0].data["secret_id"] != "secret" {
+		t.Errorf("login data = %v", logins[0].data)
+	}
+	if got := auth.client.Token(); got != logins[0].token {
+		t.Errorf("client token = %q, want %q", got, logins[0].token)
+	}
+
+	// The lifetime watcher renews the token it was given right away.
+	waitFor(t, 5*time.Second, "token renewal", func() bool {
+		_, renewed := auth.HealthCheck().Details["lastRenewal"]
+		return renewed
+	})
+	f.update(func(f *fakeVault) {
+		if len(f.renewals) == 0 || f.renewals[0] != logins[0].token {
+			t.Errorf("renewed tokens = %v, want %s", f.renewals, logins[0].token)
+		}
+	})
+	health := auth.HealthCheck()
+	if !health.Healthy || health.Details["method"] != "approle" {
+		t.Errorf("HealthCheck = %+v, want healthy approle", health)
+	}
+	if n := len(f.loginAttempts()); n != 1 {
+		t.Errorf("%d logins, want renewal without logging in again", n)
+	}
+}
+
+func TestVaultAuthenticatorKubernetes(t *testing.T) {
+	f := newFakeVault(t)
+	jwtPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(jwtPath, []byte("service-account-jwt\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	auth := startAuthenticator(t, f, KubernetesLogin{MountPath: "/k8s-prod/", Role: "signer", JWTPath: jwtPath})
+
+	logins := f.loginAttempts()
+	if len(logins) != 1 || logins[0].mount != "k8s-prod" {
+		t.Fatalf("logins = %+v, want one login at auth/k8s-prod", logins)
+	}
+	if logins[0].data["role"] != "signer" || logins[0].data["jwt"] != "service-account-jwt" {
+		t.Errorf("login data = %v", logins[0].data)
+	}
+	if got := auth.client.Token(); got != logins[0].token {
+		t.Errorf("client token = %q, want %q", got, logins[0].token)
+	}
+}
+
+func TestVaultAuthenticatorStartFails(t *testing.T) {
+	f := newFakeVault(t)
+	f.update(func(f *fakeVault) { f.failLogins = 1 })
+	auth := NewVaultAuthenticator(f.client(""), AppRoleLogin{RoleID: "role", SecretID: "wrong"})
+	err := auth.Start(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "invalid role or secret ID") {
+		t.Fatalf("Start = %v, want the login error", err)
+	}
+	auth.Stop() // must not block
+}
+
+func TestVaultAuthenticatorReloginAfterExpiry(t *testing.T) {
+	f := newFakeVault(t)
+	// A non-renewable token with a one second TTL has to be replaced by a new login.
+	f.update(func(f *fakeVault) { f.lease = 1 })
+	auth := startAuthenticator(t, f, AppRoleLogin{RoleID: "role", SecretID: "secret"})
+	f.update(func(f *fakeVault) { f.failLogins = 2 })
+
+	// While logins keep failing the token expires and the authenticator reports it
+	// along with the login error.
+	waitFor(t, 5*time.Second, "expired token and login error to be reported", func() bool {
+		health := auth.HealthCheck()
+		return !health.Healthy && strings.Contains(health.Message, "invalid role or secret ID")
+	})
+
+	waitFor(t, 10*time.Second, "a successful login after the failures", func() bool {
+		return len(f.loginAttempts()) >= 4
+	})
+	logins := f.loginAttempts()
+	if logins[1].token != "" || logins[2].token != "" || logins[3].token == "" {
+		t.Fatalf("logins = %+v, want two failures then a success", logins)
+	}
+	// Failed logins back off exponentially, starting at vaultLoginMinBackoff.
+	const slack = 50 * time.Millisecond
+	if gap := logins[2].at.Sub(logins[1].at); gap < vaultLoginMinBackoff-slack {
+		t.Errorf("first retry after %s, want at least %s", gap, vaultLoginMinBackoff)
+	}
+	if gap := logins[3].at.Sub(logins[2].at); gap < 2*vaultLoginMinBackoff-slack {
+		t.Errorf("second retry after %s, want at least %s", gap, 2*vaultLoginMinBackoff)
+	}
+
+	waitFor(t, time.Second, "the new token to be installed", func() bool {
+		return auth.HealthCheck().Healthy
+	})
+	if got := auth.client.Token(); got == logins[0].token {
+		t.Errorf("client still uses the expired token %q", got)
+	}
+}
+
+func TestVaultAuthenticatorReloginWhenRenewalFails(t *testing.T) {
+	f := newFakeVault(t)
+	f.update(func(f *fakeVault) {
+		f.lease = 2
+		f.renewable = true
+		f.failRenewals = true
+	})
+	auth := startAuthenticator(t, f, AppRoleLogin{RoleID: "role", SecretID: "secret"})
+
+	waitFor(t, 5*time.Second, "a second login", func() bool {
+		return len(f.loginAttempts()) >= 2
+	})
+	logins := f.loginAttempts()
+	waitFor(t, time.Second, "the new token to be installed", func() bool {
+		return auth.client.Token() == logins[1].token
+	})
+}
+
+func TestVaultAuthenticatorZeroLease(t *testing.T) {
+	f := newFakeVault(t)
+	// A token without a lease, like a root token, never expires and needs no renewal.
+	f.update(func(f *fakeVault) { f.lease = 0 })
+	auth := startAuthenticator(t, f, AppRoleLogin{RoleID: "role", SecretID: "secret"})
+
+	time.Sleep(2 * vaultLoginMinBackoff)
+	if n := len(f.loginAttempts()); n != 1 {
+		t.Errorf("%d logins, want the token to be kept", n)
+	}
+	f.update(func(f *fakeVault) {
+		if len(f.renewals) != 0 {
+			t.Errorf("renewed tokens = %v, want none", f.renewals)
+		}
+	})
+	health := auth.HealthCheck()
+	if !health.Healthy {
+		t.Errorf("HealthCheck = %+v, want healthy", health)
+	}
+	if _, ok := health.Details["expiresAt"]; ok {
+		t.Errorf("HealthCheck details = %v, want no expiry", health.Details)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		auth.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop blocked")
+	}
+}
+
+func TestVaultAuthenticatorShortLivedTokensBackOff(t *testing.T) {
+	f := newFakeVault(t)
+	// Every login succeeds, but each token ends within a second.
+	f.update(func(f *fakeVault) { f.lease = 1 })
+	startAuthenticator(t, f, AppRoleLogin{RoleID: "role", SecretID: "secret"})
+
+	waitFor(t, 10*time.Second, "three logins", func() bool {
+		return len(f.loginAttempts()) >= 3
+	})
+	logins := f.loginAttempts()
+	const slack = 50 * time.Millisecond
+	if gap := logins[1].at.Sub(logins[0].at); gap < vaultLoginMinBackoff-slack {
+		t.Errorf("second login after %s, want at least %s", gap, vaultLoginMinBackoff)
+	}
+	if gap := logins[2].at.Sub(logins[1].at); gap < 2*vaultLoginMinBackoff-slack {
+		t.Errorf("third login after %s, want at least %s", gap, 2*vaultLoginMinBackoff)
+	}
+}
+
+func TestVaultAuthenticatorStop(t *testing.T) {
+	f := newFakeVault(t)
+	f.update(func(f *fakeVault) { f.lease = 1 })
+	auth := startAuthenticator(t, f, AppRoleLogin{RoleID: "role", SecretID: "secret"})
+	// Keep the authenticator in its login backoff.
+	f.update(func(f *fakeVault) { f.failLogins = 1000 })
+	waitFor(t, 5*time.Second, "a failed login", func() bool {
+		return len(f.loginAttempts()) >= 2
+	})
+
+	stopped := make(chan struct{})
+	go func() {
+		auth.Stop()
+		auth.Stop() // idempotent
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not interrupt the login backoff")
+	}
+
+	n := len(f.loginAttempts())
+	time.Sleep(2 * vaultLoginMinBackoff)
+	if got := len(f.loginAttempts()); got != n {
+		t.Errorf("%d logins after Stop", got-n)
+	}
+}
+
+func TestVaultKeyManagerHealthCheck(t *testing.T) {
+	f := newFakeVault(t)
+	client := f.client("")
+	km, err := NewVaultKeyManager(client, "transit", VaultOptions{
+		Auth: NewVaultAuthenticator(client, AppRoleLogin{RoleID: "role", SecretID: "secret"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer km.Close()
+
+	health := func() map[string]ComponentHealth {
+		components := make(map[string]ComponentHealth)
+		for _, c := range km.HealthCheck(t.Context()) {
+			components[c.Name] = c
+		}
+		return components
+	}
+	components := health()
+	for _, name := range []string{"vault_seal", "vault_auth", "vault_token", "vault_transit"} {
+		c, ok := components[name]
+		if !ok || !c.Healthy {
+			t.Errorf("%s = %+v, want healthy", name, c)
+		}
+	}
+
+	f.update(func(f *fakeVault) {
+		f.sealed = true
+		clear(f.tokens) // revoked
+	})
+	components = health()
+	if c := components["vault_seal"]; c.Healthy || c.Message != "vault is sealed" {
+		t.Errorf("vault_seal = %+v, want sealed", c)
+	}
+	if c := components["vault_token"]; c.Healthy || !strings.Contains(c.Message, "permission denied") {
+		t.Errorf("vault_token = %+v, want a failed lookup", c)
+	}
+}
@@ -0,0 +1,338 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/xueqianLu/ethsigner/pkg/keywrap"
+)
+
+// fakeVault is an in-memory stand-in for the parts of the Vault HTTP API the signer uses.
+type fakeVault struct {
+	t      *testing.T
+	server *httptest.Server
+	mux    *http.ServeMux
+
+	mu         sync.Mutex
+	keys       map[string]*ecdsa.PublicKey // transit keys; nil when the key has no readable public key
+	deleted    []string                    // transit keys deleted through the API
+	wrapping   *rsa.PrivateKey
+	importKey  *ecdsa.PublicKey // public key reported for the next imported key
+	importData []byte           // key material of the last import
+
+	sealed bool
+	// Tokens issued by logins last lease seconds and can be renewed if renewable.
+	lease        int
+	renewable    bool
+	failLogins   int // number of upcoming logins to reject
+	failRenewals bool
+	logins       []fakeLogin
+	renewals     []string             // tokens renewed
+	tokens       map[string]time.Time // issued token -> expiry
+
+	// intercept, if set, is called before each request is served, without f.mu held.
+	intercept func(r *http.Request)
+}
+
+// fakeLogin records a login attempt.
+type fakeLogin struct {
+	mount string
+	data  map[string]any
+	at    time.Time
+	token string // empty if the login was rejected
+}
+
+func newFakeVault(t *testing.T) *fakeVault {
+	t.Helper()
+	wrapping, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeVault{
+		t:        t,
+		mux:      http.NewServeMux(),
+		keys:     make(map[string]*ecdsa.PublicKey),
+		wrapping: wrapping,
+		lease:    3600,
+		tokens:   make(map[string]time.Time),
+	}
+	f.mux.HandleFunc("GET /v1/sys/mounts", func(w http.ResponseWriter, r *http.Request) {
+		f.reply(w, map[string]any{"transit/": map[string]any{"type": "transit"}})
+	})
+	f.mux.HandleFunc("GET /v1/transit/keys", f.listKeys)
+	f.mux.HandleFunc("GET /v1/transit/keys/{name}", f.readKey)
+	f.mux.HandleFunc("PUT /v1/transit/keys/{name}", f.createKey)
+	f.mux.HandleFunc("PUT /v1/transit/keys/{name}/import", f.importTransitKey)
+	f.mux.HandleFunc("PUT /v1/transit/keys/{name}/config", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	f.mux.HandleFunc("DELETE /v1/transit/keys/{name}", f.deleteKey)
+	f.mux.HandleFunc("GET /v1/transit/wrapping_key", func(w http.ResponseWriter, r *http.Request) {
+		der, err := x509.MarshalPKIXPublicKey(&f.wrapping.PublicKey)
+		if err != nil {
+			f.t.Error(err)
+		}
+		f.reply(w, map[string]any{"public_key": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))})
+	})
+	f.mux.HandleFunc("GET /v1/sys/seal-status", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.replySecret(w, map[string]any{"type": "shamir", "sealed": f.sealed, "version": "1.17.0"})
+	})
+	f.mux.HandleFunc("PUT /v1/auth/{mount}/login", f.login)
+	f.mux.HandleFunc("PUT /v1/auth/token/renew-self", f.renewSelf)
+	f.mux.HandleFunc("GET /v1/auth/token/lookup-self", f.lookupSelf)
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		intercept := f.intercept
+		f.mu.Unlock()
+		if intercept != nil {
+			intercept(r)
+		}
+		f.mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// client returns a Vault client for the fake, authenticated with token.
+func (f *fakeVault) client(token string) *api.Client {
+	f.t.Helper()
+	cfg := api.DefaultConfig()
+	cfg.Address = f.server.URL
+	cfg.MaxRetries = 0
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	client.SetToken(token)
+	return client
+}
+
+// reply writes data as the data of a Vault response.
+func (f *fakeVault) reply(w http.ResponseWriter, data map[string]any) {
+	f.replySecret(w, map[string]any{"data": data})
+}
+
+func (f *fakeVault) replySecret(w http.ResponseWriter, secret map[string]any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(secret); err != nil {
+		f.t.Error(err)
+	}
+}
+
+func (f *fakeVault) notFound(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	w.Write([]byte(`{"errors":[]}`))
+}
+
+func (f *fakeVault) listKeys(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.keys) == 0 {
+		f.notFound(w)
+		return
+	}
+	var names []any
+	for name := range f.keys {
+		names = append(names, name)
+	}
+	f.reply(w, map[string]any{"keys": names})
+}
+
+func (f *fakeVault) readKey(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pub, ok := f.keys[r.PathValue("name")]
+	if !ok {
+		f.notFound(w)
+		return
+	}
+	data := map[string]any{"type": "ecdsa-p256"}
+	if pub != nil {
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			f.t.Error(err)
+		}
+		data["keys"] = map[string]any{
+			"1": map[string]any{"public_key": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))},
+		}
+	}
+	f.reply(w, data)
+}
+
+// createKey creates a transit key with a new P-256 key pair. Like Vault, it leaves an
+// existing key of the same name in place and warns.
+func (f *fakeVault) createKey(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := r.PathValue("name")
+	if _, ok := f.keys[name]; ok {
+		f.replySecret(w, map[string]any{"warnings": []string{fmt.Sprintf("key %s already existed", name)}})
+		return
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		f.t.Error(err)
+	}
+	f.keys[name] = &key.PublicKey
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeVault) importTransitKey(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sealed, err := base64.StdEncoding.DecodeString(body.Ciphertext)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	material, err := keywrap.Open(f.wrapping, sealed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.keys[r.PathValue("name")]; ok {
+		f.fail(w, http.StatusBadRequest, "the import path cannot be used with an existing key; use import-version to rotate an existing imported key")
+		return
+	}
+	f.importData = material
+	f.keys[r.PathValue("name")] = f.importKey
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeVault) deleteKey(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := r.PathValue("name")
+	delete(f.keys, name)
+	f.deleted = append(f.deleted, name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeVault) login(w http.ResponseWriter, r *http.Request) {
+	var data map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	attempt := fakeLogin{mount: r.PathValue("mount"), data: data, at: time.Now()}
+	if f.failLogins > 0 {
+		f.failLogins--
+		f.logins = append(f.logins, attempt)
+		f.fail(w, http.StatusBadRequest, "invalid role or secret ID")
+		return
+	}
+	attempt.token = fmt.Sprintf("token-%d", len(f.logins)+1)
+	f.logins = append(f.logins, attempt)
+	f.tokens[attempt.token] = f.expiry()
+	f.replySecret(w, map[string]any{"auth": f.auth(attempt.token)})
+}
+
+func (f *fakeVault) renewSelf(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token := r.Header.Get("X-Vault-Token")
+	if !f.validToken(token) {
+		f.fail(w, http.StatusForbidden, "permission denied")
+		return
+	}
+	if f.failRenewals {
+		f.fail(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	f.renewals = append(f.renewals, token)
+	f.tokens[token] = f.expiry()
+	f.replySecret(w, map[string]any{"auth": f.auth(token)})
+}
+
+func (f *fakeVault) lookupSelf(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token := r.Header.Get("X-Vault-Token")
+	if !f.validToken(token) {
+		f.fail(w, http.StatusForbidden, "permission denied")
+		return
+	}
+	f.reply(w, map[string]any{
+		"id":        token,
+		"ttl":       max(int(time.Until(f.tokens[token]).Seconds()), 0),
+		"renewable": f.renewable,
+	})
+}
+
+// auth returns the auth block of a response issuing token. f.mu must be held.
+func (f *fakeVault) auth(token string) map[string]any {
+	return map[string]any{"client_token": token, "lease_duration": f.lease, "renewable": f.renewable}
+}
+
+// expiry returns when a token issued or renewed now expires: after lease seconds, or
+// never for a zero lease. f.mu must be held.
+func (f *fakeVault) expiry() time.Time {
+	if f.lease == 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(time.Duration(f.lease) * time.Second)
+}
+
+// validToken reports whether token was issued and has not expired. f.mu must be held.
+func (f *fakeVault) validToken(token string) bool {
+	expiry, ok := f.tokens[token]
+	return ok && (expiry.IsZero() || time.Now().Before(expiry))
+}
+
+func (f *fakeVault) fail(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"errors": []string{msg}})
+}
+
+// loginAttempts returns the login attempts made so far.
+func (f *fakeVault) loginAttempts() []fakeLogin {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]fakeLogin(nil), f.logins...)
+}
+
+// update runs fn with the fake's state locked.
+func (f *fakeVault) update(fn func(f *fakeVault)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn(f)
+}
+
+// transitKeys returns the names of the transit keys held by the fake.
+func (f *fakeVault) transitKeys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for name := range f.keys {
+		names = append(names, name)
+	}
+	return names
+}
@@ -1,52 +1,123 @@
 package signer
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/base64"
 	"encoding/pem"
+	"errors"
 	"fmt"
-	"github.com/aws/smithy-go/rand"
 	"github.com/ethereum/go-ethereum/core/types"
 	"log"
 	"math/big"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
 	"github.com/hashicorp/vault/api"
+	"github.com/xueqianLu/ethsigner/pkg/keywrap"
 )
 
+// vaultCleanupTimeout bounds best-effort cleanup requests issued after a failed operation.
+const vaultCleanupTimeout = 5 * time.Second
+
+// vaultMissRefreshInterval limits how often a lookup of an unknown address refreshes the
+// key inventory, so requests for addresses that do not exist cannot flood Vault.
+const vaultMissRefreshInterval = 5 * time.Second
+
+// DefaultVaultKeyPrefix is prepended to generated transit key names when no prefix is configured.
+const DefaultVaultKeyPrefix = "eth-key-"
+
+// ErrInvalidKeyName is returned for a key name or prefix Vault would not accept in a path.
+var ErrInvalidKeyName = errors.New("invalid key name")
+
+// vaultKeyNamePattern restricts transit key names to characters that are safe in Vault
+// API paths and easy to pass around in shell scripts.
+var vaultKeyNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$`)
+
+// VaultOptions configures a VaultKeyManager.
+type VaultOptions struct {
+	// Auth logs the client in and keeps its token renewed; nil uses the client's token as is.
+	Auth *VaultAuthenticator
+	// RefreshInterval is how often the key inventory is re-read from Vault, picking up keys
+	// created or deleted by other signer replicas or by operators. Zero disables the
+	// periodic refresh; unknown addresses still trigger one.
+	RefreshInterval time.Duration
+	// KeyPrefix is prepended to the random UUID naming a new key. Empty uses DefaultVaultKeyPrefix.
+	KeyPrefix string
+}
+
 // VaultKeyManager manages keys stored in HashiCorp Vault.
 type VaultKeyManager struct {
 	vaultClient  *api.Client
+	auth         *VaultAuthenticator // nil when using a static token
 	transitPath  string
-	addressToKey map[common.Address]string // Map ETH address to Vault key name
+	keyPrefix    string
+	addressToKey map[common.Address]string   // Map ETH address to Vault key name
+	publicKeys   map[string]*ecdsa.PublicKey // public key of each key name, fetched once
+	deletions    uint64                      // counts DeleteKey calls, so a refresh can tell it raced one
 	mu           sync.RWMutex
+
+	refreshMu   sync.Mutex // serializes inventory refreshes
+	lastRefresh time.Time  // guarded by refreshMu
+	stop        chan struct{}
+	stopped     sync.WaitGroup
+	closeOnce   sync.Once
 }
 
 // NewVaultKeyManager creates a new VaultKeyManager and initializes it with keys from Vault.
-func NewVaultKeyManager(vaultClient *api.Client, transitPath string) (*VaultKeyManager, error) {
+// With opts.Auth set, it logs the client in and keeps its token renewed until Close.
+func NewVaultKeyManager(vaultClient *api.Client, transitPath string, opts VaultOptions) (*VaultKeyManager, error) {
+	if opts.KeyPrefix == "" {
+		opts.KeyPrefix = DefaultVaultKeyPrefix
+	}
+	if !vaultKeyNamePattern.MatchString(opts.KeyPrefix) {
+		return nil, fmt.Errorf("%w: key prefix %q", ErrInvalidKeyName, opts.KeyPrefix)
+	}
 	km := &VaultKeyManager{
 		vaultClient:  vaultClient,
+		auth:         opts.Auth,
 		transitPath:  transitPath,
+		keyPrefix:    opts.KeyPrefix,
 		addressToKey: make(map[common.Address]string),
+		publicKeys:   make(map[string]*ecdsa.PublicKey),
+		stop:         make(chan struct{}),
 	}
 
-	if err := km.enableTransitEngine(); err != nil {
+	ctx := context.Background()
+	if km.auth != nil {
+		if err := km.auth.Start(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := km.enableTransitEngine(ctx); err != nil {
+		km.stopAuth()
 		return nil, fmt.Errorf("failed to enable transit secrets engine: %w", err)
 	}
 
-	if err := km.loadExistingKeys(); err != nil {
+	if err := km.Reload(ctx); err != nil {
+		km.stopAuth()
 		return nil, fmt.Errorf("failed to load existing keys from vault: %w", err)
 	}
 
+	if opts.RefreshInterval > 0 {
+		km.stopped.Add(1)
+		go km.refreshLoop(opts.RefreshInterval)
+	}
 	return km, nil
 }
 
-func (km *VaultKeyManager) enableTransitEngine() error {
-	mounts, err := km.vaultClient.Sys().ListMounts()
+func (km *VaultKeyManager) enableTransitEngine(ctx context.Context) error {
+	mounts, err := km.vaultClient.Sys().ListMountsWithContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -54,7 +125,7 @@ func (km *VaultKeyManager) enableTransitEngine() error {
 	mountPath := km.transitPath + "/"
 	if _, ok := mounts[mountPath]; !ok {
 		log.Printf("Transit secrets engine not found at '%s', enabling it now.", km.transitPath)
-		return km.vaultClient.Sys().Mount(km.transitPath, &api.MountInput{
+		return km.vaultClient.Sys().MountWithContext(ctx, km.transitPath, &api.MountInput{
 			Type: "transit",
 		})
 	}
@@ -62,77 +133,299 @@ func (km *VaultKeyManager) enableTransitEngine() error {
 	return nil
 }
 
-func (km *VaultKeyManager) loadExistingKeys() error {
+// Reload re-reads the key inventory from the transit engine. Public keys are fetched only
+// for key names not seen before; keys that disappeared from Vault are forgotten.
+func (km *VaultKeyManager) Reload(ctx context.Context) error {
+	km.refreshMu.Lock()
+	defer km.refreshMu.Unlock()
+	return km.refreshLocked(ctx)
+}
+
+// refreshLocked does the work of Reload. The caller must hold refreshMu.
+func (km *VaultKeyManager) refreshLocked(ctx context.Context) error {
+	km.mu.RLock()
+	deletions := km.deletions
+	km.mu.RUnlock()
+
 	path := fmt.Sprintf("%s/keys", km.transitPath)
-	secret, err := km.vaultClient.Logical().List(path)
+	secret, err := km.vaultClient.Logical().ListWithContext(ctx, path)
 	if err != nil {
 		return err
 	}
 
-	if secret == nil || secret.Data["keys"] == nil {
-		log.Println("No existing keys found in Vault transit engine.")
-		return nil
+	var names []string
+	if secret != nil && secret.Data["keys"] != nil {
+		keys, ok := secret.Data["keys"].([]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected format for keys from vault")
+		}
+		for _, k := range keys {
+			if keyName, ok := k.(string); ok {
+				names = append(names, keyName)
+			}
+		}
 	}
 
-	keys, ok := secret.Data["keys"].([]interface{})
-	if !ok {
-		return fmt.Errorf("unexpected format for keys from vault")
+	km.mu.RLock()
+	known := make(map[string]*ecdsa.PublicKey, len(km.publicKeys))
+	for keyName, publicKey := range km.publicKeys {
+		known[keyName] = publicKey
 	}
+	km.mu.RUnlock()
 
-	km.mu.Lock()
-	defer km.mu.Unlock()
-
-	for _, k := range keys {
-		keyName, ok := k.(string)
-		if !ok {
+	// Look up new keys without holding mu, so signing is not blocked on Vault round trips.
+	publicKeys := make(map[string]*ecdsa.PublicKey, len(names))
+	for _, keyName := range names {
+		if publicKey, ok := known[keyName]; ok {
+			publicKeys[keyName] = publicKey
 			continue
 		}
-
-		address, err := km.getAddressForKey(keyName)
+		publicKey, err := km.getPublicKey(ctx, keyName)
 		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
 			log.Printf("Warning: could not get address for key '%s': %v", keyName, err)
 			continue
 		}
-		km.addressToKey[address] = keyName
-		log.Printf("Loaded key '%s' for address %s", keyName, address.Hex())
+		publicKeys[keyName] = publicKey
+		log.Printf("Loaded key '%s' for address %s", keyName, crypto.PubkeyToAddress(*publicKey).Hex())
 	}
 
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	if km.deletions != deletions {
+		// The list may predate a key deleted since; installing it would bring the key back.
+		log.Println("Discarding vault key inventory read while a key was deleted")
+		return nil
+	}
+	// Keep keys this replica created or imported while the list was in flight.
+	for keyName, publicKey := range km.publicKeys {
+		if _, listed := known[keyName]; !listed {
+			if _, ok := publicKeys[keyName]; !ok {
+				publicKeys[keyName] = publicKey
+			}
+		}
+	}
+	for keyName, publicKey := range known {
+		if _, ok := publicKeys[keyName]; !ok {
+			log.Printf("Key '%s' for address %s is no longer in vault", keyName, crypto.PubkeyToAddress(*publicKey).Hex())
+		}
+	}
+	addressToKey := make(map[common.Address]string, len(publicKeys))
+	for keyName, publicKey := range publicKeys {
+		addressToKey[crypto.PubkeyToAddress(*publicKey)] = keyName
+	}
+	km.publicKeys = publicKeys
+	km.addressToKey = addressToKey
+	km.lastRefresh = time.Now()
+	if len(names) == 0 && len(known) == 0 {
+		log.Println("No existing keys found in Vault transit engine.")
+	}
 	return nil
 }
 
+// refreshLoop refreshes the key inventory every interval until Close.
+func (km *VaultKeyManager) refreshLoop(interval time.Duration) {
+	defer km.stopped.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-km.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if err := km.Reload(ctx); err != nil {
+				log.Printf("Warning: failed to refresh vault key inventory: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// addKey records a key created or imported by this signer.
+func (km *VaultKeyManager) addKey(keyName string, publicKey *ecdsa.PublicKey) {
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	km.addressToKey[crypto.PubkeyToAddress(*publicKey)] = keyName
+	km.publicKeys[keyName] = publicKey
+}
+
 // CreateKey creates a new key in Vault and returns its Ethereum address.
-func (km *VaultKeyManager) CreateKey() (common.Address, error) {
-	id, _ := rand.CryptoRandInt63n(2 ^ 63)
-	keyName := fmt.Sprintf("eth-key-%d", id)
+func (km *VaultKeyManager) CreateKey(ctx context.Context, opts CreateKeyOptions) (common.Address, error) {
+	if opts.Password != "" {
+		return common.Address{}, fmt.Errorf("per-account passwords: %w", ErrNotSupported)
+	}
+	keyName, err := km.newKeyName(opts.Name)
+	if err != nil {
+		return common.Address{}, err
+	}
 
 	path := fmt.Sprintf("%s/keys/%s", km.transitPath, keyName)
-	_, err := km.vaultClient.Logical().Write(path, map[string]interface{}{
+	secret, err := km.vaultClient.Logical().WriteWithContext(ctx, path, map[string]interface{}{
 		"type": "secp256k1",
 	})
 	if err != nil {
 		return common.Address{}, fmt.Errorf("failed to create key in vault: %w", err)
 	}
+	// Writing to an existing name leaves that key in place and only warns. Vault decides
+	// this under the key's lock, so of concurrent creates of one name exactly one wins.
+	if secret != nil && slices.ContainsFunc(secret.Warnings, isKeyExistsWarning) {
+		return common.Address{}, fmt.Errorf("%w: vault key name '%s' is taken", ErrAccountExists, keyName)
+	}
 
-	address, err := km.getAddressForKey(keyName)
+	publicKey, err := km.getPublicKey(ctx, keyName)
 	if err != nil {
-		deletePath := fmt.Sprintf("%s/keys/%s/config", km.transitPath, keyName)
-		_, delErr := km.vaultClient.Logical().Write(deletePath, map[string]interface{}{"deletion_allowed": true})
-		if delErr == nil {
-			km.vaultClient.Logical().Delete(path)
-		}
+		km.destroyOrphan(keyName)
 		return common.Address{}, fmt.Errorf("failed to get address for new key: %w", err)
 	}
 
+	address := crypto.PubkeyToAddress(*publicKey)
+	km.addKey(keyName, publicKey)
+	log.Printf("Successfully created key '%s' for address %s", keyName, address.Hex())
+	return address, nil
+}
+
+// ImportKey imports an existing private key using transit's bring-your-own-key import.
+// The key is sealed with Vault's wrapping key, so it is never sent to Vault in plaintext.
+func (km *VaultKeyManager) ImportKey(ctx context.Context, privateKey *ecdsa.PrivateKey, opts CreateKeyOptions) (common.Address, error) {
+	if opts.Password != "" {
+		return common.Address{}, fmt.Errorf("per-account passwords: %w", ErrNotSupported)
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	if _, err := km.getKeyName(ctx, address); err == nil {
+		return common.Address{}, fmt.Errorf("%w: %s", ErrAccountExists, address.Hex())
+	}
+
+	secret, err := km.vaultClient.Logical().ReadWithContext(ctx, fmt.Sprintf("%s/wrapping_key", km.transitPath))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to read vault wrapping key: %w", err)
+	}
+	if secret == nil {
+		return common.Address{}, fmt.Errorf("vault returned no wrapping key")
+	}
+	wrappingPEM, ok := secret.Data["public_key"].(string)
+	if !ok {
+		return common.Address{}, fmt.Errorf("unexpected format for vault wrapping key")
+	}
+	wrappingKey, err := keywrap.ParsePublicKeyPEM(wrappingPEM)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	der, err := marshalSecp256k1PKCS8(privateKey)
+	if err != nil {
+		return common.Address{}, err
+	}
+	defer clear(der)
+	sealed, err := keywrap.Seal(wrappingKey, der)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to wrap key for vault: %w", err)
+	}
+
+	keyName, err := km.newKeyName(opts.Name)
+	if err != nil {
+		return common.Address{}, err
+	}
+	path := fmt.Sprintf("%s/keys/%s/import", km.transitPath, keyName)
+	_, err = km.vaultClient.Logical().WriteWithContext(ctx, path, map[string]interface{}{
+		"ciphertext":    base64.StdEncoding.EncodeToString(sealed),
+		"type":          "secp256k1",
+		"hash_function": "SHA256",
+	})
+	if err != nil {
+		// Transit refuses to import over an existing key, which is then someone else's.
+		var respErr *api.ResponseError
+		if errors.As(err, &respErr) && km.keyExists(ctx, keyName) {
+			return common.Address{}, fmt.Errorf("%w: vault key name '%s' is taken", ErrAccountExists, keyName)
+		}
+		return common.Address{}, fmt.Errorf("failed to import key into vault: %w", err)
+	}
+
+	// An imported key that cannot be verified is not recorded, so remove it from Vault
+	// rather than leaving an orphaned transit key behind.
+	publicKey, err := km.getPublicKey(ctx, keyName)
+	if err != nil {
+		km.destroyOrphan(keyName)
+		return common.Address{}, fmt.Errorf("failed to get address for imported key: %w", err)
+	}
+	if imported := crypto.PubkeyToAddress(*publicKey); imported != address {
+		km.destroyOrphan(keyName)
+		return common.Address{}, fmt.Errorf("imported key '%s' resolves to %s, expected %s", keyName, imported.Hex(), address.Hex())
+	}
+
+	km.addKey(keyName, publicKey)
+	log.Printf("Imported key '%s' for address %s", keyName, address.Hex())
+	return address, nil
+}
+
+// ArchiveKey marks the transit key for address as deletable so an operator can remove it
+// from Vault. The key itself, and the signer's mapping to it, are left in place.
+func (km *VaultKeyManager) ArchiveKey(ctx context.Context, address common.Address) error {
+	keyName, err := km.getKeyName(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	configPath := fmt.Sprintf("%s/keys/%s/config", km.transitPath, keyName)
+	if _, err := km.vaultClient.Logical().WriteWithContext(ctx, configPath, map[string]interface{}{"deletion_allowed": true}); err != nil {
+		return fmt.Errorf("failed to allow deletion of key '%s': %w", keyName, err)
+	}
+
+	log.Printf("Archived key '%s' for address %s; deletion is now allowed in vault", keyName, address.Hex())
+	return nil
+}
+
+// DeleteKey allows deletion of the transit key for address and then deletes it from Vault.
+func (km *VaultKeyManager) DeleteKey(ctx context.Context, address common.Address) error {
+	keyName, err := km.getKeyName(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	if err := km.destroyTransitKey(ctx, keyName); err != nil {
+		return err
+	}
+
 	km.mu.Lock()
 	defer km.mu.Unlock()
-	km.addressToKey[address] = keyName
+	delete(km.addressToKey, address)
+	delete(km.publicKeys, keyName)
+	km.deletions++
 
-	log.Printf("Successfully created key '%s' for address %s", keyName, address.Hex())
-	return address, nil
+	log.Printf("Deleted key '%s' for address %s", keyName, address.Hex())
+	return nil
+}
+
+// destroyTransitKey allows deletion of the transit key keyName and deletes it from Vault.
+func (km *VaultKeyManager) destroyTransitKey(ctx context.Context, keyName string) error {
+	configPath := fmt.Sprintf("%s/keys/%s/config", km.transitPath, keyName)
+	if _, err := km.vaultClient.Logical().WriteWithContext(ctx, configPath, map[string]interface{}{"deletion_allowed": true}); err != nil {
+		return fmt.Errorf("failed to allow deletion of key '%s': %w", keyName, err)
+	}
+	path := fmt.Sprintf("%s/keys/%s", km.transitPath, keyName)
+	if _, err := km.vaultClient.Logical().DeleteWithContext(ctx, path); err != nil {
+		return fmt.Errorf("failed to delete key '%s' from vault: %w", keyName, err)
+	}
+	return nil
+}
+
+// destroyOrphan deletes a transit key the signer created but could not take into use.
+// It cleans up on a fresh context: the caller's may already be cancelled, which is often
+// why the operation failed in the first place.
+func (km *VaultKeyManager) destroyOrphan(keyName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), vaultCleanupTimeout)
+	defer cancel()
+	if err := km.destroyTransitKey(ctx, keyName); err != nil {
+		log.Printf("Warning: key '%s' is orphaned in vault, delete it manually: %v", keyName, err)
+		return
+	}
+	log.Printf("Deleted unusable key '%s' from vault", keyName)
 }
 
 // GetAccounts returns all managed account addresses.
-func (km *VaultKeyManager) GetAccounts() []common.Address {
+func (km *VaultKeyManager) GetAccounts(ctx context.Context) []common.Address {
 	km.mu.RLock()
 	defer km.mu.RUnlock()
 
@@ -143,19 +436,20 @@ func (km *VaultKeyManager) GetAccounts() []common.Address {
 	return addresses
 }
 
-func (km *VaultKeyManager) getAddressForKey(keyName string) (common.Address, error) {
+// getPublicKey reads the public key of the latest version of a transit key.
+func (km *VaultKeyManager) getPublicKey(ctx context.Context, keyName string) (*ecdsa.PublicKey, error) {
 	path := fmt.Sprintf("%s/keys/%s", km.transitPath, keyName)
-	secret, err := km.vaultClient.Logical().Read(path)
+	secret, err := km.vaultClient.Logical().ReadWithContext(ctx, path)
 	if err != nil {
-		return common.Address{}, err
+		return nil, err
 	}
 	if secret == nil || secret.Data["keys"] == nil {
-		return common.Address{}, fmt.Errorf("key '%s' not found in vault", keyName)
+		return nil, fmt.Errorf("key '%s' not found in vault", keyName)
 	}
 
 	keysData, ok := secret.Data["keys"].(map[string]interface{})
 	if !ok {
-		return common.Address{}, fmt.Errorf("unexpected format for key data")
+		return nil, fmt.Errorf("unexpected format for key data")
 	}
 
 	latestVersion := "0"
@@ -167,38 +461,37 @@ func (km *VaultKeyManager) getAddressForKey(keyName string) (common.Address, err
 
 	keyData, ok := keysData[latestVersion].(map[string]interface{})
 	if !ok {
-		return common.Address{}, fmt.Errorf("unexpected format for key version data")
+		return nil, fmt.Errorf("unexpected format for key version data")
 	}
 
 	pubKeyBase64, ok := keyData["public_key"].(string)
 	if !ok {
-		return common.Address{}, fmt.Errorf("public key not found in key data")
+		return nil, fmt.Errorf("public key not found in key data")
 	}
 
 	block, _ := pem.Decode([]byte(pubKeyBase64))
 	if block == nil {
-		return common.Address{}, fmt.Errorf("failed to parse PEM block containing the public key")
+		return nil, fmt.Errorf("failed to parse PEM block containing the public key")
 	}
 
 	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
-		return common.Address{}, fmt.Errorf("failed to parse DER encoded public key: %w", err)
+		return nil, fmt.Errorf("failed to parse DER encoded public key: %w", err)
 	}
 
 	ecdsaPubKey, ok := pub.(*ecdsa.PublicKey)
 	if !ok {
-		return common.Address{}, fmt.Errorf("key is not an ECDSA public key")
+		return nil, fmt.Errorf("key is not an ECDSA public key")
 	}
 
-	address := crypto.PubkeyToAddress(*ecdsaPubKey)
-	return address, nil
+	return ecdsaPubKey, nil
 }
 
-func (km *VaultKeyManager) signWithVault(keyName string, dataToSign []byte) ([]byte, error) {
+func (km *VaultKeyManager) signWithVault(ctx context.Context, keyName string, dataToSign []byte) ([]byte, error) {
 	path := fmt.Sprintf("%s/sign/%s/sha2-256", km.transitPath, keyName)
 	b64Data := base64.StdEncoding.EncodeToString(dataToSign)
 
-	resp, err := km.vaultClient.Logical().Write(path, map[string]interface{}{
+	resp, err := km.vaultClient.Logical().WriteWithContext(ctx, path, map[string]interface{}{
 		"input":     b64Data,
 		"algorithm": "secp256k1",
 	})
@@ -235,16 +528,16 @@ func (km *VaultKeyManager) signWithVault(keyName string, dataToSign []byte) ([]b
 }
 
 // SignTx signs a transaction using a key stored in Vault.
-func (km *VaultKeyManager) SignTx(address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
-	keyName, err := km.getKeyName(address)
+func (km *VaultKeyManager) SignTx(ctx context.Context, address common.Address, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	keyName, err := km.getKeyName(ctx, address)
 	if err != nil {
 		return nil, err
 	}
 
-	signer := types.NewEIP155Signer(chainID)
+	signer := types.NewPragueSigner(chainID)
 	txHash := signer.Hash(tx)
 
-	signature, err := km.signWithVault(keyName, txHash.Bytes())
+	signature, err := km.signWithVault(ctx, keyName, txHash.Bytes())
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign transaction with vault: %w", err)
 	}
@@ -267,8 +560,8 @@ func (km *VaultKeyManager) SignTx(address common.Address, tx *types.Transaction,
 }
 
 // SignMessage signs a message using a key stored in Vault.
-func (km *VaultKeyManager) SignMessage(address common.Address, message []byte) ([]byte, error) {
-	keyName, err := km.getKeyName(address)
+func (km *VaultKeyManager) SignMessage(ctx context.Context, address common.Address, message []byte) ([]byte, error) {
+	keyName, err := km.getKeyName(ctx, address)
 	if err != nil {
 		return nil, err
 	}
@@ -278,7 +571,7 @@ func (km *VaultKeyManager) SignMessage(address common.Address, message []byte) (
 	prefixedMessage := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)
 	messageHash := crypto.Keccak256Hash([]byte(prefixedMessage))
 
-	signature, err := km.signWithVault(keyName, messageHash.Bytes())
+	signature, err := km.signWithVault(ctx, keyName, messageHash.Bytes())
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign message with vault: %w", err)
 	}
@@ -292,15 +585,221 @@ func (km *VaultKeyManager) SignMessage(address common.Address, message []byte) (
 	return signature, nil
 }
 
-func (km *VaultKeyManager) getKeyName(address common.Address) (string, error) {
+// SignHash signs a precomputed hash using a key stored in Vault.
+func (km *VaultKeyManager) SignHash(ctx context.Context, address common.Address, hash []byte) ([]byte, error) {
+	keyName, err := km.getKeyName(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := km.signWithVault(ctx, keyName, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign hash with vault: %w", err)
+	}
+
+	v, err := km.recoverV(signature, hash, address)
+	if err != nil {
+		return nil, err
+	}
+	return append(signature, v), nil
+}
+
+// PublicKey returns the cached public key of the transit key for address.
+func (km *VaultKeyManager) PublicKey(ctx context.Context, address common.Address) (*ecdsa.PublicKey, error) {
+	keyName, err := km.getKeyName(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
 	km.mu.RLock()
 	defer km.mu.RUnlock()
+	publicKey, ok := km.publicKeys[keyName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, address.Hex())
+	}
+	return publicKey, nil
+}
 
-	keyName, ok := km.addressToKey[address]
+// HealthCheck reports whether Vault is reachable and unsealed, and whether the token is still valid.
+func (km *VaultKeyManager) HealthCheck(ctx context.Context) []ComponentHealth {
+	seal := ComponentHealth{Name: "vault_seal", Healthy: true}
+	sealStatus, err := km.vaultClient.Sys().SealStatusWithContext(ctx)
+	switch {
+	case err != nil:
+		seal.Healthy = false
+		seal.Message = fmt.Sprintf("failed to query seal status: %v", err)
+	case sealStatus.Sealed:
+		seal.Healthy = false
+		seal.Message = "vault is sealed"
+	default:
+		seal.Details = map[string]interface{}{"version": sealStatus.Version}
+	}
+
+	token := ComponentHealth{Name: "vault_token", Healthy: true}
+	secret, err := km.vaultClient.Auth().Token().LookupSelfWithContext(ctx)
+	if err != nil {
+		token.Healthy = false
+		token.Message = fmt.Sprintf("token lookup failed: %v", err)
+	} else {
+		ttl, _ := secret.TokenTTL()
+		renewable, _ := secret.TokenIsRenewable()
+		token.Details = map[string]interface{}{
+			"ttl":       ttl.String(),
+			"renewable": renewable,
+		}
+	}
+
+	km.mu.RLock()
+	keys := len(km.addressToKey)
+	km.mu.RUnlock()
+	km.refreshMu.Lock()
+	lastRefresh := km.lastRefresh
+	km.refreshMu.Unlock()
+	transit := ComponentHealth{
+		Name:    "vault_transit",
+		Healthy: true,
+		Details: map[string]interface{}{"path": km.transitPath, "keys": keys, "lastRefresh": lastRefresh.UTC().Format(time.RFC3339)},
+	}
+
+	if km.auth == nil {
+		return []ComponentHealth{seal, token, transit}
+	}
+	return []ComponentHealth{seal, km.auth.HealthCheck(), token, transit}
+}
+
+// KeyReference returns the transit key path holding the key for address.
+func (km *VaultKeyManager) KeyReference(address common.Address) (string, string, error) {
+	keyName, ok := km.cachedKeyName(address)
 	if !ok {
-		return "", fmt.Errorf("account not found or not managed by this signer: %s", address.Hex())
+		return "", "", fmt.Errorf("%w: %s is not managed by this signer", ErrAccountNotFound, address.Hex())
+	}
+	return "vault", fmt.Sprintf("%s/keys/%s", km.transitPath, keyName), nil
+}
+
+// Close stops the inventory refresh and token renewal, and forgets the Vault token and
+// the cached key inventory.
+func (km *VaultKeyManager) Close() error {
+	km.closeOnce.Do(func() { close(km.stop) })
+	km.stopped.Wait()
+	km.stopAuth()
+
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	km.addressToKey = make(map[common.Address]string)
+	km.publicKeys = make(map[string]*ecdsa.PublicKey)
+	km.vaultClient.ClearToken()
+	log.Println("Vault key manager closed")
+	return nil
+}
+
+// stopAuth stops background token renewal, if any.
+func (km *VaultKeyManager) stopAuth() {
+	if km.auth != nil {
+		km.auth.Stop()
+	}
+}
+
+// HasAccount implements AccountFinder. An unknown address refreshes the key inventory
+// as in getKeyName.
+func (km *VaultKeyManager) HasAccount(ctx context.Context, address common.Address) bool {
+	_, err := km.getKeyName(ctx, address)
+	return err == nil
+}
+
+// getKeyName returns the transit key name for address. An unknown address refreshes the
+// key inventory first, unless that happened moments ago, in case another replica created it.
+func (km *VaultKeyManager) getKeyName(ctx context.Context, address common.Address) (string, error) {
+	if keyName, ok := km.cachedKeyName(address); ok {
+		return keyName, nil
+	}
+
+	km.refreshMu.Lock()
+	if time.Since(km.lastRefresh) >= vaultMissRefreshInterval {
+		if err := km.refreshLocked(ctx); err != nil {
+			log.Printf("Warning: failed to refresh vault key inventory: %v", err)
+		}
+	}
+	km.refreshMu.Unlock()
+
+	if keyName, ok := km.cachedKeyName(address); ok {
+		return keyName, nil
+	}
+	return "", fmt.Errorf("%w: %s is not managed by this signer", ErrAccountNotFound, address.Hex())
+}
+
+func (km *VaultKeyManager) cachedKeyName(address common.Address) (string, bool) {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+	keyName, ok := km.addressToKey[address]
+	return keyName, ok
+}
+
+// newKeyName validates a caller-supplied key name, or generates one from the key prefix
+// and a random UUID. Whether the name is still free is decided by the write that creates
+// the key, so that two signers sharing a transit mount cannot both claim it.
+func (km *VaultKeyManager) newKeyName(name string) (string, error) {
+	if name == "" {
+		return km.keyPrefix + uuid.NewString(), nil
+	}
+	if !vaultKeyNamePattern.MatchString(name) {
+		return "", fmt.Errorf("%w: %q must be 1-128 letters, digits, '.', '_' or '-' and start with a letter or digit", ErrInvalidKeyName, name)
+	}
+	return name, nil
+}
+
+// isKeyExistsWarning reports whether warning is the one transit returns when a create
+// request names an existing key.
+func isKeyExistsWarning(warning string) bool {
+	return strings.Contains(warning, "already existed")
+}
+
+// keyExists reports whether a transit key named keyName exists.
+func (km *VaultKeyManager) keyExists(ctx context.Context, keyName string) bool {
+	secret, err := km.vaultClient.Logical().ReadWithContext(ctx, fmt.Sprintf("%s/keys/%s", km.transitPath, keyName))
+	return err == nil && secret != nil
+}
+
+// oidSecp256k1 and oidPublicKeyECDSA identify secp256k1 EC keys in PKCS #8.
+var (
+	oidSecp256k1      = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+)
+
+// marshalSecp256k1PKCS8 encodes a secp256k1 private key as PKCS #8 DER, the format
+// transit expects for imported asymmetric keys. crypto/x509 does not know the curve.
+func marshalSecp256k1PKCS8(privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	ecKey, err := asn1.Marshal(struct {
+		Version    int
+		PrivateKey []byte
+		PublicKey  asn1.BitString `asn1:"optional,explicit,tag:1"`
+	}{
+		Version:    1,
+		PrivateKey: crypto.FromECDSA(privateKey),
+		PublicKey:  asn1.BitString{Bytes: crypto.FromECDSAPub(&privateKey.PublicKey), BitLength: 65 * 8},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode EC private key: %w", err)
+	}
+	defer clear(ecKey)
+
+	curve, err := asn1.Marshal(oidSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+	der, err := asn1.Marshal(struct {
+		Version    int
+		Algo       pkix.AlgorithmIdentifier
+		PrivateKey []byte
+	}{
+		Version:    0,
+		Algo:       pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: curve}},
+		PrivateKey: ecKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode PKCS #8 key: %w", err)
 	}
-	return keyName, nil
+	return der, nil
 }
 
 // recoverV attempts to find the correct recovery ID (v) for a signature.
@@ -0,0 +1,178 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func newTestVaultKeyManager(t *testing.T, f *fakeVault) *VaultKeyManager {
+	t.Helper()
+	km, err := NewVaultKeyManager(f.client("root"), "transit", VaultOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { km.Close() })
+	return km
+}
+
+func TestVaultImportKeyDeletesKeyOfOtherAddress(t *testing.T) {
+	f := newFakeVault(t)
+	km := newTestVaultKeyManager(t, f)
+
+	// Vault reports a public key that is not the imported one.
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.importKey = &other.PublicKey
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := km.ImportKey(context.Background(), privateKey, CreateKeyOptions{}); err == nil {
+		t.Fatal("ImportKey succeeded for a key resolving to another address")
+	}
+	if f.importData == nil {
+		t.Fatal("key was not imported into the fake vault")
+	}
+	if keys := f.transitKeys(); len(keys) != 0 {
+		t.Fatalf("orphaned transit keys left in vault: %v", keys)
+	}
+	if len(f.deleted) != 1 {
+		t.Fatalf("deleted %v, want the imported key", f.deleted)
+	}
+	if accounts := km.GetAccounts(context.Background()); len(accounts) != 0 {
+		t.Fatalf("GetAccounts = %v, want none", accounts)
+	}
+}
+
+func TestVaultImportKeyDeletesUnreadableKey(t *testing.T) {
+	f := newFakeVault(t)
+	km := newTestVaultKeyManager(t, f)
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// f.importKey is nil, so the imported key has no readable public key.
+	if _, err := km.ImportKey(context.Background(), privateKey, CreateKeyOptions{}); err == nil {
+		t.Fatal("ImportKey succeeded without a readable public key")
+	}
+	if keys := f.transitKeys(); len(keys) != 0 {
+		t.Fatalf("orphaned transit keys left in vault: %v", keys)
+	}
+}
+
+func TestVaultRefreshDoesNotRestoreDeletedKey(t *testing.T) {
+	f := newFakeVault(t)
+	deleted, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.update(func(f *fakeVault) { f.keys["deleted"] = &deleted.PublicKey })
+	km := newTestVaultKeyManager(t, f)
+	address := crypto.PubkeyToAddress(deleted.PublicKey)
+
+	// Hold a refresh while it fetches the public key of a new key, after it listed the
+	// key that is deleted meanwhile.
+	added, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetching := make(chan struct{})
+	release := make(chan struct{})
+	f.update(func(f *fakeVault) {
+		f.keys["added"] = &added.PublicKey
+		f.intercept = func(r *http.Request) {
+			if r.URL.Path == "/v1/transit/keys/added" {
+				close(fetching)
+				<-release
+			}
+		}
+	})
+	reloaded := make(chan error, 1)
+	go func() { reloaded <- km.Reload(context.Background()) }()
+	<-fetching
+
+	if err := km.DeleteKey(t.Context(), address); err != nil {
+		t.Fatal(err)
+	}
+	f.update(func(f *fakeVault) { f.intercept = nil })
+	close(release)
+	if err := <-reloaded; err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(km.GetAccounts(t.Context()), address) {
+		t.Fatal("refresh restored a key deleted while it was in flight")
+	}
+
+	// The next refresh picks up the new key.
+	if err := km.Reload(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	accounts := km.GetAccounts(t.Context())
+	if slices.Contains(accounts, address) || !slices.Contains(accounts, crypto.PubkeyToAddress(added.PublicKey)) {
+		t.Fatalf("accounts after refresh = %v, want only the added key", accounts)
+	}
+}
+
+func TestVaultCreateKeyNameConflict(t *testing.T) {
+	f := newFakeVault(t)
+	km := newTestVaultKeyManager(t, f)
+	ctx := context.Background()
+
+	if _, err := km.CreateKey(ctx, CreateKeyOptions{Name: "taken"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := km.CreateKey(ctx, CreateKeyOptions{Name: "taken"}); !errors.Is(err, ErrAccountExists) {
+		t.Fatalf("CreateKey with a taken name: got %v, want ErrAccountExists", err)
+	}
+
+	// Another signer creates the key after any check this one could make, right before
+	// its own create reaches Vault.
+	f.update(func(f *fakeVault) {
+		f.intercept = func(r *http.Request) {
+			if r.Method == http.MethodPut && r.URL.Path == "/v1/transit/keys/racy" {
+				other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				if err != nil {
+					t.Error(err)
+				}
+				f.update(func(f *fakeVault) { f.keys["racy"] = &other.PublicKey })
+			}
+		}
+	})
+	if _, err := km.CreateKey(ctx, CreateKeyOptions{Name: "racy"}); !errors.Is(err, ErrAccountExists) {
+		t.Fatalf("CreateKey racing another signer: got %v, want ErrAccountExists", err)
+	}
+	f.update(func(f *fakeVault) { f.intercept = nil })
+
+	// An import does not replace an existing key either.
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := km.ImportKey(ctx, privateKey, CreateKeyOptions{Name: "taken"}); !errors.Is(err, ErrAccountExists) {
+		t.Fatalf("ImportKey with a taken name: got %v, want ErrAccountExists", err)
+	}
+
+	if len(f.deleted) != 0 {
+		t.Fatalf("deleted %v, want the existing keys left alone", f.deleted)
+	}
+	keys := f.transitKeys()
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"racy", "taken"}) {
+		t.Fatalf("transit keys = %v, want racy and taken", keys)
+	}
+	if accounts := km.GetAccounts(ctx); len(accounts) != 1 {
+		t.Fatalf("GetAccounts = %v, want only the key this signer created", accounts)
+	}
+}
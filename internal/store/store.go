@@ -0,0 +1,249 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	bolt "go.etcd.io/bbolt"
+)
+
+// openTimeout bounds how long Open waits for the file lock held by another process,
+// typically a running signer when the CLI is used against the same store.
+const openTimeout = 2 * time.Second
+
+var accountsBucket = []byte("accounts")
+
+var (
+	// ErrLocked is returned by Open and OpenReadOnly when another process, typically a
+	// running signer, holds the store.
+	ErrLocked = errors.New("store is locked by another process (is the signer running?)")
+	// ErrReadOnly is returned when writing to a store opened with OpenReadOnly.
+	ErrReadOnly = errors.New("store is open read-only")
+)
+
+// Account lifecycle states.
+const (
+	// StateActive accounts can sign.
+	StateActive = "active"
+	// StateDisabled accounts keep their key material but refuse to sign until re-enabled.
+	StateDisabled = "disabled"
+	// StateArchived accounts have had their key material retired; this state is final.
+	StateArchived = "archived"
+)
+
+// Metadata is the descriptive information operators attach to an account.
+type Metadata struct {
+	Label   string   `json:"label,omitempty"`
+	Owner   string   `json:"owner,omitempty"` // owning team
+	Purpose string   `json:"purpose,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// HasTags reports whether m carries every tag in tags.
+func (m Metadata) HasTags(tags []string) bool {
+	for _, want := range tags {
+		if !slices.Contains(m.Tags, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// Account is the persisted record of a managed account.
+type Account struct {
+	Address common.Address `json:"address"`
+	State   string         `json:"state"`
+	Metadata
+	// Backend is the key manager type holding the key, e.g. "local" or "vault".
+	Backend string `json:"backend,omitempty"`
+	// KeyRef locates the key inside its backend, e.g. a keystore file or a transit key name.
+	KeyRef    string    `json:"keyRef,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Store persists account records in an embedded bbolt database.
+//
+// A nil *Store holds no records: every account reads as active without metadata, and
+// writes fail with ErrReadOnly. It stands in for a store that is not available.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens (creating if needed) the store at path for reading and writing. The store
+// is locked for the lifetime of the returned Store.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, openError(path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(accountsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize store: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// OpenReadOnly opens the existing store at path for reading. Several processes may read
+// a store at once, but not while another process has it open with Open. A missing store
+// is reported as an error satisfying errors.Is(err, fs.ErrNotExist).
+func OpenReadOnly(path string) (*Store, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout, ReadOnly: true})
+	if err != nil {
+		return nil, openError(path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+func openError(path string, err error) error {
+	if errors.Is(err, bolt.ErrTimeout) {
+		return fmt.Errorf("store %s: %w", path, ErrLocked)
+	}
+	return fmt.Errorf("failed to open store: %w", err)
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// view runs fn in a read transaction on the accounts bucket, which is nil if the store
+// has no records yet.
+func (s *Store) view(fn func(b *bolt.Bucket) error) error {
+	if s == nil {
+		return fn(nil)
+	}
+	return s.db.View(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket(accountsBucket))
+	})
+}
+
+// update runs fn in a write transaction on the accounts bucket.
+func (s *Store) update(fn func(b *bolt.Bucket) error) error {
+	if s == nil {
+		return ErrReadOnly
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket(accountsBucket))
+	})
+	if errors.Is(err, bolt.ErrDatabaseReadOnly) {
+		return ErrReadOnly
+	}
+	return err
+}
+
+// GetAccount returns the record for address. The boolean is false if no record exists.
+func (s *Store) GetAccount(address common.Address) (Account, bool, error) {
+	var acc Account
+	var found bool
+	err := s.view(func(b *bolt.Bucket) error {
+		if b == nil {
+			return nil
+		}
+		data := b.Get(address.Bytes())
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, &acc)
+	})
+	if err != nil {
+		return Account{}, false, fmt.Errorf("failed to read account %s: %w", address.Hex(), err)
+	}
+	return acc, found, nil
+}
+
+// PutAccount creates or replaces the record for acc.Address.
+func (s *Store) PutAccount(acc Account) error {
+	data, err := json.Marshal(acc)
+	if err != nil {
+		return err
+	}
+	err = s.update(func(b *bolt.Bucket) error {
+		return b.Put(acc.Address.Bytes(), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write account %s: %w", acc.Address.Hex(), err)
+	}
+	return nil
+}
+
+// UpdateAccount atomically applies fn to the record for address. A missing record is
+// passed to fn as an active account. If fn returns an error nothing is written.
+func (s *Store) UpdateAccount(address common.Address, fn func(acc *Account) error) (Account, error) {
+	var acc Account
+	err := s.update(func(b *bolt.Bucket) error {
+		acc = Account{Address: address, State: StateActive}
+		if data := b.Get(address.Bytes()); data != nil {
+			if err := json.Unmarshal(data, &acc); err != nil {
+				return err
+			}
+		}
+		if err := fn(&acc); err != nil {
+			return err
+		}
+		acc.UpdatedAt = time.Now().UTC()
+		data, err := json.Marshal(acc)
+		if err != nil {
+			return err
+		}
+		return b.Put(address.Bytes(), data)
+	})
+	if err != nil {
+		return Account{}, err
+	}
+	return acc, nil
+}
+
+// DeleteAccount removes the record for address, if any.
+func (s *Store) DeleteAccount(address common.Address) error {
+	err := s.update(func(b *bolt.Bucket) error {
+		return b.Delete(address.Bytes())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete account %s: %w", address.Hex(), err)
+	}
+	return nil
+}
+
+// ListAccounts returns every stored account record.
+func (s *Store) ListAccounts() ([]Account, error) {
+	var accounts []Account
+	err := s.view(func(b *bolt.Bucket) error {
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var acc Account
+			if err := json.Unmarshal(v, &acc); err != nil {
+				return err
+			}
+			accounts = append(accounts, acc)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	return accounts, nil
+}
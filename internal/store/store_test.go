@@ -0,0 +1,76 @@
+package store
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestOpenReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signer.db")
+	address := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	if _, err := OpenReadOnly(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("OpenReadOnly of a missing store: got %v, want fs.ErrNotExist", err)
+	}
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutAccount(Account{Address: address, State: StateDisabled}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A running signer holds the store exclusively.
+	if _, err := OpenReadOnly(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("OpenReadOnly of a locked store: got %v, want ErrLocked", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ro, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ro.Close()
+	// Readers share the store.
+	ro2, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("second OpenReadOnly: %v", err)
+	}
+	ro2.Close()
+
+	acc, found, err := ro.GetAccount(address)
+	if err != nil || !found || acc.State != StateDisabled {
+		t.Fatalf("GetAccount = %+v, %v, %v; want the disabled record", acc, found, err)
+	}
+	if err := ro.PutAccount(Account{Address: address, State: StateActive}); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("PutAccount on a read-only store: got %v, want ErrReadOnly", err)
+	}
+	if _, err := ro.UpdateAccount(address, func(*Account) error { return nil }); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("UpdateAccount on a read-only store: got %v, want ErrReadOnly", err)
+	}
+}
+
+func TestNilStore(t *testing.T) {
+	var s *Store
+	address := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	if _, found, err := s.GetAccount(address); found || err != nil {
+		t.Fatalf("GetAccount = %v, %v; want no record", found, err)
+	}
+	if accounts, err := s.ListAccounts(); len(accounts) != 0 || err != nil {
+		t.Fatalf("ListAccounts = %v, %v; want none", accounts, err)
+	}
+	if err := s.DeleteAccount(address); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("DeleteAccount: got %v, want ErrReadOnly", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,16 +2,26 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math/big"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/xueqianLu/ethsigner/pkg/keywrap"
 )
 
 // CreateAccountResponse represents the response for a new account creation.
@@ -19,7 +29,8 @@ type CreateAccountResponse struct {
 	Address string `json:"address"`
 }
 
-// SignTxRequest represents the request to sign a transaction.
+// SignTxRequest represents the request to sign a transaction. It is sent with hex
+// quantities and hex data, the format the signer expects; see MarshalJSON.
 type SignTxRequest struct {
 	From      string   `json:"from"`
 	To        string   `json:"to"`
@@ -30,12 +41,75 @@ type SignTxRequest struct {
 	GasPrice  *big.Int `json:"gasPrice,omitempty"`  // Legacy
 	GasFeeCap *big.Int `json:"gasFeeCap,omitempty"` // EIP-1559
 	GasTipCap *big.Int `json:"gasTipCap,omitempty"` // EIP-1559
-	ChainID   string   `json:"chainId"`
+	ChainID   string   `json:"chainId"`             // decimal, or 0x-prefixed hex
+}
+
+// MarshalJSON encodes the request in the signer's format, with 0x-prefixed hex
+// quantities and data as in the Ethereum JSON-RPC API.
+func (r SignTxRequest) MarshalJSON() ([]byte, error) {
+	var chainID *big.Int
+	if r.ChainID != "" { // left to the signer to reject
+		var ok bool
+		if chainID, ok = new(big.Int).SetString(r.ChainID, 0); !ok || chainID.Sign() < 0 {
+			return nil, fmt.Errorf("invalid chain ID %q", r.ChainID)
+		}
+	}
+	return json.Marshal(struct {
+		From      string         `json:"from"`
+		To        string         `json:"to,omitempty"`
+		Nonce     hexutil.Uint64 `json:"nonce"`
+		Value     *hexutil.Big   `json:"value,omitempty"`
+		Data      hexutil.Bytes  `json:"data,omitempty"`
+		GasLimit  hexutil.Uint64 `json:"gasLimit"`
+		GasPrice  *hexutil.Big   `json:"gasPrice,omitempty"`
+		GasFeeCap *hexutil.Big   `json:"gasFeeCap,omitempty"`
+		GasTipCap *hexutil.Big   `json:"gasTipCap,omitempty"`
+		ChainID   *hexutil.Big   `json:"chainId"`
+	}{
+		From:      r.From,
+		To:        r.To,
+		Nonce:     hexutil.Uint64(r.Nonce),
+		Value:     (*hexutil.Big)(r.Value),
+		Data:      r.Data,
+		GasLimit:  hexutil.Uint64(r.GasLimit),
+		GasPrice:  (*hexutil.Big)(r.GasPrice),
+		GasFeeCap: (*hexutil.Big)(r.GasFeeCap),
+		GasTipCap: (*hexutil.Big)(r.GasTipCap),
+		ChainID:   (*hexutil.Big)(chainID),
+	})
 }
 
 // SignTxResponse represents the response for a signed transaction.
 type SignTxResponse struct {
-	RawTx string `json:"rawTx"`
+	RawTx string `json:"rawTx"` // hex without 0x prefix
+	SignedTx
+}
+
+// SignedTx describes a signed transaction.
+type SignedTx struct {
+	Hash common.Hash    `json:"hash"`
+	From common.Address `json:"from"` // recovered from the signature by the signer
+	R    *hexutil.Big   `json:"r"`
+	S    *hexutil.Big   `json:"s"`
+	V    *hexutil.Big   `json:"v"`
+	// YParity is only set for typed transactions, where it equals V.
+	YParity *hexutil.Uint64    `json:"yParity,omitempty"`
+	Tx      *types.Transaction `json:"tx"`
+}
+
+// SignRawTxRequest represents the request to sign a transaction built by the caller.
+// Tx is an unsigned EIP-2718 typed transaction, either the standard unsigned payload
+// type || rlp([chainId, nonce, ..., accessList]) or as types.Transaction.MarshalBinary
+// encodes it.
+type SignRawTxRequest struct {
+	From string        `json:"from"`
+	Tx   hexutil.Bytes `json:"tx"`
+}
+
+// SignRawTxResponse represents the response for a signed raw transaction.
+type SignRawTxResponse struct {
+	RawTx hexutil.Bytes `json:"rawTx"`
+	SignedTx
 }
 
 // SignMessageRequest represents the request to sign a message.
@@ -49,13 +123,133 @@ type SignMessageResponse struct {
 	Signature string `json:"signature"`
 }
 
+// ImportAccountRequest represents a request to import an existing private key.
+// Exactly one of Keystore (with its Password) or Envelope must be set.
+type ImportAccountRequest struct {
+	Keystore json.RawMessage `json:"keystore,omitempty"`
+	Password string          `json:"password,omitempty"`
+	Envelope string          `json:"envelope,omitempty"`
+	// AccountPassword encrypts the imported key when the signer uses per-account passwords.
+	AccountPassword string `json:"accountPassword,omitempty"`
+	// Backend names the backend of a composite key manager that receives the key.
+	Backend string `json:"backend,omitempty"`
+	// KeyName names the key in the backend, e.g. the Vault transit key; empty lets the backend choose.
+	KeyName string `json:"keyName,omitempty"`
+	AccountMetadata
+}
+
+// AccountMetadata represents the descriptive metadata of an account.
+type AccountMetadata struct {
+	Label   string   `json:"label,omitempty"`
+	Owner   string   `json:"owner,omitempty"`
+	Purpose string   `json:"purpose,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// CreateAccountRequest represents a request to create an account with metadata.
+type CreateAccountRequest struct {
+	// Password encrypts the new key when the signer uses per-account passwords.
+	Password string `json:"password,omitempty"`
+	// Backend names the backend of a composite key manager that receives the key.
+	Backend string `json:"backend,omitempty"`
+	// KeyName names the key in the backend, e.g. the Vault transit key; empty lets the backend choose.
+	KeyName string `json:"keyName,omitempty"`
+	AccountMetadata
+}
+
+// UnlockAccountRequest represents a request to unlock an account with its own password.
+type UnlockAccountRequest struct {
+	Address  string `json:"address"`
+	Password string `json:"password"`
+	TTL      string `json:"ttl,omitempty"` // Go duration such as "15m"; empty uses the signer default
+}
+
+// UnlockAccountResponse reports until when an unlocked account stays unlocked.
+type UnlockAccountResponse struct {
+	Address       string    `json:"address"`
+	UnlockedUntil time.Time `json:"unlockedUntil"`
+}
+
+// LockAccountRequest represents a request to lock an unlocked account.
+type LockAccountRequest struct {
+	Address string `json:"address"`
+}
+
+// UpdateAccountMetadataRequest represents a request to replace the metadata of an account.
+type UpdateAccountMetadataRequest struct {
+	Address string `json:"address"`
+	AccountMetadata
+}
+
+// Account represents an account in the enriched accounts listing.
+type Account struct {
+	Address string `json:"address"`
+	State   string `json:"state"`
+	AccountMetadata
+	Backend   string     `json:"backend,omitempty"`
+	KeyRef    string     `json:"keyRef,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// AccountFilter narrows ListAccounts. Empty fields do not filter.
+type AccountFilter struct {
+	Tags   []string // accounts must carry all tags
+	Owner  string
+	States []string // "active", "disabled", "archived" or "all"; default active
+}
+
+// WrappingKeyResponse represents the public key used to seal import envelopes.
+type WrappingKeyResponse struct {
+	PublicKey string `json:"publicKey"`
+}
+
+// AccountStateRequest represents a request to disable, enable or archive an account.
+type AccountStateRequest struct {
+	Address           string `json:"address"`
+	ConfirmationToken string `json:"confirmationToken,omitempty"`
+}
+
+// AccountStateResponse represents the lifecycle state of an account after a change.
+type AccountStateResponse struct {
+	Address   string    `json:"address"`
+	State     string    `json:"state"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ArchiveConfirmationResponse is returned by an unconfirmed archive request.
+type ArchiveConfirmationResponse struct {
+	Address           string    `json:"address"`
+	ConfirmationToken string    `json:"confirmationToken"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+}
+
+// RotatePasswordRequest represents a request to re-encrypt the local keystore with a new password.
+// ScryptN and ScryptP are optional; when zero each keystore file keeps its parameters.
+type RotatePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+	ScryptN         int    `json:"scryptN,omitempty"`
+	ScryptP         int    `json:"scryptP,omitempty"`
+}
+
+// RotatePasswordResponse reports how many keystore files were re-encrypted.
+type RotatePasswordResponse struct {
+	Rotated int `json:"rotated"`
+}
+
 const (
 	apiKeyHeader    = "X-API-Key"
 	signatureHeader = "X-Signature"
 	timestampHeader = "X-Timestamp"
+	requestIDHeader = "X-Request-ID"
 )
 
-// Client is a client for the ethsigner service.
+// apiPrefix selects the versioned API, which reports errors with stable codes (see APIError).
+const apiPrefix = "/v1"
+
+// Client is a client for the ethsigner service. It uses the versioned (/v1) API; requests
+// the signer rejects fail with an *APIError.
 type Client struct {
 	baseURL    string
 	apiKey     string
@@ -63,21 +257,36 @@ type Client struct {
 	httpClient *http.Client
 }
 
-// NewClient creates a new ethsigner client.
+// unixScheme prefixes base URLs that name a Unix domain socket, e.g. "unix:///run/signer.sock".
+const unixScheme = "unix://"
+
+// NewClient creates a new ethsigner client. baseURL is an http(s) URL or
+// "unix://<socket path>" for a signer listening on a Unix domain socket.
 func NewClient(baseURL, apiKey, apiSecret string) *Client {
+	httpClient := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+	if socketPath, ok := strings.CutPrefix(baseURL, unixScheme); ok {
+		// The host is ignored; every connection goes to the socket.
+		baseURL = "http://unix"
+		httpClient.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socketPath)
+			},
+		}
+	}
 	return &Client{
-		baseURL:   baseURL,
-		apiKey:    apiKey,
-		apiSecret: apiSecret,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		apiSecret:  apiSecret,
+		httpClient: httpClient,
 	}
 }
 
 // Health checks the health of the signer service.
 func (c *Client) Health() (string, error) {
-	resp, err := c.httpClient.Get(c.baseURL + "/health")
+	resp, err := c.httpClient.Get(c.baseURL + apiPrefix + "/health")
 	if err != nil {
 		return "", err
 	}
@@ -89,29 +298,199 @@ func (c *Client) Health() (string, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("service returned non-OK status: %s, body: %s", resp.Status, string(body))
+		return "", newAPIError(resp, body)
 	}
 
 	return string(body), nil
 }
 
-// GetAccounts retrieves the list of accounts managed by the signer.
+// GetAccounts retrieves the addresses of the active accounts managed by the signer.
 func (c *Client) GetAccounts() ([]string, error) {
-	var accounts []string
-	err := c.doRequest(http.MethodGet, "/accounts", nil, &accounts)
+	accounts, err := c.ListAccounts(AccountFilter{})
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]string, 0, len(accounts))
+	for _, acc := range accounts {
+		addresses = append(addresses, acc.Address)
+	}
+	return addresses, nil
+}
+
+// ListAccounts retrieves the accounts matching filter together with their state and metadata.
+func (c *Client) ListAccounts(filter AccountFilter) ([]Account, error) {
+	query := url.Values{}
+	for _, tag := range filter.Tags {
+		query.Add("tag", tag)
+	}
+	for _, state := range filter.States {
+		query.Add("state", state)
+	}
+	if filter.Owner != "" {
+		query.Set("owner", filter.Owner)
+	}
+	path := "/accounts"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var accounts []Account
+	err := c.doRequest(http.MethodGet, path, nil, &accounts)
 	return accounts, err
 }
 
 // CreateAccount requests the creation of a new account in the signer.
 func (c *Client) CreateAccount() (*CreateAccountResponse, error) {
+	return c.CreateAccountWithMetadata(AccountMetadata{})
+}
+
+// CreateAccountWithMetadata requests the creation of a new account labelled with meta.
+func (c *Client) CreateAccountWithMetadata(meta AccountMetadata) (*CreateAccountResponse, error) {
 	var resp CreateAccountResponse
-	err := c.doRequest(http.MethodPost, "/create-account", nil, &resp)
+	err := c.doRequest(http.MethodPost, "/create-account", CreateAccountRequest{AccountMetadata: meta}, &resp)
 	if err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
+// CreateLockedAccount requests the creation of a new account encrypted with its own
+// password. The signer must have per-account passwords enabled; the account has to be
+// unlocked with UnlockAccount before it can sign.
+func (c *Client) CreateLockedAccount(password string, meta AccountMetadata) (*CreateAccountResponse, error) {
+	return c.CreateAccountWithOptions(CreateAccountRequest{Password: password, AccountMetadata: meta})
+}
+
+// CreateAccountWithOptions requests the creation of a new account as described by req,
+// e.g. in a specific backend of a composite key manager.
+func (c *Client) CreateAccountWithOptions(req CreateAccountRequest) (*CreateAccountResponse, error) {
+	var resp CreateAccountResponse
+	if err := c.doRequest(http.MethodPost, "/create-account", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UnlockAccount unlocks an account for ttl, or the signer default when ttl is zero.
+// It requires the admin API key the client was created with.
+func (c *Client) UnlockAccount(address, password string, ttl time.Duration) (*UnlockAccountResponse, error) {
+	req := UnlockAccountRequest{Address: address, Password: password}
+	if ttl > 0 {
+		req.TTL = ttl.String()
+	}
+	var resp UnlockAccountResponse
+	if err := c.doRequest(http.MethodPost, "/unlock-account", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// LockAccount locks an unlocked account immediately.
+// It requires the admin API key the client was created with.
+func (c *Client) LockAccount(address string) error {
+	return c.doRequest(http.MethodPost, "/lock-account", LockAccountRequest{Address: address}, nil)
+}
+
+// UpdateAccountMetadata replaces the metadata of an account.
+// It requires the admin API key the client was created with.
+func (c *Client) UpdateAccountMetadata(address string, meta AccountMetadata) (*Account, error) {
+	var resp Account
+	req := UpdateAccountMetadataRequest{Address: address, AccountMetadata: meta}
+	if err := c.doRequest(http.MethodPost, "/admin/account-metadata", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// WrappingKey fetches the PEM-encoded public key used to seal import envelopes.
+// It requires a client created with the admin credentials.
+func (c *Client) WrappingKey() (string, error) {
+	var resp WrappingKeyResponse
+	if err := c.doRequest(http.MethodGet, "/admin/wrapping-key", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.PublicKey, nil
+}
+
+// ImportAccount imports an existing key into the signer.
+// It requires a client created with the admin credentials.
+func (c *Client) ImportAccount(req ImportAccountRequest) (*CreateAccountResponse, error) {
+	var resp CreateAccountResponse
+	err := c.doRequest(http.MethodPost, "/admin/import-account", req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DisableAccount stops an account from signing. It requires the admin credentials.
+func (c *Client) DisableAccount(address string) (*AccountStateResponse, error) {
+	var resp AccountStateResponse
+	err := c.doRequest(http.MethodPost, "/admin/disable-account", AccountStateRequest{Address: address}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// EnableAccount allows a disabled account to sign again. It requires the admin credentials.
+func (c *Client) EnableAccount(address string) (*AccountStateResponse, error) {
+	var resp AccountStateResponse
+	err := c.doRequest(http.MethodPost, "/admin/enable-account", AccountStateRequest{Address: address}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RequestArchive asks the signer for a confirmation token to archive an account.
+// Nothing is changed until ArchiveAccount is called with the token.
+// It requires the admin credentials.
+func (c *Client) RequestArchive(address string) (*ArchiveConfirmationResponse, error) {
+	var resp ArchiveConfirmationResponse
+	err := c.doRequest(http.MethodPost, "/admin/archive-account", AccountStateRequest{Address: address}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ArchiveAccount archives an account's key material using a token from RequestArchive.
+// It requires the admin credentials.
+func (c *Client) ArchiveAccount(address, confirmationToken string) (*AccountStateResponse, error) {
+	var resp AccountStateResponse
+	req := AccountStateRequest{Address: address, ConfirmationToken: confirmationToken}
+	err := c.doRequest(http.MethodPost, "/admin/archive-account", req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RotatePassword re-encrypts every local keystore file with a new password.
+// It requires the admin API key the client was created with.
+func (c *Client) RotatePassword(req RotatePasswordRequest) (*RotatePasswordResponse, error) {
+	var resp RotatePasswordResponse
+	if err := c.doRequest(http.MethodPost, "/admin/rotate-password", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SealPrivateKey seals a raw 32-byte private key with the signer's wrapping key,
+// producing the Envelope of an ImportAccountRequest.
+func SealPrivateKey(wrappingKeyPEM string, privateKey []byte) (string, error) {
+	pub, err := keywrap.ParsePublicKeyPEM(wrappingKeyPEM)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := keywrap.Seal(pub, privateKey)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
 // SignTransaction sends a transaction to the signer service to be signed.
 func (c *Client) SignTransaction(req SignTxRequest) (*SignTxResponse, error) {
 	var resp SignTxResponse
@@ -122,6 +501,16 @@ func (c *Client) SignTransaction(req SignTxRequest) (*SignTxResponse, error) {
 	return &resp, nil
 }
 
+// SignRawTransaction sends an unsigned typed transaction to the signer service to be signed.
+func (c *Client) SignRawTransaction(req SignRawTxRequest) (*SignRawTxResponse, error) {
+	var resp SignRawTxResponse
+	err := c.doRequest(http.MethodPost, "/sign-raw-transaction", req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // SignMessage sends a message to the signer service to be signed.
 func (c *Client) SignMessage(req SignMessageRequest) (*SignMessageResponse, error) {
 	var resp SignMessageResponse
@@ -143,13 +532,13 @@ func (c *Client) doRequest(method, path string, data, result interface{}) error
 		}
 	}
 
-	req, err := http.NewRequest(method, c.baseURL+path, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequest(method, c.baseURL+apiPrefix+path, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
-	signature := c.calculateSignature(timestamp, reqBody)
+	signature := c.calculateSignature(timestamp, method, req.URL.RequestURI(), reqBody)
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set(apiKeyHeader, c.apiKey)
@@ -167,8 +556,8 @@ func (c *Client) doRequest(method, path string, data, result interface{}) error
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(resp, respBody)
 	}
 
 	if result != nil {
@@ -180,9 +569,12 @@ func (c *Client) doRequest(method, path string, data, result interface{}) error
 	return nil
 }
 
-func (c *Client) calculateSignature(timestamp string, body []byte) string {
-	payload := timestamp + string(body)
+// calculateSignature signs the timestamp followed by the method, a space, the request URI,
+// a newline and the body, as the signer's authentication middleware expects.
+func (c *Client) calculateSignature(timestamp, method, requestURI string, body []byte) string {
 	mac := hmac.New(sha256.New, []byte(c.apiSecret))
-	mac.Write([]byte(payload))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte(method + " " + requestURI + "\n"))
+	mac.Write(body)
 	return hex.EncodeToString(mac.Sum(nil))
 }
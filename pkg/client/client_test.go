@@ -0,0 +1,87 @@
+package client
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/xueqianLu/ethsigner/internal/handler"
+	"github.com/xueqianLu/ethsigner/internal/middleware"
+	"github.com/xueqianLu/ethsigner/internal/signer/signertest"
+)
+
+// serve starts the signer's versioned routes behind the HMAC authentication middleware
+// and returns its URL and the address of the key it holds.
+func serve(t *testing.T, apiKey, apiSecret string) (string, string) {
+	t.Helper()
+	km := signertest.NewKeyManager(t, 1)
+	s := signertest.NewSigner(t, km)
+	auth := middleware.NewAuthMiddleware(apiKey, apiSecret)
+	mux := http.NewServeMux()
+	mux.Handle("/v1/accounts", auth.Wrap(handler.NewAccountsHandler(s)))
+	mux.Handle("/v1/sign-transaction", auth.Wrap(handler.NewSignTxHandler(s, false)))
+	mux.Handle("/v1/sign-message", auth.Wrap(handler.NewSignMessageHandler(s)))
+	ts := httptest.NewServer(middleware.RequestID(mux))
+	t.Cleanup(ts.Close)
+	return ts.URL, km.GetAccounts(t.Context())[0].Hex()
+}
+
+func TestClient(t *testing.T) {
+	url, from := serve(t, "key", "secret")
+	c := NewClient(url, "key", "secret")
+
+	accounts, err := c.GetAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0] != from {
+		t.Fatalf("accounts = %v, want [%s]", accounts, from)
+	}
+	accs, err := c.ListAccounts(AccountFilter{Tags: []string{"hot"}, States: []string{"all"}})
+	if err != nil {
+		t.Fatalf("list with a query string: %v", err)
+	}
+	if len(accs) != 0 {
+		t.Fatalf("accounts tagged hot = %v, want none", accs)
+	}
+
+	resp, err := c.SignTransaction(SignTxRequest{
+		From:      from,
+		To:        "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		Nonce:     1,
+		Value:     big.NewInt(1000),
+		Data:      []byte{0xca, 0xfe},
+		GasLimit:  50000,
+		GasFeeCap: big.NewInt(3e10),
+		GasTipCap: big.NewInt(1e9),
+		ChainID:   "11155111",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Tx.Type() != types.DynamicFeeTxType || resp.Tx.ChainId().Int64() != 11155111 || resp.From.Hex() != from {
+		t.Fatalf("signed transaction does not match the request: %+v", resp.SignedTx)
+	}
+
+	if _, err := c.SignMessage(SignMessageRequest{From: "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", Message: "hello"}); !errors.Is(err, ErrAccountNotFound) {
+		t.Fatalf("unknown account: got %v, want ErrAccountNotFound", err)
+	}
+	if _, err := c.SignTransaction(SignTxRequest{From: from[:20], GasLimit: 21000, ChainID: "1"}); !errors.Is(err, ErrInvalidAddress) {
+		t.Fatalf("truncated address: got %v, want ErrInvalidAddress", err)
+	}
+	if _, err := c.SignTransaction(SignTxRequest{From: from, GasLimit: 21000}); !errors.Is(err, ErrInvalidChainID) {
+		t.Fatalf("missing chain ID: got %v, want ErrInvalidChainID", err)
+	}
+
+	var apiErr *APIError
+	_, err = NewClient(url, "key", "wrong").GetAccounts()
+	if !errors.Is(err, ErrUnauthorized) || !errors.As(err, &apiErr) {
+		t.Fatalf("wrong secret: got %v, want ErrUnauthorized", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.RequestID == "" {
+		t.Fatalf("wrong secret: %+v, want status 401 with a request ID", apiErr)
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error codes returned by the signer's versioned API.
+const (
+	CodeInvalidRequest     = "invalid_request"
+	CodeInvalidAddress     = "invalid_address"
+	CodeInvalidChainID     = "invalid_chain_id"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeUnauthorized       = "unauthorized"
+	CodeAccountNotFound    = "account_not_found"
+	CodeAccountExists      = "account_exists"
+	CodeAccountArchived    = "account_archived"
+	CodeAccountLocked      = "account_locked"
+	CodePolicyDenied       = "policy_denied"
+	CodeWrongPassword      = "wrong_password"
+	CodeNotSupported       = "not_supported"
+	CodeConflict           = "conflict"
+	CodeBackendUnavailable = "backend_unavailable"
+	CodeInternal           = "internal_error"
+)
+
+// Sentinel errors matching an *APIError with the corresponding code through errors.Is.
+var (
+	ErrInvalidRequest     = errors.New("invalid request")
+	ErrInvalidAddress     = errors.New("invalid address")
+	ErrInvalidChainID     = errors.New("invalid chain ID")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrAccountNotFound    = errors.New("account not found")
+	ErrAccountExists      = errors.New("account already exists")
+	ErrAccountArchived    = errors.New("account is archived")
+	ErrAccountLocked      = errors.New("account locked")
+	ErrPolicyDenied       = errors.New("denied by policy")
+	ErrWrongPassword      = errors.New("wrong password")
+	ErrNotSupported       = errors.New("operation not supported")
+	ErrBackendUnavailable = errors.New("key backend unavailable")
+)
+
+var codeErrors = map[string]error{
+	CodeInvalidRequest:     ErrInvalidRequest,
+	CodeInvalidAddress:     ErrInvalidAddress,
+	CodeInvalidChainID:     ErrInvalidChainID,
+	CodeUnauthorized:       ErrUnauthorized,
+	CodeAccountNotFound:    ErrAccountNotFound,
+	CodeAccountExists:      ErrAccountExists,
+	CodeAccountArchived:    ErrAccountArchived,
+	CodeAccountLocked:      ErrAccountLocked,
+	CodePolicyDenied:       ErrPolicyDenied,
+	CodeWrongPassword:      ErrWrongPassword,
+	CodeNotSupported:       ErrNotSupported,
+	CodeBackendUnavailable: ErrBackendUnavailable,
+}
+
+// APIError is the error returned for a request the signer rejected.
+type APIError struct {
+	StatusCode int
+	Code       string // one of the Code constants
+	Message    string
+	RequestID  string // quote it when reporting problems; the signer logs it
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("request failed with status %d", e.StatusCode)
+	if e.Code != "" {
+		msg += " (" + e.Code + ")"
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.RequestID != "" {
+		msg += " [request " + e.RequestID + "]"
+	}
+	return msg
+}
+
+// Is reports whether target is the sentinel error of e's code, so that callers can use
+// errors.Is(err, client.ErrAccountNotFound).
+func (e *APIError) Is(target error) bool {
+	sentinel, ok := codeErrors[e.Code]
+	return ok && sentinel == target
+}
+
+// errorResponse is the JSON error body of the versioned API.
+type errorResponse struct {
+	Error     string `json:"error"`
+	Code      string `json:"code"`
+	RequestID string `json:"requestId"`
+}
+
+// newAPIError builds the error of a failed response with the given body.
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(body)),
+		RequestID:  resp.Header.Get(requestIDHeader),
+	}
+	var errResp errorResponse
+	if json.Unmarshal(body, &errResp) == nil && errResp.Code != "" {
+		apiErr.Code = errResp.Code
+		apiErr.Message = errResp.Error
+		if errResp.RequestID != "" {
+			apiErr.RequestID = errResp.RequestID
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xueqianLu/ethsigner/internal/apierror"
+)
+
+func TestAPIErrorMapping(t *testing.T) {
+	sentinels := map[string]error{
+		apierror.CodeInvalidRequest:     ErrInvalidRequest,
+		apierror.CodeInvalidAddress:     ErrInvalidAddress,
+		apierror.CodeInvalidChainID:     ErrInvalidChainID,
+		apierror.CodeUnauthorized:       ErrUnauthorized,
+		apierror.CodeAccountNotFound:    ErrAccountNotFound,
+		apierror.CodeAccountExists:      ErrAccountExists,
+		apierror.CodeAccountArchived:    ErrAccountArchived,
+		apierror.CodeAccountLocked:      ErrAccountLocked,
+		apierror.CodePolicyDenied:       ErrPolicyDenied,
+		apierror.CodeWrongPassword:      ErrWrongPassword,
+		apierror.CodeNotSupported:       ErrNotSupported,
+		apierror.CodeBackendUnavailable: ErrBackendUnavailable,
+	}
+	for code, sentinel := range sentinels {
+		t.Run(code, func(t *testing.T) {
+			var err error = &APIError{StatusCode: http.StatusBadRequest, Code: code}
+			if !errors.Is(err, sentinel) {
+				t.Fatalf("errors.Is(%s, %v) = false", code, sentinel)
+			}
+			for other, otherSentinel := range sentinels {
+				if other != code && errors.Is(err, otherSentinel) {
+					t.Fatalf("code %s also matches %v", code, otherSentinel)
+				}
+			}
+		})
+	}
+
+	// Codes without a sentinel, and plain text errors, match none.
+	for _, code := range []string{apierror.CodeMethodNotAllowed, apierror.CodeConflict, apierror.CodeInternal, "unknown", ""} {
+		err := &APIError{StatusCode: http.StatusInternalServerError, Code: code}
+		for _, sentinel := range sentinels {
+			if errors.Is(err, sentinel) {
+				t.Fatalf("code %q matches %v", code, sentinel)
+			}
+		}
+	}
+}
+
+func TestErrorCodesMatchServer(t *testing.T) {
+	for client, server := range map[string]string{
+		CodeInvalidRequest:     apierror.CodeInvalidRequest,
+		CodeInvalidAddress:     apierror.CodeInvalidAddress,
+		CodeInvalidChainID:     apierror.CodeInvalidChainID,
+		CodeMethodNotAllowed:   apierror.CodeMethodNotAllowed,
+		CodeUnauthorized:       apierror.CodeUnauthorized,
+		CodeAccountNotFound:    apierror.CodeAccountNotFound,
+		CodeAccountExists:      apierror.CodeAccountExists,
+		CodeAccountArchived:    apierror.CodeAccountArchived,
+		CodeAccountLocked:      apierror.CodeAccountLocked,
+		CodePolicyDenied:       apierror.CodePolicyDenied,
+		CodeWrongPassword:      apierror.CodeWrongPassword,
+		CodeNotSupported:       apierror.CodeNotSupported,
+		CodeConflict:           apierror.CodeConflict,
+		CodeBackendUnavailable: apierror.CodeBackendUnavailable,
+		CodeInternal:           apierror.CodeInternal,
+	} {
+		if client != server {
+			t.Errorf("client code %q, server code %q", client, server)
+		}
+	}
+}
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		body      string
+		want      APIError
+		wantError string
+	}{
+		{
+			name:      "JSON error",
+			header:    "header-id",
+			body:      `{"error": "account not found", "code": "account_not_found", "requestId": "body-id"}`,
+			want:      APIError{StatusCode: 404, Code: CodeAccountNotFound, Message: "account not found", RequestID: "body-id"},
+			wantError: "request failed with status 404 (account_not_found): account not found [request body-id]",
+		},
+		{
+			name:      "JSON error without request ID",
+			header:    "header-id",
+			body:      `{"error": "account not found", "code": "account_not_found"}`,
+			want:      APIError{StatusCode: 404, Code: CodeAccountNotFound, Message: "account not found", RequestID: "header-id"},
+			wantError: "request failed with status 404 (account_not_found): account not found [request header-id]",
+		},
+		{
+			name:      "plain text error",
+			body:      "Account not found\n",
+			want:      APIError{StatusCode: 404, Message: "Account not found"},
+			wantError: "request failed with status 404: Account not found",
+		},
+		{
+			name:      "JSON without a code",
+			body:      `{"error": "boom"}`,
+			want:      APIError{StatusCode: 404, Message: `{"error": "boom"}`},
+			wantError: `request failed with status 404: {"error": "boom"}`,
+		},
+		{
+			name:      "empty body",
+			want:      APIError{StatusCode: 404},
+			wantError: "request failed with status 404",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if tt.header != "" {
+				rec.Header().Set(requestIDHeader, tt.header)
+			}
+			rec.WriteHeader(http.StatusNotFound)
+			err := newAPIError(rec.Result(), []byte(tt.body))
+			if *err != tt.want {
+				t.Fatalf("got %+v, want %+v", *err, tt.want)
+			}
+			if err.Error() != tt.wantError {
+				t.Fatalf("Error() = %q, want %q", err.Error(), tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Package keywrap implements the key wrapping scheme used to move private keys into the
+// signer without exposing them in transit. It matches HashiCorp Vault's BYOK import format:
+// an ephemeral AES-256 key is wrapped with RSA-OAEP (SHA-256) and the key material itself is
+// wrapped with AES Key Wrap with Padding (RFC 5649).
+package keywrap
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// aivPrefix is the alternative initial value prefix defined by RFC 5649.
+var aivPrefix = []byte{0xA6, 0x59, 0x59, 0xA6}
+
+// ErrUnwrap is returned when wrapped data fails its integrity check.
+var ErrUnwrap = errors.New("keywrap: integrity check failed")
+
+// Wrap wraps plaintext with kek using AES Key Wrap with Padding (RFC 5649).
+func Wrap(kek, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(plaintext) == 0 {
+		return nil, errors.New("keywrap: empty plaintext")
+	}
+
+	aiv := make([]byte, 8)
+	copy(aiv, aivPrefix)
+	binary.BigEndian.PutUint32(aiv[4:], uint32(len(plaintext)))
+
+	padded := make([]byte, (len(plaintext)+7)/8*8)
+	copy(padded, plaintext)
+
+	if len(padded) == 8 {
+		out := make([]byte, 16)
+		copy(out, aiv)
+		copy(out[8:], padded)
+		block.Encrypt(out, out)
+		return out, nil
+	}
+
+	n := len(padded) / 8
+	out := make([]byte, 8+len(padded))
+	copy(out[8:], padded)
+	a := aiv
+	buf := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(buf, a)
+			copy(buf[8:], out[i*8:i*8+8])
+			block.Encrypt(buf, buf)
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(buf[:8])^t)
+			copy(out[i*8:], buf[8:])
+		}
+	}
+	copy(out, a)
+	return out, nil
+}
+
+// Unwrap reverses Wrap and verifies the integrity of the result.
+func Unwrap(kek, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < 16 || len(ciphertext)%8 != 0 {
+		return nil, errors.New("keywrap: invalid ciphertext length")
+	}
+
+	var a, padded []byte
+	if len(ciphertext) == 16 {
+		buf := make([]byte, 16)
+		block.Decrypt(buf, ciphertext)
+		a, padded = buf[:8], buf[8:]
+	} else {
+		n := len(ciphertext)/8 - 1
+		r := make([]byte, len(ciphertext)-8)
+		copy(r, ciphertext[8:])
+		a = make([]byte, 8)
+		copy(a, ciphertext[:8])
+		buf := make([]byte, 16)
+		for j := 5; j >= 0; j-- {
+			for i := n; i >= 1; i-- {
+				t := uint64(n*j + i)
+				binary.BigEndian.PutUint64(buf, binary.BigEndian.Uint64(a)^t)
+				copy(buf[8:], r[(i-1)*8:i*8])
+				block.Decrypt(buf, buf)
+				copy(a, buf[:8])
+				copy(r[(i-1)*8:], buf[8:])
+			}
+		}
+		padded = r
+	}
+
+	if subtle.ConstantTimeCompare(a[:4], aivPrefix) != 1 {
+		return nil, ErrUnwrap
+	}
+	mli := int(binary.BigEndian.Uint32(a[4:]))
+	if mli <= len(padded)-8 || mli > len(padded) {
+		return nil, ErrUnwrap
+	}
+	for _, b := range padded[mli:] {
+		if b != 0 {
+			return nil, ErrUnwrap
+		}
+	}
+	return padded[:mli], nil
+}
+
+// Seal wraps keyMaterial for the holder of pub: an ephemeral AES-256 key encrypted with
+// RSA-OAEP (SHA-256), followed by keyMaterial wrapped with that AES key.
+func Seal(pub *rsa.PublicKey, keyMaterial []byte) ([]byte, error) {
+	ephemeral := make([]byte, 32)
+	if _, err := rand.Read(ephemeral); err != nil {
+		return nil, err
+	}
+	defer clear(ephemeral)
+
+	wrappedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, ephemeral, nil)
+	if err != nil {
+		return nil, fmt.Errorf("keywrap: failed to encrypt ephemeral key: %w", err)
+	}
+	wrappedMaterial, err := Wrap(ephemeral, keyMaterial)
+	if err != nil {
+		return nil, err
+	}
+	return append(wrappedKey, wrappedMaterial...), nil
+}
+
+// Open reverses Seal using the RSA private key the material was sealed for.
+func Open(priv *rsa.PrivateKey, sealed []byte) ([]byte, error) {
+	size := priv.Size()
+	if len(sealed) <= size {
+		return nil, errors.New("keywrap: sealed data too short")
+	}
+	ephemeral, err := rsa.DecryptOAEP(sha256.New(), nil, priv, sealed[:size], nil)
+	if err != nil {
+		return nil, fmt.Errorf("keywrap: failed to decrypt ephemeral key: %w", err)
+	}
+	defer clear(ephemeral)
+	return Unwrap(ephemeral, sealed[size:])
+}
+
+// ParsePublicKeyPEM parses a PEM-encoded RSA public key as published by the signer or Vault.
+func ParsePublicKeyPEM(data string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("keywrap: no PEM block found")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("keywrap: failed to parse public key: %w", err)
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("keywrap: wrapping key is not an RSA key")
+	}
+	return rsaPub, nil
+}
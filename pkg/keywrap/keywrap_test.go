@@ -0,0 +1,136 @@
+package keywrap
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// Test vectors of RFC 5649, section 6.
+var rfc5649Vectors = []struct {
+	name       string
+	kek        string
+	plaintext  string
+	ciphertext string
+}{
+	{
+		name:       "20 octets",
+		kek:        "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8",
+		plaintext:  "c37b7e6492584340bed12207808941155068f738",
+		ciphertext: "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a",
+	},
+	{
+		name:       "7 octets",
+		kek:        "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8",
+		plaintext:  "466f7250617369",
+		ciphertext: "afbeb0f07dfbf5419200f2ccb50bb24f",
+	},
+}
+
+func TestWrapRFC5649Vectors(t *testing.T) {
+	for _, v := range rfc5649Vectors {
+		t.Run(v.name, func(t *testing.T) {
+			kek, plaintext, ciphertext := mustHex(t, v.kek), mustHex(t, v.plaintext), mustHex(t, v.ciphertext)
+
+			wrapped, err := Wrap(kek, plaintext)
+			if err != nil {
+				t.Fatalf("Wrap: %v", err)
+			}
+			if !bytes.Equal(wrapped, ciphertext) {
+				t.Fatalf("Wrap: got %x, want %x", wrapped, ciphertext)
+			}
+			unwrapped, err := Unwrap(kek, ciphertext)
+			if err != nil {
+				t.Fatalf("Unwrap: %v", err)
+			}
+			if !bytes.Equal(unwrapped, plaintext) {
+				t.Fatalf("Unwrap: got %x, want %x", unwrapped, plaintext)
+			}
+		})
+	}
+}
+
+func TestUnwrapRejectsTampering(t *testing.T) {
+	for _, v := range rfc5649Vectors {
+		kek, ciphertext := mustHex(t, v.kek), mustHex(t, v.ciphertext)
+		for i := range ciphertext {
+			tampered := bytes.Clone(ciphertext)
+			tampered[i] ^= 0x01
+			if _, err := Unwrap(kek, tampered); !errors.Is(err, ErrUnwrap) {
+				t.Errorf("%s: flipping a bit of byte %d: got %v, want ErrUnwrap", v.name, i, err)
+			}
+		}
+		if _, err := Unwrap(kek, ciphertext[:len(ciphertext)-8]); err == nil {
+			t.Errorf("%s: truncated ciphertext unwrapped", v.name)
+		}
+	}
+}
+
+func TestUnwrapRejectsWrongKEK(t *testing.T) {
+	for _, v := range rfc5649Vectors {
+		kek, ciphertext := mustHex(t, v.kek), mustHex(t, v.ciphertext)
+		kek[0] ^= 0x80
+		if _, err := Unwrap(kek, ciphertext); !errors.Is(err, ErrUnwrap) {
+			t.Errorf("%s: got %v, want ErrUnwrap", v.name, err)
+		}
+	}
+}
+
+func TestWrapRoundTrip(t *testing.T) {
+	kek := make([]byte, 32)
+	rand.Read(kek)
+	for size := 1; size <= 80; size++ {
+		plaintext := make([]byte, size)
+		rand.Read(plaintext)
+		wrapped, err := Wrap(kek, plaintext)
+		if err != nil {
+			t.Fatalf("size %d: Wrap: %v", size, err)
+		}
+		unwrapped, err := Unwrap(kek, wrapped)
+		if err != nil {
+			t.Fatalf("size %d: Unwrap: %v", size, err)
+		}
+		if !bytes.Equal(unwrapped, plaintext) {
+			t.Fatalf("size %d: round trip mismatch", size)
+		}
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	material := []byte("secp256k1 key material in PKCS #8")
+	sealed, err := Seal(&priv.PublicKey, material)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	opened, err := Open(priv, sealed)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if !bytes.Equal(opened, material) {
+		t.Fatalf("Open: got %q, want %q", opened, material)
+	}
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(other, sealed); err == nil {
+		t.Fatal("Open with another RSA key succeeded")
+	}
+}
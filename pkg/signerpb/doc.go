@@ -0,0 +1,6 @@
+// Package signerpb contains the gRPC API of the signer: the protobuf messages and the
+// client and server stubs generated from signer.proto, and ClientAuthInterceptor, which
+// authenticates client calls.
+package signerpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative signer.proto